@@ -0,0 +1,167 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package aferofs adapts any `vfs.FileSystem` to read-only `afero.Fs`, so the
+// application VFS plugs into the libraries standardized on
+// github.com/spf13/afero, for e.g. viper config, static site tools and test
+// harnesses.
+//
+//	viper.SetFs(aferofs.New(aah.AppVFS()))
+//
+// Write operations return `vfs.ErrReadOnly`.
+package aferofs
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"aahframework.org/vfs.v0"
+	"github.com/spf13/afero"
+)
+
+var _ afero.Fs = (*Fs)(nil)
+var _ afero.Lstater = (*Fs)(nil)
+var _ afero.File = (*file)(nil)
+
+// Fs is the read-only `afero.Fs` backed by `vfs.FileSystem`.
+type Fs struct {
+	fs vfs.FileSystem
+}
+
+// New method returns the `afero.Fs` of given FileSystem.
+func New(fs vfs.FileSystem) *Fs {
+	return &Fs{fs: fs}
+}
+
+// Open method opens the file of FileSystem.
+func (a *Fs) Open(name string) (afero.File, error) {
+	f, err := a.fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &file{File: f, name: name}, nil
+}
+
+// OpenFile method opens the file of FileSystem, flags other than
+// `os.O_RDONLY` returns `vfs.ErrReadOnly`.
+func (a *Fs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_APPEND|os.O_CREATE|os.O_TRUNC) != 0 {
+		return nil, readOnly("open", name)
+	}
+	return a.Open(name)
+}
+
+// Stat method returns the file info of FileSystem.
+func (a *Fs) Stat(name string) (os.FileInfo, error) {
+	return a.fs.Stat(name)
+}
+
+// LstatIfPossible method implements `afero.Lstater`, symbolic link is not
+// followed.
+func (a *Fs) LstatIfPossible(name string) (os.FileInfo, bool, error) {
+	fi, err := a.fs.Lstat(name)
+	return fi, true, err
+}
+
+// Name method returns the name of filesystem.
+func (a *Fs) Name() string {
+	return "aferofs"
+}
+
+// Create method returns `vfs.ErrReadOnly`.
+func (a *Fs) Create(name string) (afero.File, error) {
+	return nil, readOnly("create", name)
+}
+
+// Mkdir method returns `vfs.ErrReadOnly`.
+func (a *Fs) Mkdir(name string, perm os.FileMode) error {
+	return readOnly("mkdir", name)
+}
+
+// MkdirAll method returns `vfs.ErrReadOnly`.
+func (a *Fs) MkdirAll(path string, perm os.FileMode) error {
+	return readOnly("mkdir", path)
+}
+
+// Remove method returns `vfs.ErrReadOnly`.
+func (a *Fs) Remove(name string) error {
+	return readOnly("remove", name)
+}
+
+// RemoveAll method returns `vfs.ErrReadOnly`.
+func (a *Fs) RemoveAll(path string) error {
+	return readOnly("remove", path)
+}
+
+// Rename method returns `vfs.ErrReadOnly`.
+func (a *Fs) Rename(oldname, newname string) error {
+	return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: vfs.ErrReadOnly}
+}
+
+// Chmod method returns `vfs.ErrReadOnly`.
+func (a *Fs) Chmod(name string, mode os.FileMode) error {
+	return readOnly("chmod", name)
+}
+
+// Chtimes method returns `vfs.ErrReadOnly`.
+func (a *Fs) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	return readOnly("chtimes", name)
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Unexported types and methods
+//______________________________________________________________________________
+
+// file adapts the `vfs.File` to `afero.File`.
+type file struct {
+	vfs.File
+	name string
+}
+
+func (f *file) Name() string {
+	return f.name
+}
+
+// ReadAt method reads via `io.ReaderAt` of file if available, otherwise it
+// seeks to offset and restores the offset after read.
+func (f *file) ReadAt(b []byte, off int64) (int, error) {
+	if ra, ok := f.File.(io.ReaderAt); ok {
+		return ra.ReadAt(b, off)
+	}
+
+	cur, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _, _ = f.Seek(cur, io.SeekStart) }()
+	if _, err = f.Seek(off, io.SeekStart); err != nil {
+		return 0, err
+	}
+	return io.ReadFull(f.File, b)
+}
+
+func (f *file) Write(b []byte) (int, error) {
+	return 0, readOnly("write", f.name)
+}
+
+func (f *file) WriteAt(b []byte, off int64) (int, error) {
+	return 0, readOnly("write", f.name)
+}
+
+func (f *file) WriteString(s string) (int, error) {
+	return 0, readOnly("write", f.name)
+}
+
+func (f *file) Truncate(size int64) error {
+	return readOnly("truncate", f.name)
+}
+
+func (f *file) Sync() error {
+	return nil
+}
+
+func readOnly(op, name string) error {
+	return &os.PathError{Op: op, Path: name, Err: vfs.ErrReadOnly}
+}
@@ -0,0 +1,72 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package aferofs
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"aahframework.org/test.v0/assert"
+	"aahframework.org/vfs.v0"
+	"github.com/spf13/afero"
+)
+
+func TestAferoFs(t *testing.T) {
+	m, err := vfs.NewMount("/app", "")
+	assert.Nil(t, err)
+	now := time.Now()
+	assert.Nil(t, m.AddDir(&vfs.NodeInfo{Dir: true, Path: "/app/config", Time: now}))
+	assert.Nil(t, m.AddFile(&vfs.NodeInfo{Path: "/app/config/app.yaml", DataSize: 10, Time: now}, []byte("name: app\n")))
+	assert.Nil(t, m.AddFile(&vfs.NodeInfo{Path: "/app/index.html", DataSize: 5, Time: now}, []byte("hello")))
+
+	fs := New(m)
+	assert.Equal(t, "aferofs", fs.Name())
+
+	data, err := afero.ReadFile(fs, "/app/config/app.yaml")
+	assert.Nil(t, err)
+	assert.Equal(t, "name: app\n", string(data))
+
+	ok, err := afero.Exists(fs, "/app/index.html")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	ok, err = afero.DirExists(fs, "/app/config")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+
+	var files []string
+	err = afero.Walk(fs, "/app", func(p string, fi os.FileInfo, err error) error {
+		if err == nil && !fi.IsDir() {
+			files = append(files, p)
+		}
+		return err
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/app/config/app.yaml", "/app/index.html"}, files)
+
+	f, err := fs.Open("/app/index.html")
+	assert.Nil(t, err)
+	assert.Equal(t, "/app/index.html", f.Name())
+	b := make([]byte, 3)
+	n, err := f.ReadAt(b, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, "llo", string(b[:n]))
+	_, err = f.WriteString("x")
+	assert.Equal(t, vfs.ErrReadOnly, err.(*os.PathError).Err)
+	assert.Nil(t, f.Close())
+
+	// read-only
+	_, err = fs.Create("/app/new.txt")
+	assert.Equal(t, vfs.ErrReadOnly, err.(*os.PathError).Err)
+	_, err = fs.OpenFile("/app/index.html", os.O_RDWR, 0644)
+	assert.Equal(t, vfs.ErrReadOnly, err.(*os.PathError).Err)
+	assert.NotNil(t, fs.Remove("/app/index.html"))
+	assert.NotNil(t, fs.Rename("/app/index.html", "/app/home.html"))
+	assert.NotNil(t, afero.WriteFile(fs, "/app/index.html", []byte("x"), 0644))
+	assert.True(t, m.IsExists("/app/index.html"))
+
+	_, err = fs.Stat("/app/not-exists")
+	assert.True(t, os.IsNotExist(err))
+}
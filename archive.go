@@ -0,0 +1,199 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ArchiveOpener type opens the archive file and returns the mount of its
+// content at given virtual root.
+type ArchiveOpener func(vroot, filename string) (*Mount, error)
+
+var (
+	archiveOpenersMu sync.RWMutex
+	archiveOpeners   = make(map[string]ArchiveOpener)
+)
+
+// RegisterArchive method registers the opener for archive file extension,
+// for e.g.: ".zip", ".tgz". It is used by `VFS.MountArchives`.
+func RegisterArchive(ext string, opener ArchiveOpener) {
+	archiveOpenersMu.Lock()
+	defer archiveOpenersMu.Unlock()
+	archiveOpeners[strings.ToLower(ext)] = opener
+}
+
+// MountArchives method scans the physical directory dir for archive files of
+// registered extensions and mounts each archive under vroot with archive name
+// without extension. For e.g.: plugins/blog.zip => /plugins/blog
+//
+// Calling it again rescans the directory, newly added archives get mounted,
+// removed ones get unmounted and modified ones get remounted. It returns the
+// mount paths currently mounted from dir, sorted. Archive which fails to
+// open does not stop the scan, first error is returned.
+func (v *VFS) MountArchives(vroot, dir string) ([]string, error) {
+	dir = v.resolvePath(dir)
+	_, err := v.syncArchives(vroot, dir)
+	var mounted []string
+	for mp := range v.archivesOf(dir) {
+		mounted = append(mounted, mp)
+	}
+	sort.Strings(mounted)
+
+	return mounted, err
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Archive mount unexported types and methods
+//______________________________________________________________________________
+
+// syncArchives method brings the archive mounts of dir in sync with the
+// archive files in dir and returns the changes made.
+func (v *VFS) syncArchives(vroot, dir string) ([]MountEvent, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	vroot = path.Clean("/" + filepath.ToSlash(vroot))
+	found := make(map[string]*archiveMount)
+	for _, fi := range infos {
+		if fi.IsDir() {
+			continue
+		}
+		name, opener := archiveOpenerFor(fi.Name())
+		if opener == nil {
+			continue
+		}
+		am := &archiveMount{
+			dir:      dir,
+			filename: filepath.Join(dir, fi.Name()),
+			size:     fi.Size(),
+			modTime:  fi.ModTime(),
+			opener:   opener,
+		}
+		found[path.Join(vroot, name)] = am
+	}
+
+	var events []MountEvent
+	var firstErr error
+	record := func(e MountEvent) {
+		if e.Err != nil && firstErr == nil {
+			firstErr = e.Err
+		}
+		events = append(events, e)
+	}
+
+	// unmount removed archives
+	for mp, am := range v.archivesOf(dir) {
+		if _, exists := found[mp]; !exists {
+			v.forgetArchive(mp)
+			e := MountEvent{Type: MountRemoved, MountPath: mp, Source: am.filename}
+			if m := v.detach(mp); m != nil {
+				e.Err = m.Close()
+			}
+			record(e)
+		} else if am.same(found[mp]) {
+			delete(found, mp)
+		}
+	}
+
+	// mount new and modified archives
+	mountPaths := make([]string, 0, len(found))
+	for mp := range found {
+		mountPaths = append(mountPaths, mp)
+	}
+	sort.Strings(mountPaths)
+	for _, mp := range mountPaths {
+		am := found[mp]
+		m, err := am.opener(mp, am.filename)
+		if err != nil {
+			record(MountEvent{Type: MountFailed, MountPath: mp, Source: am.filename, Err: err})
+			continue
+		}
+
+		e := MountEvent{Type: MountAdded, MountPath: mp, Source: am.filename}
+		if _, exists := v.archivesOf(dir)[mp]; exists {
+			e.Type = MountSwapped
+			if old := v.replace(m); old != nil {
+				e.Err = old.Close()
+			}
+		} else if err = v.attach(m); err != nil {
+			_ = m.Close()
+			record(MountEvent{Type: MountFailed, MountPath: mp, Source: am.filename, Err: err})
+			continue
+		}
+		v.rememberArchive(mp, am)
+		record(e)
+	}
+
+	return events, firstErr
+}
+
+// archiveMount holds the state of archive file mounted by
+// `VFS.MountArchives` to detect the changes on rescan.
+type archiveMount struct {
+	dir      string
+	filename string
+	size     int64
+	modTime  time.Time
+	opener   ArchiveOpener
+}
+
+func (a *archiveMount) same(b *archiveMount) bool {
+	return a.filename == b.filename && a.size == b.size && a.modTime.Equal(b.modTime)
+}
+
+func (v *VFS) archivesOf(dir string) map[string]*archiveMount {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	result := make(map[string]*archiveMount)
+	for mp, am := range v.archives {
+		if am.dir == dir {
+			result[mp] = am
+		}
+	}
+	return result
+}
+
+func (v *VFS) rememberArchive(mountPath string, am *archiveMount) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.archives == nil {
+		v.archives = make(map[string]*archiveMount)
+	}
+	v.archives[mountPath] = am
+}
+
+func (v *VFS) forgetArchive(mountPath string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	delete(v.archives, mountPath)
+}
+
+// archiveOpenerFor method returns the archive name without extension and
+// its opener, longest registered extension wins. For e.g.: ".tar.gz" over
+// ".gz".
+func archiveOpenerFor(filename string) (string, ArchiveOpener) {
+	archiveOpenersMu.RLock()
+	defer archiveOpenersMu.RUnlock()
+	lname := strings.ToLower(filename)
+	var ext string
+	for e := range archiveOpeners {
+		if len(e) > len(ext) && len(lname) > len(e) && strings.HasSuffix(lname, e) {
+			ext = e
+		}
+	}
+	if ext == "" {
+		return "", nil
+	}
+	return filename[:len(filename)-len(ext)], archiveOpeners[ext]
+}
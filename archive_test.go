@@ -0,0 +1,100 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"aahframework.org/test.v0/assert"
+)
+
+func TestVFSMountArchives(t *testing.T) {
+	var closed []string
+	RegisterArchive(".testbundle", func(vroot, filename string) (*Mount, error) {
+		return openTestBundle(vroot, filename, &closed)
+	})
+
+	dir, err := ioutil.TempDir("", "vfs-archives")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	writeBundle := func(name, content string, mtime time.Time) {
+		fname := filepath.Join(dir, name)
+		assert.Nil(t, ioutil.WriteFile(fname, []byte(content), 0644))
+		assert.Nil(t, os.Chtimes(fname, mtime, mtime))
+	}
+	mtime := time.Now().Add(-time.Hour)
+	writeBundle("blog.testbundle", "index.html=blog v1", mtime)
+	writeBundle("shop.TESTBUNDLE", "index.html=shop", mtime)
+	writeBundle("readme.txt", "not a bundle", mtime)
+
+	fs := new(VFS)
+	mounted, err := fs.MountArchives("plugins", dir)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/plugins/blog", "/plugins/shop"}, mounted)
+
+	data, err := fs.ReadFile("/plugins/blog/index.html")
+	assert.Nil(t, err)
+	assert.Equal(t, "blog v1", string(data))
+
+	// rescan without changes
+	mounted, err = fs.MountArchives("/plugins", dir)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/plugins/blog", "/plugins/shop"}, mounted)
+	assert.Equal(t, 0, len(closed))
+
+	// modify, remove and add
+	writeBundle("blog.testbundle", "index.html=blog v2", mtime.Add(time.Minute))
+	assert.Nil(t, os.Remove(filepath.Join(dir, "shop.TESTBUNDLE")))
+	writeBundle("forum.testbundle", "index.html=forum", mtime)
+
+	mounted, err = fs.MountArchives("/plugins", dir)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/plugins/blog", "/plugins/forum"}, mounted)
+	assert.Equal(t, []string{"/plugins/shop", "/plugins/blog"}, closed)
+
+	data, err = fs.ReadFile("/plugins/blog/index.html")
+	assert.Nil(t, err)
+	assert.Equal(t, "blog v2", string(data))
+	assert.True(t, fs.IsExists("/plugins/forum/index.html"))
+	_, err = fs.FindMount("/plugins/shop/index.html")
+	assert.NotNil(t, err)
+
+	_, err = fs.MountArchives("/plugins", filepath.Join(dir, "not-exists"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+// openTestBundle opens the simple line based bundle of "name=content".
+func openTestBundle(vroot, filename string, closed *[]string) (*Mount, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := NewMount(vroot, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		parts := strings.SplitN(line, "=", 2)
+		if err = m.AddFile(&NodeInfo{
+			Path:     m.Vroot + "/" + parts[0],
+			DataSize: int64(len(parts[1])),
+		}, []byte(parts[1])); err != nil {
+			return nil, err
+		}
+	}
+	m.AddCloser(testCloser(func() error {
+		*closed = append(*closed, vroot)
+		return nil
+	}))
+
+	return m, nil
+}
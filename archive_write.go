@@ -0,0 +1,115 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+// WriteTar method streams the tree of root from fs into w as tar archive,
+// paths and modification times are preserved. Entry name is the virtual
+// path without leading slash, for e.g.: `app/static/css/aah.css`. Symbolic
+// link to a file is archived with its target content, symbolic link to a
+// directory is skipped. Gzip virtual files are archived uncompressed.
+//
+// It is useful for "download all assets" endpoint and backup jobs, nothing
+// is written to disk.
+func WriteTar(w io.Writer, fs FileSystem, root string) error {
+	tw := tar.NewWriter(w)
+	err := writeArchive(fs, root, func(name string, fi os.FileInfo) (io.Writer, error) {
+		hdr := &tar.Header{
+			Name:     name,
+			Mode:     int64(fi.Mode().Perm()),
+			ModTime:  fi.ModTime(),
+			Typeflag: tar.TypeReg,
+			Size:     fi.Size(),
+		}
+		if fi.IsDir() {
+			hdr.Typeflag, hdr.Size = tar.TypeDir, 0
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return nil, err
+		}
+		return tw, nil
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// WriteZip method streams the tree of root from fs into w as zip archive,
+// files are deflate compressed. Entries are same as `vfs.WriteTar`.
+func WriteZip(w io.Writer, fs FileSystem, root string) error {
+	zw := zip.NewWriter(w)
+	err := writeArchive(fs, root, func(name string, fi os.FileInfo) (io.Writer, error) {
+		hdr := &zip.FileHeader{Name: name, Method: zip.Deflate}
+		hdr.SetModTime(fi.ModTime())
+		hdr.SetMode(fi.Mode())
+		if fi.IsDir() {
+			hdr.Method = zip.Store
+		}
+		return zw.CreateHeader(hdr)
+	})
+	if err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Archive writer unexported methods
+//______________________________________________________________________________
+
+// writeArchive method walks the root and calls create for each entry, file
+// content is copied into returned writer. Directory entry name ends with
+// slash.
+func writeArchive(fs FileSystem, root string, create func(string, os.FileInfo) (io.Writer, error)) error {
+	root = path.Clean("/" + root)
+	info, err := fs.Lstat(root)
+	if err != nil {
+		return err
+	}
+
+	return walk(fs, root, info, func(fpath string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.Mode()&os.ModeSymlink != 0 {
+			if fi, err = fs.Stat(fpath); err != nil {
+				return err
+			}
+			if fi.IsDir() {
+				return nil
+			}
+		}
+
+		name := strings.TrimPrefix(fpath, "/")
+		if fi.IsDir() {
+			if name == "" {
+				return nil
+			}
+			_, err = create(name+"/", fi)
+			return err
+		}
+
+		w, err := create(name, fi)
+		if err != nil {
+			return err
+		}
+		f, err := fs.Open(fpath)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = f.Close() }()
+		_, err = io.Copy(w, f)
+		return err
+	})
+}
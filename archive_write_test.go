@@ -0,0 +1,73 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"aahframework.org/test.v0/assert"
+)
+
+func TestVFSWriteTarAndZip(t *testing.T) {
+	fs := createVFS(t)
+	expected, err := fs.ReadFile("/app/config/security.conf")
+	assert.Nil(t, err)
+	efi, err := fs.Stat("/app/config/security.conf")
+	assert.Nil(t, err)
+
+	var buf bytes.Buffer
+	assert.Nil(t, WriteTar(&buf, fs, "/app/config"))
+	tr := tar.NewReader(&buf)
+	var names []string
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.Nil(t, err)
+		names = append(names, hdr.Name)
+		if hdr.Name == "app/config/security.conf" {
+			data, err := ioutil.ReadAll(tr)
+			assert.Nil(t, err)
+			assert.Equal(t, expected, data)
+			assert.Equal(t, efi.ModTime().Unix(), hdr.ModTime.Unix())
+		}
+	}
+	assert.Equal(t, []string{
+		"app/config/",
+		"app/config/aah.conf",
+		"app/config/env/",
+		"app/config/env/dev.conf",
+		"app/config/env/prod.conf",
+		"app/config/routes.conf",
+		"app/config/security.conf",
+	}, names)
+
+	buf.Reset()
+	assert.Nil(t, WriteZip(&buf, fs, "/app/config"))
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.Nil(t, err)
+	names = nil
+	for _, zf := range zr.File {
+		names = append(names, zf.Name)
+		if zf.Name == "app/config/security.conf" {
+			r, err := zf.Open()
+			assert.Nil(t, err)
+			data, err := ioutil.ReadAll(r)
+			assert.Nil(t, err)
+			assert.Equal(t, expected, data)
+			assert.Equal(t, efi.ModTime().Unix()/2, zf.ModTime().Unix()/2) // MS-DOS time resolution
+		}
+	}
+	assert.Equal(t, 7, len(names))
+	assert.Equal(t, "app/config/env/", names[2])
+
+	assert.NotNil(t, WriteTar(&buf, fs, "/app/missing"))
+}
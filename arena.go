@@ -0,0 +1,98 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"io"
+	"io/ioutil"
+	"sync"
+)
+
+// defaultArenaChunkSize is the size of mount data arena chunk.
+const defaultArenaChunkSize = 1 << 20 // 1MB
+
+// dataArena allocates the node data from large chunks, so the data of
+// thousands of files are few heap objects instead of one per file. Chunks
+// do not have pointers, so GC does not scan them.
+//
+// Data larger than quarter of chunk size gets its own allocation, to limit
+// the unused tail of chunks.
+type dataArena struct {
+	mu        sync.Mutex
+	chunkSize int
+	free      []byte
+	allocated int64
+}
+
+func newDataArena(chunkSize int) *dataArena {
+	return &dataArena{chunkSize: chunkSize}
+}
+
+// copy method returns the copy of data allocated from arena, nil for nil.
+func (a *dataArena) copy(data []byte) []byte {
+	if data == nil {
+		return nil
+	}
+
+	b := a.alloc(len(data))
+	copy(b, data)
+	return b
+}
+
+// readFrom method reads r until EOF into the data allocated from arena of
+// expected size, so the data is not buffered and copied. Data of unknown
+// size gets its own allocation, data beyond the size is read into grown
+// copy.
+func (a *dataArena) readFrom(r io.Reader, size int64) ([]byte, error) {
+	if size <= 0 || int64(int(size)) != size {
+		return ioutil.ReadAll(r)
+	}
+
+	b := a.alloc(int(size))
+	n, err := io.ReadFull(r, b)
+	switch err {
+	case nil:
+	case io.EOF, io.ErrUnexpectedEOF:
+		return b[:n:n], nil
+	default:
+		return nil, err
+	}
+	rest, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return append(b, rest...), nil
+	}
+	return b, nil
+}
+
+// alloc method returns the data of given size allocated from arena.
+func (a *dataArena) alloc(size int) []byte {
+	if size > a.chunkSize/4 {
+		a.mu.Lock()
+		a.allocated += int64(size)
+		a.mu.Unlock()
+		return make([]byte, size)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.free) < size {
+		a.free = make([]byte, a.chunkSize)
+		a.allocated += int64(a.chunkSize)
+	}
+	b := a.free[:size:size] // capped, append does not overwrite neighbour
+	a.free = a.free[size:]
+	return b
+}
+
+// size method returns the bytes allocated by arena, including the unused
+// tail of chunks.
+func (a *dataArena) size() int64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.allocated
+}
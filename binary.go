@@ -0,0 +1,1268 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ManifestFormat type is used to specify the asset manifest format.
+type ManifestFormat uint8
+
+// Asset manifest formats
+const (
+	ManifestJSON ManifestFormat = iota
+	ManifestCSV
+	ManifestYAML
+)
+
+// BinaryLiteral type is used to specify the Go literal of file data in
+// generated code.
+//
+// Build time of generated code for 10MB of assets (8MB binary and 2MB text)
+// was 1.5s with `LiteralString`, 41s with `LiteralByteSlice` and 1.1s with
+// `LiteralBase64`.
+type BinaryLiteral uint8
+
+// File data literals of generated code, see `BinaryOptions.Literal`
+const (
+	// LiteralString is the hex escaped string, `[]byte("\x1f\x8b...")`. Its
+	// 4 bytes of code per data byte, compiler handles it as single constant.
+	LiteralString BinaryLiteral = iota
+
+	// LiteralByteSlice is the byte slice, `[]byte{0x1f, 0x8b, ...}`. Its 6
+	// bytes of code per data byte and one syntax node per data byte, which
+	// is the slowest to compile for large files.
+	LiteralByteSlice
+
+	// LiteralBase64 is the base64 string decoded on init, its 1.34 bytes of
+	// code per data byte. Its the smallest and fastest to compile, however
+	// data is decoded at application startup. Generated code declares
+	// package level `vfsBase64`.
+	LiteralBase64
+)
+
+// BinaryOptions struct is used to customize the code generation of
+// `vfs.BinaryWithOptions`.
+type BinaryOptions struct {
+	// Manifest is the writer for machine-readable asset manifest, for e.g.
+	// file next to generated code. Manifest is not written if nil.
+	Manifest io.Writer
+
+	// ManifestFormat is the manifest format, default is `ManifestJSON`.
+	ManifestFormat ManifestFormat
+
+	// PackageName is the package name of generated code, default is `main`.
+	PackageName string
+
+	// VFSVarName is the name of package level `*vfs.VFS` variable which is
+	// populated by generated code, instead of `aah.AppVFS()`. The aah import
+	// is not generated in that case.
+	VFSVarName string
+
+	// SkipAahImports generates the code without aah framework import, so
+	// that it can be used outside of aah. If VFSVarName is empty, generated
+	// code declares the variable `AppVFS` and populates it.
+	SkipAahImports bool
+
+	// CompressionLevel is the gzip compression level of file data, see
+	// `compress/gzip` constants. Default is `gzip.BestCompression`.
+	CompressionLevel int
+
+	// MinCompressSize is the file size in bytes below which file data is
+	// stored as-is without compression.
+	MinCompressSize int64
+
+	// SkipCompressExts is the list of file extensions, for e.g. `.png`,
+	// whose data is stored as-is since its already compressed format.
+	// Default is `vfs.DefaultSkipCompressExts`, empty non-nil list
+	// compresses all the files.
+	SkipCompressExts []string
+
+	// Encoders is the map of content encoding to its encoder, for e.g. `br`
+	// with brotli encoder of third party package. Encoded data of each file
+	// is generated as pre-compressed variant, see `Mount.AddEncoded`, if its
+	// smaller than file data. Compression options apply to encoders too.
+	Encoders map[string]func(data []byte) ([]byte, error)
+
+	// Literal is the Go literal of file data, default is `LiteralString`.
+	Literal BinaryLiteral
+
+	// FollowSymlinks embeds the targets of symbolic links under physical
+	// path, for e.g.: linked package directories of npm/yarn setups. Link
+	// to directory being walked (cycle) is skipped, dangling link is an
+	// error. Symbolic links are skipped if its false.
+	FollowSymlinks bool
+
+	// Includes is the list of file patterns to embed, for e.g.
+	// `**/*.html`, other files are skipped. Pattern without `/` is matched
+	// against the file name, otherwise relative path of file, see
+	// `vfs.GlobStar` for `**` and `{a,b}` syntax. Only the directories having
+	// included files are embedded. Excludes are applied first. All the files
+	// are embedded if its empty.
+	Includes []string
+
+	// MaxEmbedSize is the file size in bytes above which file data is not
+	// embedded, for e.g. videos and archives. Such file is added as physical
+	// node, see `NodeInfo.Physical`, it is listed and its info is served from
+	// virtual tree, however its opened from physical filesystem of the mount
+	// at runtime. Value 0 means no limit.
+	MaxEmbedSize int64
+
+	// EncryptionKey is the AES key, 16, 24 or 32 bytes, to encrypt the
+	// embedded file data with AES-GCM, for e.g. licensed templates shipped
+	// in distributed binaries. Encrypted file is decrypted on open with the
+	// key set via `Mount.SetDecryptionKey` at runtime. Encoders and physical
+	// files of `MaxEmbedSize` do not apply. Data is not encrypted if its
+	// empty.
+	EncryptionKey []byte
+
+	// SigningKey is the ed25519 private key, 64 bytes, to sign the manifest
+	// of embedded file checksums. Signature is embedded along and its
+	// verified at runtime via `Mount.VerifySignature` with the public key.
+	// Manifest is not signed if its empty. Requires go1.13 or later.
+	SigningKey []byte
+
+	// Workers is the no. of files encoded concurrently, i.e. read,
+	// compressed, encrypted and formatted. Output is same regardless of
+	// workers, at most workers encoded files are held in memory. Encoders
+	// are called concurrently. Default is `runtime.GOMAXPROCS(0)`.
+	Workers int
+
+	// Report is populated with the size statistics of embedded files after
+	// generation, if its not nil. See `vfs.Report`.
+	Report *Report
+
+	// ReportTopN is the no. of largest files in report, default is
+	// `DefaultReportTopN`.
+	ReportTopN int
+
+	// Progress func is called after each file is embedded and for each
+	// directory or file skipped, for e.g. to render progress bar.
+	Progress func(BinaryProgress)
+
+	// ModTime is the fixed modification time of all directories and files
+	// in generated code, so that builds are reproducible. If it is zero then
+	// environment variable `SOURCE_DATE_EPOCH` (Unix seconds) is used if set,
+	// otherwise modification time of each file.
+	ModTime time.Time
+}
+
+// DefaultSkipCompressExts is the default file extensions of already
+// compressed formats, used by `vfs.BinaryWithOptions`.
+var DefaultSkipCompressExts = []string{
+	".png", ".jpg", ".jpeg", ".gif", ".webp", ".ico",
+	".woff", ".woff2", ".zip", ".gz", ".tgz", ".bz2", ".xz", ".br", ".zst",
+	".mp3", ".mp4", ".ogg", ".webm", ".pdf",
+}
+
+// Binary skip reasons, see `BinaryProgress.Reason`.
+const (
+	SkipExcluded     = "excluded"
+	SkipNotIncluded  = "not included"
+	SkipSymlink      = "symlink"
+	SkipSymlinkCycle = "symlink cycle"
+)
+
+// BinaryProgress struct is reported to `BinaryOptions.Progress` after each
+// file is embedded and for each directory or file skipped. Skips are
+// reported once the walk is done, before the first file is embedded.
+type BinaryProgress struct {
+	// Path is the virtual path of directory or file.
+	Path string
+
+	// Size is the file size, 0 for directory.
+	Size int64
+
+	// StoredSize is the size of embedded data after compression and
+	// encryption, 0 if skipped or file is physical.
+	StoredSize int64
+
+	// Physical is true if file is not embedded due to
+	// `BinaryOptions.MaxEmbedSize`.
+	Physical bool
+
+	// Skipped is true if directory or file is skipped, see Reason.
+	Skipped bool
+
+	// Reason is the skip reason, for e.g. `SkipExcluded`.
+	Reason string
+
+	// Files is no. of files embedded so far.
+	Files int
+
+	// TotalFiles is no. of files to embed.
+	TotalFiles int
+}
+
+// Ratio method returns the compression ratio of file, stored size divided
+// by size. It is 1 for empty, skipped and physical file.
+func (p BinaryProgress) Ratio() float64 {
+	if p.Size == 0 || p.Skipped || p.Physical {
+		return 1
+	}
+	return float64(p.StoredSize) / float64(p.Size)
+}
+
+// ManifestEntry struct represents the embedded file in asset manifest.
+type ManifestEntry struct {
+	Path       string    `json:"path"`
+	Size       int64     `json:"size"`
+	StoredSize int64     `json:"stored_size"`
+	SHA256     string    `json:"sha256"`
+	MimeType   string    `json:"mime_type"`
+	Mode       string    `json:"mode"`
+	ModTime    time.Time `json:"mtime"`
+
+	// Encoding is `gzip` if the stored data is gzipped otherwise empty.
+	Encoding string `json:"encoding"`
+}
+
+// Binary method generates the Go source code of directories and files of
+// physicalPath for mountPath. Generated code adds them into aah VFS on
+// package init for single binary build. File data is gzipped if it reduces
+// the size. Identical file contents are embedded once, duplicate file is
+// added with `Mount.AddLink` so the data is shared at runtime too.
+//
+// Directory or file name matching any of the excludes pattern (see
+// `filepath.Match`) is skipped. Pattern is matched against the name and path
+// relative to physicalPath.
+//
+// Entries are generated in lexical order of path. With fixed modification
+// time, see `BinaryOptions.ModTime`, two runs over the same tree produce
+// byte-identical code.
+func Binary(mountPath, physicalPath string, excludes []string) ([]byte, error) {
+	return BinaryWithOptions(mountPath, physicalPath, excludes, BinaryOptions{})
+}
+
+// BinaryWithOptions method is same as `vfs.Binary` with given options.
+func BinaryWithOptions(mountPath, physicalPath string, excludes []string, opts BinaryOptions) ([]byte, error) {
+	return BinaryContext(context.Background(), mountPath, physicalPath, excludes, opts)
+}
+
+// BinaryContext method is same as `vfs.BinaryWithOptions` but generation is
+// cancelled when ctx is done, for e.g. build tool or editor invoking it. Ctx
+// is checked for each directory entry during walk and before each file is
+// encoded, on cancel it returns the ctx error.
+func BinaryContext(ctx context.Context, mountPath, physicalPath string, excludes []string, opts BinaryOptions) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := binaryTo(ctx, buf, mountPath, physicalPath, excludes, opts); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// BinaryDryRun method performs the `vfs.BinaryWithOptions` walk, applies
+// the excludes, includes and size options and encodes the files, without
+// generating code. It returns the manifest entries of files would be
+// embedded with projected stored size, for e.g. to tune skip lists. Manifest
+// is not written, report and progress are populated.
+func BinaryDryRun(mountPath, physicalPath string, excludes []string, opts BinaryOptions) ([]ManifestEntry, error) {
+	g, err := newBinaryGen(context.Background(), mountPath, physicalPath, excludes, opts)
+	if err != nil {
+		return nil, err
+	}
+	g.dryRun = true
+	g.opts.Manifest = nil
+
+	bw := bufio.NewWriter(ioutil.Discard)
+	err = g.encodeEntries(g.entries, func(e binaryEntry, ef *encodedFile) error {
+		return g.writeEntry(bw, e, ef)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = g.finish(); err != nil {
+		return nil, err
+	}
+	return g.manifest, nil
+}
+
+// BinaryTo method is same as `vfs.BinaryWithOptions` but it streams the
+// generated code into w, so only the file data being encoded is held in
+// memory regardless of the size of physicalPath, see `BinaryOptions.Workers`.
+// Code is written in gofmt style as it is generated, it does not need
+// `go/format`.
+//
+// On error w may have partial code.
+func BinaryTo(w io.Writer, mountPath, physicalPath string, excludes []string, opts BinaryOptions) error {
+	return binaryTo(context.Background(), w, mountPath, physicalPath, excludes, opts)
+}
+
+// BinarySplit method is same as `vfs.BinaryWithOptions` but it splits the
+// generated code into multiple files of same package, since large asset
+// tree produces enormous file which is slow to compile.
+//
+// Files are distributed into n shards balanced by file size, if n is zero
+// then one shard per top level directory of physicalPath. First code is the
+// main file, it has directories, top level files and init func which runs
+// the shards registered by rest of the codes. Empty shards are not generated.
+// Each code has to be written into its own `.go` file, for e.g.:
+// `vfs_main.go`, `vfs_shard1.go`, etc. Generated code declares package level
+// `vfsShards` and `vfsAddShard`.
+func BinarySplit(mountPath, physicalPath string, excludes []string, opts BinaryOptions, n int) ([][]byte, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("vfs: invalid no. of shards %d", n)
+	}
+	g, err := newBinaryGen(context.Background(), mountPath, physicalPath, excludes, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	main, shards := g.split(n)
+	codes := make([][]byte, 0, len(shards)+1)
+	buf := new(bytes.Buffer)
+	bw := bufio.NewWriter(buf)
+	if err = writeBinaryHeader(bw, opts, g.mountPath, g.physicalPath); err != nil {
+		return nil, err
+	}
+	err = g.encodeEntries(main, func(e binaryEntry, ef *encodedFile) error {
+		return g.writeEntry(bw, e, ef)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(shards) == 0 {
+		if err = g.writeSignature(bw); err != nil {
+			return nil, err
+		}
+	}
+	fmt.Fprintf(bw, binarySplitFooter, len(shards))
+	if err = bw.Flush(); err != nil {
+		return nil, err
+	}
+	codes = append(codes, buf.Bytes())
+
+	pkg := opts.PackageName
+	if pkg == "" {
+		pkg = "main"
+	}
+	for i, shard := range shards {
+		buf = new(bytes.Buffer)
+		bw = bufio.NewWriter(buf)
+		fmt.Fprintf(bw, binaryShardHeader, pkg, i)
+		err = g.encodeEntries(shard, func(e binaryEntry, ef *encodedFile) error {
+			return g.writeEntry(bw, e, ef)
+		})
+		if err != nil {
+			return nil, err
+		}
+		// signature covers all the shards, so its written by the last one
+		if i == len(shards)-1 {
+			if err = g.writeSignature(bw); err != nil {
+				return nil, err
+			}
+		}
+		_, _ = bw.WriteString("\treturn nil\n})\n")
+		if err = bw.Flush(); err != nil {
+			return nil, err
+		}
+		codes = append(codes, buf.Bytes())
+	}
+
+	if err = g.finish(); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Binary unexported methods
+//______________________________________________________________________________
+
+// binaryTo method is same as `vfs.BinaryTo` with ctx, see `vfs.BinaryContext`.
+func binaryTo(ctx context.Context, w io.Writer, mountPath, physicalPath string, excludes []string, opts BinaryOptions) error {
+	g, err := newBinaryGen(ctx, mountPath, physicalPath, excludes, opts)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	if err = writeBinaryHeader(bw, opts, g.mountPath, g.physicalPath); err != nil {
+		return err
+	}
+	err = g.encodeEntries(g.entries, func(e binaryEntry, ef *encodedFile) error {
+		return g.writeEntry(bw, e, ef)
+	})
+	if err != nil {
+		return err
+	}
+	if err = g.writeSignature(bw); err != nil {
+		return err
+	}
+	_, _ = bw.WriteString("}\n")
+	if err = bw.Flush(); err != nil {
+		return err
+	}
+	return g.finish()
+}
+
+const binarySplitFooter = `	for i := 0; i < %d; i++ {
+		shard, found := vfsShards[i]
+		if !found {
+			log.Fatalf("vfs: shard %%d is not found", i)
+		}
+		add(shard(m))
+	}
+}
+
+// vfsShards is the generated shards of VFS, indexed by shard no.
+var vfsShards = make(map[int]func(m *vfs.Mount) error)
+
+// vfsAddShard registers the shard, its used by package variable declaration
+// of shard so that its registered before init func.
+func vfsAddShard(i int, f func(m *vfs.Mount) error) bool {
+	vfsShards[i] = f
+	return true
+}
+`
+
+const binaryShardHeader = `// Code generated by aah vfs, DO NOT EDIT.
+
+package %s
+
+import (
+	"time"
+
+	"aahframework.org/vfs.v0"
+)
+
+var _ = vfsAddShard(%d, func(m *vfs.Mount) error {
+	var err error
+	add := func(e error) {
+		if err == nil {
+			err = e
+		}
+	}
+
+`
+
+// binaryEntry is the directory or file of binary generation.
+type binaryEntry struct {
+	fpath string
+	vpath string
+	top   string // top level directory name, empty for top level file
+	fi    os.FileInfo
+}
+
+// binaryGen generates the code of entries, manifest is collected as entries
+// are written.
+type binaryGen struct {
+	ctx          context.Context
+	mountPath    string
+	physicalPath string
+	opts         BinaryOptions
+	modTime      time.Time
+	entries      []binaryEntry
+	manifest     []ManifestEntry
+	written      map[string]ManifestEntry // by data key, see `dataKey`
+	gcm          cipher.AEAD              // nil unless encryption key is set
+	pack         bool                     // true if its used by `vfs.Pack`
+	dryRun       bool                     // true if its used by `vfs.BinaryDryRun`
+	skipped      []BinaryProgress
+	files        int
+	totalFiles   int
+}
+
+func newBinaryGen(ctx context.Context, mountPath, physicalPath string, excludes []string, opts BinaryOptions) (*binaryGen, error) {
+	g := &binaryGen{
+		ctx:          ctx,
+		mountPath:    path.Clean("/" + filepath.ToSlash(mountPath)),
+		physicalPath: filepath.Clean(physicalPath),
+		opts:         opts,
+		written:      make(map[string]ManifestEntry),
+	}
+	var err error
+	if g.modTime, err = binaryModTime(opts.ModTime); err != nil {
+		return nil, err
+	}
+	if len(opts.EncryptionKey) > 0 {
+		if g.gcm, err = newGCM(opts.EncryptionKey); err != nil {
+			return nil, err
+		}
+	}
+	includes, err := expandPatterns(opts.Includes)
+	if err != nil {
+		return nil, err
+	}
+
+	fi, err := os.Stat(g.physicalPath)
+	if err != nil {
+		return nil, err
+	}
+	if fi.IsDir() {
+		visited := make(map[string]bool)
+		if err = g.walk(g.physicalPath, "", excludes, visited); err != nil {
+			return nil, err
+		}
+	}
+	if len(includes) > 0 {
+		var dropped []binaryEntry
+		g.entries, dropped = filterIncluded(g.entries, includes, g.mountPath)
+		for _, e := range dropped {
+			g.skip(e.vpath, e.fi, SkipNotIncluded)
+		}
+	}
+
+	for _, e := range g.entries {
+		if !e.fi.IsDir() {
+			g.totalFiles++
+		}
+	}
+	if g.opts.Progress != nil {
+		for _, p := range g.skipped {
+			p.TotalFiles = g.totalFiles
+			g.opts.Progress(p)
+		}
+	}
+	return g, nil
+}
+
+// walk method collects the entries of directory in lexical order, rel is
+// slash separated path of dir relative to physical path. Symbolic links are
+// skipped unless `BinaryOptions.FollowSymlinks`, visited has the real paths
+// of directories being walked for cycle detection.
+func (g *binaryGen) walk(dir, rel string, excludes []string, visited map[string]bool) error {
+	if g.opts.FollowSymlinks {
+		real, err := filepath.EvalSymlinks(dir)
+		if err != nil {
+			return err
+		}
+		visited[real] = true
+		defer delete(visited, real)
+	}
+
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, fi := range infos {
+		if err = g.ctx.Err(); err != nil {
+			return err
+		}
+		fpath := filepath.Join(dir, fi.Name())
+		frel := path.Join(rel, fi.Name())
+		vpath := path.Join(g.mountPath, frel)
+		if isExcluded(excludes, frel) {
+			g.skip(vpath, fi, SkipExcluded)
+			continue
+		}
+		if fi.Mode()&os.ModeSymlink != 0 {
+			if !g.opts.FollowSymlinks {
+				g.skip(vpath, fi, SkipSymlink)
+				continue
+			}
+			if fi, err = os.Stat(fpath); err != nil {
+				return err
+			}
+			if fi.IsDir() {
+				real, err := filepath.EvalSymlinks(fpath)
+				if err != nil {
+					return err
+				}
+				if visited[real] {
+					// cycle, link to directory being walked
+					g.skip(vpath, fi, SkipSymlinkCycle)
+					continue
+				}
+			}
+		}
+
+		e := binaryEntry{fpath: fpath, vpath: vpath, fi: fi}
+		if i := strings.IndexByte(frel, '/'); i > 0 {
+			e.top = frel[:i]
+		}
+		g.entries = append(g.entries, e)
+		if fi.IsDir() {
+			if err = g.walk(fpath, frel, excludes, visited); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// expandPatterns method returns the brace expanded patterns, it validates
+// each pattern.
+func expandPatterns(patterns []string) ([]string, error) {
+	var expanded []string
+	for _, pattern := range patterns {
+		list, err := expandBraces(pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range list {
+			if _, err = path.Match(p, ""); err != nil {
+				return nil, err
+			}
+		}
+		expanded = append(expanded, list...)
+	}
+	return expanded, nil
+}
+
+// filterIncluded method returns the files matching any of includes and
+// directories having them, in same order, and the rest.
+func filterIncluded(entries []binaryEntry, includes []string, mountPath string) ([]binaryEntry, []binaryEntry) {
+	keep := make(map[string]bool)
+	for _, e := range entries {
+		if e.fi.IsDir() || !isIncluded(includes, strings.TrimPrefix(e.vpath, mountPath+"/")) {
+			continue
+		}
+		for p := e.vpath; p != mountPath && !keep[p]; p = path.Dir(p) {
+			keep[p] = true
+		}
+	}
+
+	var filtered, dropped []binaryEntry
+	for _, e := range entries {
+		if keep[e.vpath] {
+			filtered = append(filtered, e)
+		} else {
+			dropped = append(dropped, e)
+		}
+	}
+	return filtered, dropped
+}
+
+// isIncluded method reports whether slash separated relative path matches
+// any of the patterns.
+func isIncluded(patterns []string, rel string) bool {
+	segs := splitPath(rel)
+	for _, p := range patterns {
+		if !strings.Contains(p, "/") {
+			if ok, _ := path.Match(p, path.Base(rel)); ok {
+				return true
+			}
+			continue
+		}
+		if matchStar(splitPath(p), segs) {
+			return true
+		}
+	}
+	return false
+}
+
+// split method returns the entries of main file and shards. Directories and
+// top level files are in main file.
+func (g *binaryGen) split(n int) ([]binaryEntry, [][]binaryEntry) {
+	var main []binaryEntry
+	var shards [][]binaryEntry
+	sizes := make([]int64, n)
+	if n > 0 {
+		shards = make([][]binaryEntry, n)
+	}
+	tops := make(map[string]int)
+
+	for _, e := range g.entries {
+		switch {
+		case e.fi.IsDir() || e.top == "":
+			main = append(main, e)
+		case n > 0:
+			i := 0
+			for j := range sizes {
+				if sizes[j] < sizes[i] {
+					i = j
+				}
+			}
+			sizes[i] += e.fi.Size()
+			shards[i] = append(shards[i], e)
+		default:
+			i, found := tops[e.top]
+			if !found {
+				i = len(shards)
+				tops[e.top] = i
+				shards = append(shards, nil)
+			}
+			shards[i] = append(shards[i], e)
+		}
+	}
+
+	nonEmpty := shards[:0]
+	for _, shard := range shards {
+		if len(shard) > 0 {
+			nonEmpty = append(nonEmpty, shard)
+		}
+	}
+	return main, nonEmpty
+}
+
+// encodedFile is the file entry encoded for code generation or pack.
+type encodedFile struct {
+	me       ManifestEntry
+	key      string // data key, see `dataKey`
+	stored   []byte // file data compressed and encrypted
+	code     []byte // add statements of file, unless pack or dry run
+	physical bool
+}
+
+// encodeEntries method encodes the file entries concurrently per
+// `BinaryOptions.Workers` and calls fn for each entry with its encoded file,
+// nil for directory, in order of entries. At most workers entries are held
+// encoded ahead of fn.
+func (g *binaryGen) encodeEntries(entries []binaryEntry, fn func(binaryEntry, *encodedFile) error) error {
+	type result struct {
+		ef  *encodedFile
+		err error
+	}
+
+	workers := g.opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	done := make(chan struct{})
+	defer close(done)
+
+	// queue holds the results in order of entries, one is taken by consumer
+	queue := make(chan chan result, workers-1)
+	go func() {
+		defer close(queue)
+		for _, e := range entries {
+			ch := make(chan result, 1)
+			select {
+			case queue <- ch:
+			case <-done:
+				return
+			}
+			go func(e binaryEntry) {
+				ef, err := g.encode(e)
+				ch <- result{ef: ef, err: err}
+			}(e)
+		}
+	}()
+
+	i := 0
+	for ch := range queue {
+		r := <-ch
+		if r.err == nil {
+			r.err = g.ctx.Err()
+		}
+		if r.err == nil {
+			r.err = fn(entries[i], r.ef)
+		}
+		if r.err != nil {
+			return r.err
+		}
+		i++
+	}
+	return nil
+}
+
+// encode method reads, compresses and encrypts the file data of entry and
+// formats its add statements unless pack or dry run. It returns nil for
+// directory. It is called concurrently, so it does not modify the generator
+// state.
+func (g *binaryGen) encode(e binaryEntry) (*encodedFile, error) {
+	if err := g.ctx.Err(); err != nil {
+		return nil, err
+	}
+	if e.fi.IsDir() {
+		return nil, nil
+	}
+	if !g.pack && g.opts.MaxEmbedSize > 0 && e.fi.Size() > g.opts.MaxEmbedSize {
+		return g.encodePhysical(e)
+	}
+
+	data, err := ioutil.ReadFile(e.fpath)
+	if err != nil {
+		return nil, err
+	}
+	compress := shouldCompress(g.opts, e.vpath, int64(len(data)))
+	sum := sha256.Sum256(data)
+	stored, gzipped := data, false
+	if compress {
+		if stored, gzipped, err = gzipIfSmaller(data, g.opts.CompressionLevel); err != nil {
+			return nil, err
+		}
+	}
+	ef := &encodedFile{me: g.manifestEntry(e, data, stored), key: dataKey(sum[:], compress)}
+	if gzipped {
+		ef.me.Encoding = EncodingGzip
+	}
+	if g.gcm != nil {
+		stored = encryptData(g.gcm, g.opts.EncryptionKey, stored)
+		ef.me.StoredSize = int64(len(stored))
+	}
+	ef.stored = stored
+	if g.pack || g.dryRun {
+		return ef, nil
+	}
+
+	buf := new(bytes.Buffer)
+	bw := bufio.NewWriter(buf)
+	fmt.Fprintf(bw, "\tadd(m.AddFile(&vfs.NodeInfo{DataSize: %d, Path: %q, Time: %s, SHA256: %q%s%s}, ",
+		len(data), e.vpath, g.timeLiteral(e.fi), ef.me.SHA256, g.encryptedField(), gzipField(gzipped, data))
+	writeDataLiteral(bw, g.opts.Literal, stored)
+	_, _ = bw.WriteString("))\n")
+	if g.gcm == nil {
+		if err = writeEncoded(bw, g.opts, e.vpath, data); err != nil {
+			return nil, err
+		}
+	}
+	if err = bw.Flush(); err != nil {
+		return nil, err
+	}
+	ef.code = buf.Bytes()
+	return ef, nil
+}
+
+// encodePhysical method returns the encoded physical file, file is streamed
+// to compute the checksum.
+func (g *binaryGen) encodePhysical(e binaryEntry) (*encodedFile, error) {
+	f, err := os.Open(e.fpath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	head := make([]byte, 512) // content sniffing length
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	h := sha256.New()
+	_, _ = h.Write(head[:n])
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return nil, err
+	}
+	size += int64(n)
+
+	me := g.manifestEntry(e, head[:n], nil)
+	me.Size = size
+	me.SHA256 = hex.EncodeToString(h.Sum(nil))
+	code := fmt.Sprintf("\tadd(m.AddFile(&vfs.NodeInfo{DataSize: %d, Path: %q, Time: %s, SHA256: %q, Physical: true}, nil))\n",
+		size, e.vpath, g.timeLiteral(e.fi), me.SHA256)
+	return &encodedFile{me: me, code: []byte(code), physical: true}, nil
+}
+
+// writeEntry method writes the add statements of entry with its encoded
+// file.
+func (g *binaryGen) writeEntry(bw *bufio.Writer, e binaryEntry, ef *encodedFile) error {
+	if e.fi.IsDir() {
+		fmt.Fprintf(bw, "\tadd(m.AddDir(&vfs.NodeInfo{Dir: true, Path: %q, Time: %s}))\n",
+			e.vpath, g.timeLiteral(e.fi))
+		return nil
+	}
+
+	me := ef.me
+	if ef.physical {
+		_, _ = bw.Write(ef.code)
+		g.embedded(me, true)
+		return nil
+	}
+
+	// duplicate content refers the data of file written first
+	if first, found := g.written[ef.key]; found {
+		me.StoredSize, me.Encoding = first.StoredSize, first.Encoding
+		fmt.Fprintf(bw, "\tadd(m.AddLink(&vfs.NodeInfo{DataSize: %d, Path: %q, Time: %s, SHA256: %q%s}, %q))\n",
+			me.Size, e.vpath, g.timeLiteral(e.fi), me.SHA256, g.encryptedField(), first.Path)
+		g.embedded(me, false)
+		return nil
+	}
+
+	_, _ = bw.Write(ef.code)
+	g.written[ef.key] = me
+	g.embedded(me, false)
+	return nil
+}
+
+// dataKey method returns the key of file data for deduplication, data is
+// shared only if its stored same way.
+func dataKey(sum []byte, compress bool) string {
+	return hex.EncodeToString(sum) + ":" + strconv.FormatBool(compress)
+}
+
+// encryptedField method returns the `NodeInfo.Encrypted` field literal of
+// file, empty if encryption is not enabled.
+func (g *binaryGen) encryptedField() string {
+	if g.gcm != nil {
+		return ", Encrypted: true"
+	}
+	return ""
+}
+
+// gzipField method returns the `NodeInfo.Gzip` field literal of gzipped
+// file, `NodeInfo.Raw` if its data is stored as-is and starts with gzip
+// member header, otherwise empty.
+func gzipField(gzipped bool, data []byte) string {
+	switch {
+	case gzipped:
+		return ", Gzip: true"
+	case bytes.HasPrefix(data, gzipMemberHeader):
+		return ", Raw: true"
+	}
+	return ""
+}
+
+// manifestEntry method returns the manifest entry of file entry with its
+// data and stored data.
+func (g *binaryGen) manifestEntry(e binaryEntry, data, stored []byte) ManifestEntry {
+	me := newManifestEntry(e.vpath, data, stored)
+	me.Mode = e.fi.Mode().String()
+	me.ModTime = g.modTimeOf(e.fi)
+	return me
+}
+
+// embedded method records the manifest entry of embedded file and reports
+// the progress.
+func (g *binaryGen) embedded(me ManifestEntry, physical bool) {
+	g.manifest = append(g.manifest, me)
+	g.files++
+	if g.opts.Progress != nil {
+		g.opts.Progress(BinaryProgress{Path: me.Path, Size: me.Size, StoredSize: me.StoredSize,
+			Physical: physical, Files: g.files, TotalFiles: g.totalFiles})
+	}
+}
+
+// skip method records the skipped directory or file, its reported after the
+// walk.
+func (g *binaryGen) skip(vpath string, fi os.FileInfo, reason string) {
+	if g.opts.Progress == nil {
+		return
+	}
+	p := BinaryProgress{Path: vpath, Skipped: true, Reason: reason}
+	if !fi.IsDir() {
+		p.Size = fi.Size()
+	}
+	g.skipped = append(g.skipped, p)
+}
+
+// modTimeOf method returns the modification time of file info in generated
+// output, see `BinaryOptions.ModTime`.
+func (g *binaryGen) modTimeOf(fi os.FileInfo) time.Time {
+	if !g.modTime.IsZero() {
+		return g.modTime
+	}
+	return fi.ModTime()
+}
+
+func (g *binaryGen) timeLiteral(fi os.FileInfo) string {
+	t := g.modTimeOf(fi)
+	return fmt.Sprintf("time.Unix(%d, %d)", t.Unix(), t.Nanosecond())
+}
+
+// finish method populates the report and writes the manifest, if
+// requested.
+func (g *binaryGen) finish() error {
+	if g.opts.Report != nil {
+		n := g.opts.ReportTopN
+		if n <= 0 {
+			n = DefaultReportTopN
+		}
+		*g.opts.Report = NewReport(g.manifest, n)
+	}
+	if g.opts.Manifest != nil {
+		return writeManifest(g.opts.Manifest, g.opts.ManifestFormat, g.manifest)
+	}
+	return nil
+}
+
+const binaryHeader = `// Code generated by aah vfs, DO NOT EDIT.
+
+package %s
+
+import (
+%s	"log"
+	"time"
+
+%s	"aahframework.org/vfs.v0"
+)
+%s
+func init() {
+	fs := %s
+	fs.SetEmbeddedMode()
+	_ = fs.AddMount(%q, %q)
+
+	m, err := fs.FindMount(%q)
+	if err != nil {
+		log.Fatal(err)
+	}
+	add := func(err error) {
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+`
+
+const binaryBase64Decl = `
+// vfsBase64 decodes the base64 file data of generated code.
+func vfsBase64(s string) []byte {
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return data
+}
+`
+
+// writeBinaryHeader method writes the package clause, imports and beginning
+// of init func per given options.
+func writeBinaryHeader(w io.Writer, opts BinaryOptions, mountPath, physicalPath string) error {
+	pkg := opts.PackageName
+	if pkg == "" {
+		pkg = "main"
+	}
+	if !isIdentifier(pkg) {
+		return fmt.Errorf("vfs: invalid package name %q", pkg)
+	}
+	if opts.VFSVarName != "" && !isIdentifier(opts.VFSVarName) {
+		return fmt.Errorf("vfs: invalid variable name %q", opts.VFSVarName)
+	}
+
+	// aah import is used only by `aah.AppVFS()`
+	aahImport, decl, fs := "", "", opts.VFSVarName
+	switch {
+	case fs != "":
+	case opts.SkipAahImports:
+		decl, fs = "\n// AppVFS is the virtual filesystem of embedded files.\nvar AppVFS = new(vfs.VFS)\n", "AppVFS"
+	default:
+		aahImport, fs = "\t\"aahframework.org/aah.v0\"\n", "aah.AppVFS()"
+	}
+
+	stdImport := ""
+	if opts.Literal == LiteralBase64 {
+		stdImport = "\t\"encoding/base64\"\n"
+		decl += binaryBase64Decl
+	}
+
+	_, err := fmt.Fprintf(w, binaryHeader, pkg, stdImport, aahImport, decl, fs, mountPath, physicalPath, mountPath)
+	return err
+}
+
+// isIdentifier method reports whether s is a Go identifier.
+func isIdentifier(s string) bool {
+	for i, c := range s {
+		if !unicode.IsLetter(c) && c != '_' && (i == 0 || !unicode.IsDigit(c)) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func isExcluded(excludes []string, rel string) bool {
+	rel = filepath.ToSlash(rel)
+	for _, pattern := range excludes {
+		if ok, _ := path.Match(pattern, path.Base(rel)); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, rel); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// binaryModTime method returns the fixed modification time for generated
+// code, zero time means file modification time.
+func binaryModTime(t time.Time) (time.Time, error) {
+	if !t.IsZero() {
+		return t, nil
+	}
+	epoch := os.Getenv("SOURCE_DATE_EPOCH")
+	if epoch == "" {
+		return t, nil
+	}
+	sec, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil {
+		return t, fmt.Errorf("vfs: invalid SOURCE_DATE_EPOCH %q", epoch)
+	}
+	return time.Unix(sec, 0), nil
+}
+
+// shouldCompress method returns true if file data of vpath with given size
+// should be compressed per options.
+func shouldCompress(opts BinaryOptions, vpath string, size int64) bool {
+	if size < opts.MinCompressSize {
+		return false
+	}
+	exts := opts.SkipCompressExts
+	if exts == nil {
+		exts = DefaultSkipCompressExts
+	}
+	ext := strings.ToLower(path.Ext(vpath))
+	for _, e := range exts {
+		if !strings.HasPrefix(e, ".") {
+			e = "." + e
+		}
+		if ext == strings.ToLower(e) {
+			return false
+		}
+	}
+	return true
+}
+
+// writeEncoded method writes the pre-compressed variants of file data per
+// encoders in sorted order of encoding.
+func writeEncoded(bw *bufio.Writer, opts BinaryOptions, vpath string, data []byte) error {
+	if len(opts.Encoders) == 0 || !shouldCompress(opts, vpath, int64(len(data))) {
+		return nil
+	}
+
+	encodings := make([]string, 0, len(opts.Encoders))
+	for enc := range opts.Encoders {
+		encodings = append(encodings, enc)
+	}
+	sort.Strings(encodings)
+
+	for _, enc := range encodings {
+		encoded, err := opts.Encoders[enc](data)
+		if err != nil {
+			return fmt.Errorf("vfs: %s encoding of %s: %v", enc, vpath, err)
+		}
+		if len(encoded) >= len(data) {
+			continue
+		}
+		fmt.Fprintf(bw, "\tadd(m.AddEncoded(%q, %q, ", vpath, enc)
+		writeDataLiteral(bw, opts.Literal, encoded)
+		_, _ = bw.WriteString("))\n")
+	}
+	return nil
+}
+
+// gzipIfSmaller method returns gzip data of given level if its smaller than
+// given data, otherwise data as-is. Level zero means best compression.
+func gzipIfSmaller(data []byte, level int) ([]byte, bool, error) {
+	if level == gzip.NoCompression {
+		level = gzip.BestCompression
+	}
+	buf := new(bytes.Buffer)
+	gw, err := gzip.NewWriterLevel(buf, level)
+	if err != nil {
+		return nil, false, err
+	}
+	if _, err := gw.Write(data); err != nil {
+		return nil, false, err
+	}
+	if err := gw.Close(); err != nil {
+		return nil, false, err
+	}
+
+	if buf.Len() < len(data) {
+		return buf.Bytes(), true, nil
+	}
+	return data, false, nil
+}
+
+// writeDataLiteral method writes data as Go expression of given literal.
+func writeDataLiteral(w *bufio.Writer, literal BinaryLiteral, data []byte) {
+	switch literal {
+	case LiteralByteSlice:
+		const hextable = "0123456789abcdef"
+		w.WriteString("[]byte{")
+		for i, b := range data {
+			if i > 0 {
+				w.WriteString(", ")
+			}
+			w.WriteString("0x")
+			w.WriteByte(hextable[b>>4])
+			w.WriteByte(hextable[b&0x0f])
+		}
+		w.WriteByte('}')
+	case LiteralBase64:
+		w.WriteString(`vfsBase64("`)
+		enc := base64.NewEncoder(base64.StdEncoding, w)
+		_, _ = enc.Write(data)
+		_ = enc.Close()
+		w.WriteString(`")`)
+	default:
+		w.WriteString("[]byte(")
+		writeByteString(w, data)
+		w.WriteByte(')')
+	}
+}
+
+// writeByteString method writes data as Go interpreted string literal, each
+// byte is hex escaped.
+func writeByteString(w *bufio.Writer, data []byte) {
+	const hextable = "0123456789abcdef"
+	w.WriteByte('"')
+	for _, b := range data {
+		w.WriteString(`\x`)
+		w.WriteByte(hextable[b>>4])
+		w.WriteByte(hextable[b&0x0f])
+	}
+	w.WriteByte('"')
+}
+
+func newManifestEntry(vpath string, data, stored []byte) ManifestEntry {
+	sum := sha256.Sum256(data)
+	mimeType := mime.TypeByExtension(path.Ext(vpath))
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+	return ManifestEntry{
+		Path:       vpath,
+		Size:       int64(len(data)),
+		StoredSize: int64(len(stored)),
+		SHA256:     hex.EncodeToString(sum[:]),
+		MimeType:   mimeType,
+	}
+}
+
+func writeManifest(w io.Writer, f ManifestFormat, entries []ManifestEntry) error {
+	switch f {
+	case ManifestCSV:
+		cw := csv.NewWriter(w)
+		_ = cw.Write([]string{"path", "size", "stored_size", "sha256", "mime_type", "mode", "mtime", "encoding"})
+		for _, e := range entries {
+			_ = cw.Write([]string{e.Path, strconv.FormatInt(e.Size, 10),
+				strconv.FormatInt(e.StoredSize, 10), e.SHA256, e.MimeType,
+				e.Mode, e.ModTime.Format(time.RFC3339Nano), e.Encoding})
+		}
+		cw.Flush()
+		return cw.Error()
+	case ManifestYAML:
+		return writeManifestYAML(w, entries)
+	}
+
+	if entries == nil {
+		entries = []ManifestEntry{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
+
+// writeManifestYAML method writes the entries as YAML sequence, strings are
+// double-quoted since Go escapes are valid YAML escapes.
+func writeManifestYAML(w io.Writer, entries []ManifestEntry) error {
+	bw := bufio.NewWriter(w)
+	if len(entries) == 0 {
+		_, _ = bw.WriteString("[]\n")
+	}
+	for _, e := range entries {
+		fmt.Fprintf(bw, "- path: %s\n  size: %d\n  stored_size: %d\n  sha256: %s\n"+
+			"  mime_type: %s\n  mode: %s\n  mtime: %s\n  encoding: %s\n",
+			strconv.Quote(e.Path), e.Size, e.StoredSize, strconv.Quote(e.SHA256),
+			strconv.Quote(e.MimeType), strconv.Quote(e.Mode),
+			e.ModTime.Format(time.RFC3339Nano), strconv.Quote(e.Encoding))
+	}
+	return bw.Flush()
+}
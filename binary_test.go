@@ -0,0 +1,653 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"aahframework.org/test.v0/assert"
+)
+
+func TestVFSBinary(t *testing.T) {
+	src := filepath.Join(testdataBaseDir(), "vfstest", "static")
+	manifest := new(bytes.Buffer)
+	code, err := BinaryWithOptions("/app/static", src, []string{"*.ico", "js"},
+		BinaryOptions{Manifest: manifest})
+	assert.Nil(t, err)
+	assert.True(t, bytes.HasPrefix(code, []byte("// Code generated by aah vfs, DO NOT EDIT.\n\npackage main\n")))
+
+	files := parseBinaryFiles(t, code)
+	assert.Equal(t, []string{"/app/static/css/aah.css", "/app/static/img/aah-framework-logo.png", "/app/static/robots.txt"}, sortedKeysOf(files))
+	for vpath, data := range files {
+		expected, err := ioutil.ReadFile(filepath.Join(src, filepath.FromSlash(strings.TrimPrefix(vpath, "/app/static"))))
+		assert.Nil(t, err)
+		assert.Equal(t, expected, data)
+	}
+	assert.True(t, bytes.Contains(code, []byte(`add(m.AddDir(&vfs.NodeInfo{Dir: true, Path: "/app/static/css", Time: time.Unix(`)))
+
+	var entries []ManifestEntry
+	assert.Nil(t, json.Unmarshal(manifest.Bytes(), &entries))
+	assert.Equal(t, 3, len(entries))
+	assert.Equal(t, "/app/static/css/aah.css", entries[0].Path)
+	assert.Equal(t, "text/css; charset=utf-8", entries[0].MimeType)
+	assert.Equal(t, int64(len(files["/app/static/css/aah.css"])), entries[0].Size)
+	assert.True(t, entries[0].StoredSize < entries[0].Size)
+	assert.Equal(t, 64, len(entries[0].SHA256))
+	assert.Equal(t, "image/png", entries[1].MimeType)
+	assert.Equal(t, entries[1].Size, entries[1].StoredSize)
+
+	manifest.Reset()
+	_, err = BinaryWithOptions("/app/static", src, nil, BinaryOptions{Manifest: manifest, ManifestFormat: ManifestCSV})
+	assert.Nil(t, err)
+	records, err := csv.NewReader(manifest).ReadAll()
+	assert.Nil(t, err)
+	assert.Equal(t, 6, len(records))
+	assert.Equal(t, []string{"path", "size", "stored_size", "sha256", "mime_type", "mode", "mtime", "encoding"}, records[0])
+	assert.Equal(t, EncodingGzip, records[1][7])
+
+	manifest.Reset()
+	mt := time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+	_, err = BinaryWithOptions("/app/static", src, []string{"img", "js"}, BinaryOptions{
+		Manifest: manifest, ManifestFormat: ManifestYAML, ModTime: mt, MinCompressSize: 1 << 20})
+	assert.Nil(t, err)
+	robots, err := os.Stat(filepath.Join(src, "robots.txt"))
+	assert.Nil(t, err)
+	yaml := manifest.String()
+	assert.True(t, strings.HasPrefix(yaml, "- path: \"/app/static/css/aah.css\"\n  size: "))
+	assert.True(t, strings.Contains(yaml, "- path: \"/app/static/robots.txt\"\n  size: "+strconv.FormatInt(robots.Size(), 10)+"\n"))
+	assert.True(t, strings.Contains(yaml, "  mime_type: \"text/plain; charset=utf-8\"\n  mode: \""+robots.Mode().String()+"\"\n"))
+	assert.True(t, strings.Contains(yaml, "  mtime: 2018-01-02T03:04:05Z\n  encoding: \"\"\n"))
+	assert.Equal(t, 2, strings.Count(yaml, "- path: "))
+
+	_, err = Binary("/app", filepath.Join(testdataBaseDir(), "not-exists"), nil)
+	assert.NotNil(t, err)
+
+	// streaming output is gofmt style
+	out := new(bytes.Buffer)
+	assert.Nil(t, BinaryTo(out, "/app/static", src, nil, BinaryOptions{}))
+	formatted, err := format.Source(out.Bytes())
+	assert.Nil(t, err)
+	assert.Equal(t, string(formatted), out.String())
+	assert.Equal(t, 5, len(parseBinaryFiles(t, out.Bytes())))
+}
+
+func TestVFSBinaryReproducible(t *testing.T) {
+	src := filepath.Join(testdataBaseDir(), "vfstest", "static")
+	mtime := time.Date(2018, 3, 12, 10, 20, 30, 0, time.UTC)
+	code1, err := BinaryWithOptions("/app/static", src, nil, BinaryOptions{ModTime: mtime})
+	assert.Nil(t, err)
+	code2, err := BinaryWithOptions("/app/static", src, nil, BinaryOptions{ModTime: mtime})
+	assert.Nil(t, err)
+	assert.Equal(t, code1, code2)
+	assert.Equal(t, 8, bytes.Count(code1, []byte(fmt.Sprintf("Time: time.Unix(%d, 0)", mtime.Unix()))))
+
+	defer func() { _ = os.Unsetenv("SOURCE_DATE_EPOCH") }()
+	assert.Nil(t, os.Setenv("SOURCE_DATE_EPOCH", strconv.FormatInt(mtime.Unix(), 10)))
+	code3, err := Binary("/app/static", src, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, code1, code3)
+
+	assert.Nil(t, os.Setenv("SOURCE_DATE_EPOCH", "yesterday"))
+	_, err = Binary("/app/static", src, nil)
+	assert.NotNil(t, err)
+}
+
+// parseBinaryFiles method returns the file data of generated code by path.
+func parseBinaryFiles(t *testing.T, code []byte) map[string][]byte {
+	f, err := parser.ParseFile(token.NewFileSet(), "vfs.go", code, 0)
+	assert.Nil(t, err)
+
+	files := make(map[string][]byte)
+	ast.Inspect(f, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) != 2 {
+			return true
+		}
+		if sel, ok := call.Fun.(*ast.SelectorExpr); !ok || sel.Sel.Name != "AddFile" {
+			return true
+		}
+		if _, ok := call.Args[1].(*ast.Ident); ok { // nil data of physical node
+			return true
+		}
+
+		var vpath string
+		var gzipped, raw, encrypted bool
+		for _, elt := range call.Args[0].(*ast.UnaryExpr).X.(*ast.CompositeLit).Elts {
+			switch kv := elt.(*ast.KeyValueExpr); kv.Key.(*ast.Ident).Name {
+			case "Path":
+				vpath, _ = strconv.Unquote(kv.Value.(*ast.BasicLit).Value)
+			case "Gzip":
+				gzipped = kv.Value.(*ast.Ident).Name == "true"
+			case "Raw":
+				raw = kv.Value.(*ast.Ident).Name == "true"
+			case "Encrypted":
+				encrypted = kv.Value.(*ast.Ident).Name == "true"
+			}
+		}
+		s, err := strconv.Unquote(call.Args[1].(*ast.CallExpr).Args[0].(*ast.BasicLit).Value)
+		assert.Nil(t, err)
+
+		data := []byte(s)
+		if !encrypted && (gzipped || (!raw && bytes.HasPrefix(data, gzipMemberHeader))) {
+			r, err := gzip.NewReader(bytes.NewReader(data))
+			assert.Nil(t, err)
+			data, err = ioutil.ReadAll(r)
+			assert.Nil(t, err)
+		}
+		files[vpath] = data
+		return true
+	})
+	return files
+}
+
+func sortedKeysOf(m map[string][]byte) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestVFSBinaryPackageOptions(t *testing.T) {
+	src := filepath.Join(testdataBaseDir(), "vfstest", "static")
+	code, err := BinaryWithOptions("/app/static", src, nil, BinaryOptions{
+		PackageName:    "assets",
+		SkipAahImports: true,
+	})
+	assert.Nil(t, err)
+	f, err := parser.ParseFile(token.NewFileSet(), "assets.go", code, parser.ImportsOnly)
+	assert.Nil(t, err)
+	assert.Equal(t, "assets", f.Name.Name)
+	for _, imp := range f.Imports {
+		assert.NotEqual(t, `"aahframework.org/aah.v0"`, imp.Path.Value)
+	}
+	assert.True(t, bytes.Contains(code, []byte("var AppVFS = new(vfs.VFS)")))
+	assert.True(t, bytes.Contains(code, []byte("fs := AppVFS\n")))
+	formatted, err := format.Source(code)
+	assert.Nil(t, err)
+	assert.Equal(t, string(formatted), string(code))
+
+	code, err = BinaryWithOptions("/app/static", src, nil, BinaryOptions{VFSVarName: "appFS"})
+	assert.Nil(t, err)
+	assert.True(t, bytes.Contains(code, []byte("package main\n")))
+	assert.True(t, bytes.Contains(code, []byte("fs := appFS\n")))
+	assert.False(t, bytes.Contains(code, []byte("var AppVFS")))
+	assert.False(t, bytes.Contains(code, []byte(`"aahframework.org/aah.v0"`)))
+
+	_, err = BinaryWithOptions("/app/static", src, nil, BinaryOptions{PackageName: "my-pkg"})
+	assert.NotNil(t, err)
+	_, err = BinaryWithOptions("/app/static", src, nil, BinaryOptions{VFSVarName: "1fs"})
+	assert.NotNil(t, err)
+}
+
+func TestVFSBinaryCompressionOptions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vfs-binary")
+	assert.FailNowOnError(t, err, "")
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	data := []byte(strings.Repeat("aah web framework ", 64))
+	for _, name := range []string{"logo.png", "style.css", "small.txt"} {
+		content := data
+		if name == "small.txt" {
+			content = data[:40]
+		}
+		assert.FailOnError(t, ioutil.WriteFile(filepath.Join(dir, name), content, 0644), "")
+	}
+
+	storedSizes := func(opts BinaryOptions) map[string]int64 {
+		buf := new(bytes.Buffer)
+		opts.Manifest = buf
+		_, err := BinaryWithOptions("/assets", dir, nil, opts)
+		assert.FailNowOnError(t, err, "")
+		var entries []ManifestEntry
+		assert.FailNowOnError(t, json.Unmarshal(buf.Bytes(), &entries), "")
+		sizes := make(map[string]int64)
+		for _, e := range entries {
+			sizes[path.Base(e.Path)] = e.StoredSize
+		}
+		return sizes
+	}
+
+	sizes := storedSizes(BinaryOptions{MinCompressSize: 64})
+	assert.Equal(t, int64(len(data)), sizes["logo.png"])
+	assert.Equal(t, int64(40), sizes["small.txt"])
+	assert.True(t, sizes["style.css"] < int64(len(data)))
+
+	sizes = storedSizes(BinaryOptions{SkipCompressExts: []string{}, CompressionLevel: gzip.BestSpeed})
+	assert.True(t, sizes["logo.png"] < int64(len(data)))
+
+	sizes = storedSizes(BinaryOptions{SkipCompressExts: []string{"CSS"}})
+	assert.Equal(t, int64(len(data)), sizes["style.css"])
+	assert.True(t, sizes["logo.png"] < int64(len(data)))
+
+	_, err = BinaryWithOptions("/assets", dir, nil, BinaryOptions{CompressionLevel: 42})
+	assert.NotNil(t, err)
+
+	// gzip file is stored as-is, not marked as gzip encoded
+	gz := new(bytes.Buffer)
+	gw := gzip.NewWriter(gz)
+	_, err = gw.Write(data)
+	assert.Nil(t, err)
+	assert.Nil(t, gw.Close())
+	assert.FailOnError(t, ioutil.WriteFile(filepath.Join(dir, "app.tar.gz"), gz.Bytes(), 0644), "")
+	manifest := new(bytes.Buffer)
+	code, err := BinaryWithOptions("/assets", dir, nil, BinaryOptions{Manifest: manifest})
+	assert.FailNowOnError(t, err, "")
+	var entries []ManifestEntry
+	assert.FailNowOnError(t, json.Unmarshal(manifest.Bytes(), &entries), "")
+	encodings := make(map[string]string)
+	for _, e := range entries {
+		encodings[path.Base(e.Path)] = e.Encoding
+	}
+	assert.Equal(t, "", encodings["app.tar.gz"])
+	assert.Equal(t, EncodingGzip, encodings["style.css"])
+	assert.Equal(t, 1, bytes.Count(code, []byte(", Gzip: true")))
+	assert.Equal(t, 1, bytes.Count(code, []byte(", Raw: true")))
+	assert.Equal(t, gz.Bytes(), parseBinaryFiles(t, code)["/assets/app.tar.gz"])
+}
+
+func TestVFSBinaryEncoders(t *testing.T) {
+	src := filepath.Join(testdataBaseDir(), "vfstest", "static")
+	code, err := BinaryWithOptions("/app/static", src, nil, BinaryOptions{
+		Encoders: map[string]func([]byte) ([]byte, error){
+			EncodingBrotli: func(data []byte) ([]byte, error) { return data[:len(data)/2], nil },
+			EncodingZstd:   func(data []byte) ([]byte, error) { return data, nil },
+		},
+	})
+	assert.Nil(t, err)
+	assert.True(t, bytes.Contains(code, []byte(`add(m.AddEncoded("/app/static/css/aah.css", "br", []byte(`)))
+	assert.False(t, bytes.Contains(code, []byte(`"zstd"`)))
+
+	_, err = BinaryWithOptions("/app/static", src, nil, BinaryOptions{
+		Encoders: map[string]func([]byte) ([]byte, error){
+			EncodingBrotli: func(data []byte) ([]byte, error) { return nil, fmt.Errorf("encoder failure") },
+		},
+	})
+	assert.NotNil(t, err)
+}
+
+func TestVFSBinarySplit(t *testing.T) {
+	src := filepath.Join(testdataBaseDir(), "vfstest")
+	opts := BinaryOptions{ModTime: time.Unix(1520850030, 0)}
+	single, err := BinaryWithOptions("/app", src, nil, opts)
+	assert.Nil(t, err)
+	countAdds := func(codes [][]byte) int {
+		count := 0
+		for _, code := range codes {
+			count += bytes.Count(code, []byte("add(m.AddFile("))
+		}
+		return count
+	}
+
+	for _, n := range []int{0, 1, 3, 100} {
+		codes, err := BinarySplit("/app", src, nil, opts, n)
+		assert.Nil(t, err)
+		assert.Equal(t, countAdds([][]byte{single}), countAdds(codes))
+		if n > 0 && n < 100 {
+			assert.Equal(t, n+1, len(codes))
+		}
+		assert.True(t, bytes.Contains(codes[0], []byte(fmt.Sprintf("for i := 0; i < %d; i++ {", len(codes)-1))))
+
+		for i, code := range codes {
+			formatted, err := format.Source(code)
+			assert.FailNowOnError(t, err, "")
+			assert.Equal(t, string(formatted), string(code))
+			if i > 0 {
+				assert.True(t, bytes.Contains(code, []byte(fmt.Sprintf("var _ = vfsAddShard(%d, ", i-1))))
+				assert.False(t, bytes.Contains(code, []byte("m.AddDir(")))
+			}
+		}
+	}
+
+	_, err = BinarySplit("/app", src, nil, opts, -1)
+	assert.NotNil(t, err)
+}
+
+func TestVFSBinaryDedupe(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vfs-binary-dedupe")
+	assert.FailNowOnError(t, err, "")
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	font := bytes.Repeat([]byte("font data "), 100)
+	for _, name := range []string{"a/font.woff", "b/font.woff", "b/font.txt"} {
+		assert.FailNowOnError(t, os.MkdirAll(filepath.Join(dir, path.Dir(name)), 0755), "")
+		assert.FailNowOnError(t, ioutil.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), font, 0644), "")
+	}
+
+	manifest := new(bytes.Buffer)
+	code, err := BinaryWithOptions("/fonts", dir, nil, BinaryOptions{Manifest: manifest})
+	assert.Nil(t, err)
+	formatted, err := format.Source(code)
+	assert.FailNowOnError(t, err, "")
+	assert.Equal(t, string(formatted), string(code))
+
+	// .txt is compressed, so its data is not shared with .woff
+	assert.Equal(t, []string{"/fonts/a/font.woff", "/fonts/b/font.txt"}, sortedKeysOf(parseBinaryFiles(t, code)))
+	assert.True(t, bytes.Contains(code, []byte(`Path: "/fonts/b/font.woff", Time: `)))
+	assert.True(t, bytes.Contains(code, []byte(`}, "/fonts/a/font.woff"))`)))
+	assert.Equal(t, 1, bytes.Count(code, []byte("add(m.AddLink(")))
+
+	var entries []ManifestEntry
+	assert.Nil(t, json.Unmarshal(manifest.Bytes(), &entries))
+	assert.Equal(t, 3, len(entries))
+	assert.Equal(t, entries[0].SHA256, entries[2].SHA256)
+	assert.Equal(t, entries[0].StoredSize, entries[2].StoredSize)
+}
+
+func TestVFSBinaryMaxEmbedSize(t *testing.T) {
+	src := filepath.Join(testdataBaseDir(), "vfstest", "static")
+	manifest := new(bytes.Buffer)
+	code, err := BinaryWithOptions("/app/static", src, nil, BinaryOptions{Manifest: manifest, MaxEmbedSize: 4096})
+	assert.Nil(t, err)
+	formatted, err := format.Source(code)
+	assert.FailNowOnError(t, err, "")
+	assert.Equal(t, string(formatted), string(code))
+
+	assert.Equal(t, []string{"/app/static/css/aah.css", "/app/static/js/aah.js", "/app/static/robots.txt"},
+		sortedKeysOf(parseBinaryFiles(t, code)))
+	assert.True(t, bytes.Contains(code, []byte(`Path: "/app/static/img/favicon.ico", Time: `)))
+	assert.Equal(t, 2, bytes.Count(code, []byte(`Physical: true}, nil))`)))
+
+	logo, err := ioutil.ReadFile(filepath.Join(src, "img", "aah-framework-logo.png"))
+	assert.FailNowOnError(t, err, "")
+	sum := sha256.Sum256(logo)
+	var entries []ManifestEntry
+	assert.Nil(t, json.Unmarshal(manifest.Bytes(), &entries))
+	assert.Equal(t, 5, len(entries))
+	assert.Equal(t, "/app/static/img/aah-framework-logo.png", entries[1].Path)
+	assert.Equal(t, int64(len(logo)), entries[1].Size)
+	assert.Equal(t, int64(0), entries[1].StoredSize)
+	assert.Equal(t, fmt.Sprintf("%x", sum), entries[1].SHA256)
+	assert.Equal(t, "image/png", entries[1].MimeType)
+}
+
+func TestVFSBinaryProgress(t *testing.T) {
+	src := filepath.Join(testdataBaseDir(), "vfstest", "static")
+	var reports []BinaryProgress
+	_, err := BinaryWithOptions("/app/static", src, []string{"js"}, BinaryOptions{
+		Includes:     []string{"*.css", "*.png", "*.txt"},
+		MaxEmbedSize: 4096,
+		Progress:     func(p BinaryProgress) { reports = append(reports, p) },
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(reports))
+
+	assert.Equal(t, BinaryProgress{Path: "/app/static/js", Skipped: true, Reason: SkipExcluded, TotalFiles: 3}, reports[0])
+	assert.Equal(t, "/app/static/img/favicon.ico", reports[1].Path)
+	assert.Equal(t, SkipNotIncluded, reports[1].Reason)
+	assert.True(t, reports[1].Size > 0)
+	assert.Equal(t, 1.0, reports[1].Ratio())
+
+	css := reports[2]
+	assert.Equal(t, "/app/static/css/aah.css", css.Path)
+	assert.False(t, css.Skipped)
+	assert.Equal(t, 1, css.Files)
+	assert.Equal(t, 3, css.TotalFiles)
+	assert.True(t, css.Ratio() < 1)
+	assert.True(t, reports[3].Physical)
+	assert.Equal(t, int64(0), reports[3].StoredSize)
+	assert.Equal(t, "/app/static/robots.txt", reports[4].Path)
+	assert.Equal(t, 3, reports[4].Files)
+}
+
+func TestVFSBinaryContext(t *testing.T) {
+	src := filepath.Join(testdataBaseDir(), "vfstest", "static")
+	code, err := BinaryContext(context.Background(), "/app/static", src, nil, BinaryOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(parseBinaryFiles(t, code)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = BinaryContext(ctx, "/app/static", src, nil, BinaryOptions{})
+	assert.Equal(t, context.Canceled, err)
+
+	// cancelled during encoding
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	files := 0
+	_, err = BinaryContext(ctx, "/app/static", src, nil, BinaryOptions{Progress: func(p BinaryProgress) {
+		if files++; files == 2 {
+			cancel()
+		}
+	}})
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 2, files)
+}
+
+func TestVFSBinaryDryRun(t *testing.T) {
+	src := filepath.Join(testdataBaseDir(), "vfstest", "static")
+	opts := BinaryOptions{MaxEmbedSize: 4096, SkipCompressExts: []string{".js"}}
+	entries, err := BinaryDryRun("/app/static", src, []string{"*.ico"}, opts)
+	assert.Nil(t, err)
+
+	manifest := new(bytes.Buffer)
+	opts.Manifest = manifest
+	_, err = BinaryWithOptions("/app/static", src, []string{"*.ico"}, opts)
+	assert.Nil(t, err)
+	var expected []ManifestEntry
+	assert.Nil(t, json.Unmarshal(manifest.Bytes(), &expected))
+	assert.Equal(t, len(expected), len(entries))
+	for i := range expected {
+		assert.Equal(t, expected[i].Path, entries[i].Path)
+		assert.Equal(t, expected[i].StoredSize, entries[i].StoredSize)
+	}
+	assert.Equal(t, "/app/static/js/aah.js", entries[2].Path)
+	assert.Equal(t, entries[2].Size, entries[2].StoredSize)
+
+	_, err = BinaryDryRun("/app", filepath.Join(testdataBaseDir(), "not-exists"), nil, BinaryOptions{})
+	assert.NotNil(t, err)
+}
+
+func TestVFSBinaryWorkers(t *testing.T) {
+	src := filepath.Join(testdataBaseDir(), "vfstest")
+	var codes [][]byte
+	var packs [][]byte
+	for _, workers := range []int{1, 3, 0} {
+		opts := BinaryOptions{Workers: workers, MaxEmbedSize: 4096}
+		code, err := BinaryWithOptions("/app", src, nil, opts)
+		assert.Nil(t, err)
+		codes = append(codes, code)
+
+		var buf bytes.Buffer
+		assert.Nil(t, Pack(&buf, "/app", src, nil, opts))
+		packs = append(packs, buf.Bytes())
+	}
+	assert.Equal(t, codes[0], codes[1])
+	assert.Equal(t, codes[0], codes[2])
+	assert.Equal(t, packs[0], packs[1])
+	assert.Equal(t, packs[0], packs[2])
+
+	// error of an entry stops the generation
+	_, err := BinaryWithOptions("/app", src, nil, BinaryOptions{
+		Workers:  2,
+		Encoders: map[string]func([]byte) ([]byte, error){"br": func([]byte) ([]byte, error) { return nil, os.ErrInvalid }},
+	})
+	assert.NotNil(t, err)
+}
+
+func TestVFSBinaryLiteral(t *testing.T) {
+	src := filepath.Join(testdataBaseDir(), "vfstest", "static")
+	expected, err := ioutil.ReadFile(filepath.Join(src, "css", "aah.css"))
+	assert.FailNowOnError(t, err, "")
+
+	for _, literal := range []BinaryLiteral{LiteralString, LiteralByteSlice, LiteralBase64} {
+		code, err := BinaryWithOptions("/app/static", src, nil, BinaryOptions{Literal: literal, MinCompressSize: 1 << 20})
+		assert.Nil(t, err)
+		formatted, err := format.Source(code)
+		assert.FailNowOnError(t, err, "")
+		assert.Equal(t, string(formatted), string(code))
+
+		// decode the file data literal of aah.css
+		f, err := parser.ParseFile(token.NewFileSet(), "vfs.go", code, 0)
+		assert.FailNowOnError(t, err, "")
+		var data []byte
+		ast.Inspect(f, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) != 2 || !strings.Contains(fmt.Sprint(call.Fun), "AddFile") {
+				return true
+			}
+			if !strings.Contains(string(code[call.Args[0].Pos()-1:call.Args[0].End()-1]), `"/app/static/css/aah.css"`) {
+				return true
+			}
+			data = decodeDataLiteral(t, call.Args[1])
+			return false
+		})
+		assert.Equal(t, string(expected), string(data))
+
+		assert.Equal(t, literal == LiteralBase64, bytes.Contains(code, []byte(`"encoding/base64"`)))
+	}
+}
+
+func decodeDataLiteral(t *testing.T, expr ast.Expr) []byte {
+	switch e := expr.(type) {
+	case *ast.CompositeLit:
+		var data []byte
+		for _, elt := range e.Elts {
+			b, err := strconv.ParseUint(elt.(*ast.BasicLit).Value, 0, 8)
+			assert.FailNowOnError(t, err, "")
+			data = append(data, byte(b))
+		}
+		return data
+	case *ast.CallExpr:
+		s, err := strconv.Unquote(e.Args[0].(*ast.BasicLit).Value)
+		assert.FailNowOnError(t, err, "")
+		if fn, ok := e.Fun.(*ast.Ident); ok && fn.Name == "vfsBase64" {
+			data, err := base64.StdEncoding.DecodeString(s)
+			assert.FailNowOnError(t, err, "")
+			return data
+		}
+		return []byte(s)
+	}
+	t.Fatalf("unexpected data literal %T", expr)
+	return nil
+}
+
+func TestVFSBinaryIncludes(t *testing.T) {
+	src := filepath.Join(testdataBaseDir(), "vfstest")
+	paths := func(opts BinaryOptions, excludes ...string) []string {
+		buf := new(bytes.Buffer)
+		opts.Manifest = buf
+		code, err := BinaryWithOptions("/app", src, excludes, opts)
+		assert.FailNowOnError(t, err, "")
+		var entries []ManifestEntry
+		assert.FailNowOnError(t, json.Unmarshal(buf.Bytes(), &entries), "")
+		var list []string
+		for _, e := range entries {
+			list = append(list, e.Path)
+		}
+		assert.Equal(t, len(list), bytes.Count(code, []byte("add(m.AddFile(")))
+		return list
+	}
+
+	list := paths(BinaryOptions{Includes: []string{"**/*.html"}})
+	assert.Equal(t, 8, len(list))
+	for _, p := range list {
+		assert.Equal(t, ".html", path.Ext(p))
+	}
+
+	opts := BinaryOptions{Includes: []string{"static/**/*.{css,js}", "robots.txt"}}
+	assert.Equal(t, []string{"/app/static/css/aah.css", "/app/static/js/aah.js", "/app/static/robots.txt"},
+		paths(opts))
+	code, err := BinaryWithOptions("/app", src, nil, opts)
+	assert.Nil(t, err)
+	assert.True(t, bytes.Contains(code, []byte(`Path: "/app/static/css"`)))
+	assert.False(t, bytes.Contains(code, []byte(`Path: "/app/views"`)))
+	assert.False(t, bytes.Contains(code, []byte(`Path: "/app/static/img"`)))
+
+	assert.Equal(t, []string{"/app/static/robots.txt"}, paths(opts, "css", "js"))
+
+	_, err = BinaryWithOptions("/app", src, nil, BinaryOptions{Includes: []string{"{*.css"}})
+	assert.NotNil(t, err)
+	_, err = BinaryWithOptions("/app", src, nil, BinaryOptions{Includes: []string{"[*.css"}})
+	assert.NotNil(t, err)
+}
+
+func TestVFSBinaryChecksum(t *testing.T) {
+	src := filepath.Join(testdataBaseDir(), "vfstest", "static")
+	code, err := Binary("/app/static", src, nil)
+	assert.Nil(t, err)
+
+	data, err := ioutil.ReadFile(filepath.Join(src, "robots.txt"))
+	assert.FailNowOnError(t, err, "")
+	sum := sha256.Sum256(data)
+	assert.True(t, bytes.Contains(code, []byte(`Path: "/app/static/robots.txt", Time: time.Unix(`)))
+	assert.True(t, bytes.Contains(code, []byte(fmt.Sprintf(`SHA256: "%x"}`, sum))))
+	assert.Equal(t, bytes.Count(code, []byte("add(m.AddFile(")), bytes.Count(code, []byte("SHA256: \"")))
+}
+
+func TestVFSBinaryFollowSymlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlink creation requires privilege on windows")
+	}
+	dir, err := ioutil.TempDir("", "vfs-binary-symlink")
+	assert.FailNowOnError(t, err, "")
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	// assets/app.js
+	// assets/node_modules/lib -> ../../pkg/lib
+	// assets/node_modules/loop -> ..
+	// assets/main.js -> app.js
+	// pkg/lib/index.js
+	assets := filepath.Join(dir, "assets")
+	for _, d := range []string{filepath.Join(assets, "node_modules"), filepath.Join(dir, "pkg", "lib")} {
+		assert.FailNowOnError(t, os.MkdirAll(d, 0755), "")
+	}
+	assert.FailNowOnError(t, ioutil.WriteFile(filepath.Join(assets, "app.js"), []byte("app();"), 0644), "")
+	assert.FailNowOnError(t, ioutil.WriteFile(filepath.Join(dir, "pkg", "lib", "index.js"), []byte("lib();"), 0644), "")
+	assert.FailNowOnError(t, os.Symlink(filepath.Join("..", "..", "pkg", "lib"), filepath.Join(assets, "node_modules", "lib")), "")
+	assert.FailNowOnError(t, os.Symlink("..", filepath.Join(assets, "node_modules", "loop")), "")
+	assert.FailNowOnError(t, os.Symlink("app.js", filepath.Join(assets, "main.js")), "")
+
+	paths := func(opts BinaryOptions) []string {
+		buf := new(bytes.Buffer)
+		opts.Manifest = buf
+		_, err := BinaryWithOptions("/assets", assets, nil, opts)
+		assert.FailNowOnError(t, err, "")
+		var entries []ManifestEntry
+		assert.FailNowOnError(t, json.Unmarshal(buf.Bytes(), &entries), "")
+		var list []string
+		for _, e := range entries {
+			list = append(list, e.Path)
+		}
+		return list
+	}
+
+	assert.Equal(t, []string{"/assets/app.js"}, paths(BinaryOptions{}))
+	assert.Equal(t, []string{"/assets/app.js", "/assets/main.js", "/assets/node_modules/lib/index.js"},
+		paths(BinaryOptions{FollowSymlinks: true}))
+
+	code, err := BinaryWithOptions("/assets", assets, nil, BinaryOptions{FollowSymlinks: true})
+	assert.Nil(t, err)
+	assert.True(t, bytes.Contains(code, []byte(`Dir: true, Path: "/assets/node_modules/lib"`)))
+	assert.False(t, bytes.Contains(code, []byte(`/assets/node_modules/loop`)))
+
+	// dangling link
+	assert.FailNowOnError(t, os.Symlink("not-exists.js", filepath.Join(assets, "dangling.js")), "")
+	_, err = BinaryWithOptions("/assets", assets, nil, BinaryOptions{FollowSymlinks: true})
+	assert.NotNil(t, err)
+	assert.Equal(t, []string{"/assets/app.js"}, paths(BinaryOptions{}))
+}
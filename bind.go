@@ -0,0 +1,169 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"os"
+	"path"
+	"strings"
+)
+
+// Bind method exposes the existing virtual path source also as target, for
+// e.g.: `/app/static` as `/assets`, like bind mount. No node data is copied,
+// lookup of target and its descendants resolves through the source, so the
+// later changes of source are visible on target. Stat and Walk report the
+// target paths.
+//
+// Target must not exist and must not overlap with source, bound target can
+// not be used as source. Alias is not listed on the parent directory of
+// target and glob pattern must have the target as literal prefix, for e.g.:
+// `/assets/css/*.css`.
+func (v *VFS) Bind(target, source string) error {
+	target, err := cleanPath("bind", target)
+	if err != nil {
+		return err
+	}
+	if source, err = cleanPath("bind", source); err != nil {
+		return err
+	}
+
+	lerr := func(err error) error {
+		return &os.LinkError{Op: "bind", Old: source, New: target, Err: err}
+	}
+	if target == "/" || isPathWithin(target, source) || isPathWithin(source, target) {
+		return lerr(os.ErrInvalid)
+	}
+	if _, found := v.bindOf(source); found {
+		return lerr(os.ErrInvalid)
+	}
+	if _, err = v.Lstat(source); err != nil {
+		return lerr(os.ErrNotExist)
+	}
+	if v.IsExists(target) {
+		return lerr(os.ErrExist)
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.binds == nil {
+		v.binds = make(map[string]string)
+	}
+	v.binds[target] = source
+	return nil
+}
+
+// Unbind method removes the alias target created by `VFS.Bind`, source is
+// not affected.
+func (v *VFS) Unbind(target string) error {
+	target, err := cleanPath("unbind", target)
+	if err != nil {
+		return err
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if _, found := v.binds[target]; !found {
+		return &os.PathError{Op: "unbind", Path: target, Err: os.ErrNotExist}
+	}
+	delete(v.binds, target)
+	return nil
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Bind unexported types and methods
+//______________________________________________________________________________
+
+type bind struct {
+	target string
+	source string
+}
+
+// bindOf method returns the bind of longest target matching given clean
+// name.
+func (v *VFS) bindOf(name string) (bind, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	var b bind
+	for target, source := range v.binds {
+		if isPathWithin(name, target) && len(target) > len(b.target) {
+			b = bind{target: target, source: source}
+		}
+	}
+	return b, b.target != ""
+}
+
+// unbound method returns the source path of given clean name, name as-is if
+// it is not under any target.
+func (v *VFS) unbound(name string) string {
+	if b, found := v.bindOf(name); found {
+		return b.toSource(name)
+	}
+	return name
+}
+
+// boundInfo method returns the file info with path of given name, if name
+// is under a target.
+func (v *VFS) boundInfo(name string, fi os.FileInfo) os.FileInfo {
+	if fi == nil {
+		return nil
+	}
+	if name, err := cleanPath("stat", name); err == nil {
+		if _, found := v.bindOf(name); found {
+			return renameInfo(name, fi)
+		}
+	}
+	return fi
+}
+
+func (b bind) toSource(name string) string {
+	return path.Join(b.source, strings.TrimPrefix(name, b.target))
+}
+
+func (b bind) toTarget(name string) string {
+	return path.Join(b.target, strings.TrimPrefix(name, b.source))
+}
+
+// renameInfo method returns the file info of given path, virtual info keeps
+// its checksum and symbolic link. Physical info is kept as-is if its name
+// is same.
+func renameInfo(name string, fi os.FileInfo) os.FileInfo {
+	var ni *NodeInfo
+	switch t := fi.(type) {
+	case *file:
+		ni = t.NodeInfo
+	case *node:
+		ni = t.NodeInfo
+	case *NodeInfo:
+		ni = t
+	}
+	if ni != nil {
+		c := *ni
+		c.Path = name
+		return &c
+	}
+	if fi.Name() == path.Base(name) {
+		return fi
+	}
+	return newNodeInfo(name, fi)
+}
+
+// boundTree presents the source tree on target paths, it is used by
+// `VFS.Walk`.
+type boundTree struct {
+	t walkFileSystem
+	b bind
+}
+
+func (t boundTree) Open(name string) (File, error) {
+	return t.t.Open(t.b.toSource(name))
+}
+
+func (t boundTree) Lstat(name string) (os.FileInfo, error) {
+	fi, err := t.t.Lstat(t.b.toSource(name))
+	if err != nil {
+		return nil, err
+	}
+	return renameInfo(name, fi), nil
+}
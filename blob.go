@@ -0,0 +1,438 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sync"
+	"time"
+)
+
+// Asset blob errors
+var (
+	ErrBlobNotFound = errors.New("vfs: asset blob not found")
+	ErrBlobCorrupt  = errors.New("vfs: asset blob is corrupt")
+)
+
+// blobMagic is the last 8 bytes of asset blob.
+var blobMagic = []byte("AAHVFSB1")
+
+// blobTrailerSize is index offset (8), index length (8) and magic (8).
+const blobTrailerSize = 24
+
+// WriteBlob method writes the asset blob of given virtual roots from fs into
+// w. Blob carries the file data as-is (gzip data stays compressed) followed
+// by index and trailer, so it can be appended to a file. On load each root
+// becomes a mount, see `VFS.MountBlob`.
+//
+// Identical file contents are stored once, such files share the data on load
+// like hard links, see `Mount.Link`.
+func WriteBlob(w io.Writer, fs FileSystem, roots ...string) error {
+	bw := &blobWriter{w: w, offsets: make(map[[sha256.Size]byte]int64)}
+	for _, root := range roots {
+		root = path.Clean("/" + root)
+		bw.index.Mounts = append(bw.index.Mounts, root)
+		info, err := fs.Lstat(root)
+		if err != nil {
+			return err
+		}
+		if err = walk(fs, root, info, bw.walkFn(fs)); err != nil {
+			return err
+		}
+	}
+	return bw.close()
+}
+
+// AppendBlob method appends the asset blob of given virtual roots from fs to
+// the file, for e.g. built executable. It makes the self-contained single
+// binary without generated Go source, see `VFS.MountExecutable`.
+func AppendBlob(filename string, fs FileSystem, roots ...string) error {
+	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return err
+	}
+
+	if err = WriteBlob(f, fs, roots...); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// MountBlob method mounts the asset blob located at the end of r, as purely
+// virtual mounts. File data is read into memory. It returns
+// `ErrBlobNotFound` if r does not end with blob.
+func (v *VFS) MountBlob(r io.ReaderAt, size int64) error {
+	index, payload, err := readBlob(r, size)
+	if err != nil {
+		return err
+	}
+	return v.mountBlob(index, payload, nil)
+}
+
+// MountBlobFile method mounts the asset blob located at the end of file.
+// On Unix like systems file is memory-mapped and file data is served
+// directly from the mapping, so the assets are not part of Go heap and GC.
+// Mapping is released when all the blob mounts are closed, see
+// `Mount.Close`, and the files opened prior are closed. Opening the file of
+// closed blob mount returns `os.ErrClosed`.
+func (v *VFS) MountBlobFile(filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	data, unmap, err := mmapFile(f, fi.Size())
+	if err != nil {
+		return err
+	}
+
+	index, payload, err := parseBlob(data)
+	if err == nil {
+		err = v.mountBlob(index, payload, &blobMapping{unmap: unmap})
+	}
+	if err != nil {
+		_ = unmap()
+	}
+	return err
+}
+
+// MountExecutable method mounts the asset blob appended to running
+// executable, see `vfs.AppendBlob` and `VFS.MountBlobFile`.
+func (v *VFS) MountExecutable() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	return v.MountBlobFile(exe)
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Blob unexported types and methods
+//______________________________________________________________________________
+
+type blobIndex struct {
+	Mounts  []string    `json:"mounts"`
+	Entries []blobEntry `json:"entries"`
+
+	// Signature is the hex asset manifest signature of pack, see `vfs.Pack`
+	Signature string `json:"signature,omitempty"`
+}
+
+type blobEntry struct {
+	Path      string `json:"p"`
+	Dir       bool   `json:"d,omitempty"`
+	Size      int64  `json:"s,omitempty"`
+	Time      int64  `json:"t"`
+	Offset    int64  `json:"o,omitempty"`
+	Length    int64  `json:"l,omitempty"`
+	SHA256    string `json:"h,omitempty"`
+	Encrypted bool   `json:"e,omitempty"`
+	Gzip      bool   `json:"z,omitempty"`
+}
+
+type blobWriter struct {
+	w       io.Writer
+	offset  int64
+	index   blobIndex
+	offsets map[[sha256.Size]byte]int64
+}
+
+func (bw *blobWriter) walkFn(fs FileSystem) func(string, os.FileInfo, error) error {
+	return func(fpath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		e := blobEntry{Path: fpath, Dir: info.IsDir(), Time: info.ModTime().UnixNano()}
+		if e.Dir {
+			return bw.add(e, nil)
+		}
+
+		data, gzipped, err := readRawBytes(fs, fpath)
+		if err != nil {
+			return err
+		}
+		e.Gzip = gzipped
+		e.Size = info.Size()
+		if c, ok := info.(Checksummer); ok {
+			e.SHA256, _ = c.Checksum()
+		}
+		return bw.add(e, data)
+	}
+}
+
+// add method writes the file data unless identical data is written already
+// and adds the entry into index.
+func (bw *blobWriter) add(e blobEntry, data []byte) error {
+	if !e.Dir {
+		e.Length = int64(len(data))
+		sum := sha256.Sum256(data)
+		if offset, found := bw.offsets[sum]; found {
+			e.Offset = offset
+		} else {
+			if _, err := bw.w.Write(data); err != nil {
+				return err
+			}
+			e.Offset = bw.offset
+			bw.offsets[sum] = e.Offset
+			bw.offset += e.Length
+		}
+	}
+	bw.index.Entries = append(bw.index.Entries, e)
+	return nil
+}
+
+func (bw *blobWriter) close() error {
+	index, err := json.Marshal(bw.index)
+	if err != nil {
+		return err
+	}
+
+	trailer := make([]byte, blobTrailerSize)
+	binary.BigEndian.PutUint64(trailer[0:8], uint64(bw.offset))
+	binary.BigEndian.PutUint64(trailer[8:16], uint64(len(index)))
+	copy(trailer[16:], blobMagic)
+
+	if _, err = bw.w.Write(index); err != nil {
+		return err
+	}
+	_, err = bw.w.Write(trailer)
+	return err
+}
+
+// readRawBytes method returns the gzip data as-is with true, otherwise file
+// content.
+func readRawBytes(fs FileSystem, name string) ([]byte, bool, error) {
+	f, err := fs.Open(name)
+	if err != nil {
+		return nil, false, err
+	}
+	defer func() { _ = f.Close() }()
+
+	if gz, ok := f.(Gziper); ok && gz.IsGzip() {
+		return gz.RawBytes(), true, nil
+	}
+	data, err := ioutil.ReadAll(f)
+	return data, false, err
+}
+
+// mountBlob method creates the mounts of blob index with data from payload
+// and attaches them. Mapping b of payload is shared by the mounts, if not
+// nil. Caller unmaps it on error.
+func (v *VFS) mountBlob(index *blobIndex, payload []byte, b *blobMapping) error {
+	mounts, err := newBlobMounts(index, payload, v.mountOpts...)
+	if err != nil {
+		return err
+	}
+	if b != nil && len(mounts) == 0 {
+		return b.unmap() // nothing refers the payload
+	}
+
+	for i, m := range mounts {
+		if err := v.attach(m); err != nil {
+			for _, am := range mounts[:i] {
+				_ = v.detach(am.Vroot)
+			}
+			return err
+		}
+	}
+
+	if b != nil {
+		b.refs = len(mounts)
+		for _, m := range mounts {
+			ref := &mappingRef{b: b}
+			m.mapping = ref
+			m.AddCloser(ref)
+		}
+	}
+	return nil
+}
+
+// newBlobMounts method creates the mounts of blob index with data from
+// payload.
+func newBlobMounts(index *blobIndex, payload []byte, opts ...MountOption) ([]*Mount, error) {
+	var mounts []*Mount
+	for _, root := range index.Mounts {
+		m, err := newMount(root, "", opts...)
+		if err != nil {
+			return nil, err
+		}
+		mounts = append(mounts, m)
+	}
+
+	for _, e := range index.Entries {
+		var m *Mount
+		for _, mt := range mounts {
+			if isPathWithin(e.Path, mt.Vroot) && (m == nil || len(mt.Vroot) > len(m.Vroot)) {
+				m = mt
+			}
+		}
+		if m == nil || e.Path == m.Vroot {
+			continue
+		}
+
+		var err error
+		fi := &NodeInfo{Dir: e.Dir, Path: e.Path, DataSize: e.Size, Time: time.Unix(0, e.Time).UTC(), SHA256: e.SHA256, Encrypted: e.Encrypted, Gzip: e.Gzip, Raw: !e.Gzip}
+		if e.Dir {
+			err = m.AddDir(fi)
+		} else {
+			// payload is single allocation or mapping already
+			err = m.addNode(fi, payload[e.Offset:e.Offset+e.Length:e.Offset+e.Length])
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if index.Signature != "" && len(mounts) == 1 {
+		if err := mounts[0].SetSignature(index.Signature); err != nil {
+			return nil, err
+		}
+	}
+	for _, m := range mounts {
+		m.sealReadOnly()
+	}
+	return mounts, nil
+}
+
+// readBlob method reads the index and payload of asset blob located at the
+// end of r.
+func readBlob(r io.ReaderAt, size int64) (*blobIndex, []byte, error) {
+	if size < blobTrailerSize {
+		return nil, nil, ErrBlobNotFound
+	}
+
+	trailer := make([]byte, blobTrailerSize)
+	if _, err := r.ReadAt(trailer, size-blobTrailerSize); err != nil {
+		return nil, nil, err
+	}
+	start, payloadLen, indexLen, err := blobLayout(trailer, size)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	buf := make([]byte, payloadLen+indexLen)
+	if _, err := r.ReadAt(buf, start); err != nil {
+		return nil, nil, err
+	}
+	return parseIndex(buf[payloadLen:], buf[:payloadLen:payloadLen])
+}
+
+// parseBlob method parses the asset blob located at the end of data, payload
+// refers to data.
+func parseBlob(data []byte) (*blobIndex, []byte, error) {
+	size := int64(len(data))
+	if size < blobTrailerSize {
+		return nil, nil, ErrBlobNotFound
+	}
+
+	start, payloadLen, indexLen, err := blobLayout(data[size-blobTrailerSize:], size)
+	if err != nil {
+		return nil, nil, err
+	}
+	end := start + payloadLen
+	return parseIndex(data[end:end+indexLen], data[start:end:end])
+}
+
+// blobLayout method validates the trailer and returns the blob start offset,
+// payload and index length.
+func blobLayout(trailer []byte, size int64) (start, payloadLen, indexLen int64, err error) {
+	if !bytes.Equal(trailer[16:], blobMagic) {
+		return 0, 0, 0, ErrBlobNotFound
+	}
+
+	// lengths are checked separately, so their sum does not overflow
+	avail := size - blobTrailerSize
+	payloadLen = int64(binary.BigEndian.Uint64(trailer[0:8]))
+	indexLen = int64(binary.BigEndian.Uint64(trailer[8:16]))
+	if payloadLen < 0 || payloadLen > avail || indexLen < 0 || indexLen > avail-payloadLen {
+		return 0, 0, 0, ErrBlobCorrupt
+	}
+	return avail - indexLen - payloadLen, payloadLen, indexLen, nil
+}
+
+func parseIndex(data, payload []byte) (*blobIndex, []byte, error) {
+	index := &blobIndex{}
+	if err := json.Unmarshal(data, index); err != nil {
+		return nil, nil, err
+	}
+	for _, e := range index.Entries {
+		if e.Offset < 0 || e.Length < 0 || e.Offset > int64(len(payload)) ||
+			e.Length > int64(len(payload))-e.Offset {
+			return nil, nil, &os.PathError{Op: "readblob", Path: e.Path, Err: ErrBlobCorrupt}
+		}
+	}
+	return index, payload, nil
+}
+
+// blobMapping is the memory mapping of blob shared by the blob mounts, it
+// is unmapped when all the mounts are closed and none of the opened files
+// reads it.
+type blobMapping struct {
+	mu      sync.Mutex
+	refs    int // mounts not closed
+	readers int // opened files
+	unmap   func() error
+}
+
+// release method unmaps the mapping if it is not referred, caller holds the
+// lock.
+func (b *blobMapping) release() error {
+	if b.refs > 0 || b.readers > 0 || b.unmap == nil {
+		return nil
+	}
+	unmap := b.unmap
+	b.unmap = nil
+	return unmap()
+}
+
+// mappingRef is the reference of blob mount to its mapping, it is closed
+// by `Mount.Close`.
+type mappingRef struct {
+	b      *blobMapping
+	closed bool // guarded by mapping lock
+}
+
+// acquire method holds the mapping for the opened file until the returned
+// func is called, false if the mount is closed.
+func (r *mappingRef) acquire() (func(), bool) {
+	r.b.mu.Lock()
+	defer r.b.mu.Unlock()
+	if r.closed {
+		return nil, false
+	}
+	r.b.readers++
+	return func() {
+		r.b.mu.Lock()
+		defer r.b.mu.Unlock()
+		r.b.readers--
+		_ = r.b.release()
+	}, true
+}
+
+func (r *mappingRef) Close() error {
+	r.b.mu.Lock()
+	defer r.b.mu.Unlock()
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	r.b.refs--
+	return r.b.release()
+}
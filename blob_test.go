@@ -0,0 +1,193 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"aahframework.org/test.v0/assert"
+)
+
+func TestVFSBlob(t *testing.T) {
+	fs := createVFS(t)
+	fs.SetEmbeddedMode()
+
+	exe, err := ioutil.TempFile("", "vfs-blob")
+	assert.Nil(t, err)
+	defer func() { _ = os.Remove(exe.Name()) }()
+	_, err = exe.Write([]byte("executable content"))
+	assert.Nil(t, err)
+	assert.Nil(t, exe.Close())
+
+	assert.Nil(t, AppendBlob(exe.Name(), fs, "/app/config", "/app/static"))
+	data, err := ioutil.ReadFile(exe.Name())
+	assert.Nil(t, err)
+	assert.True(t, bytes.HasPrefix(data, []byte("executable content")))
+
+	bfs := new(VFS)
+	assert.Nil(t, bfs.MountBlob(bytes.NewReader(data), int64(len(data))))
+
+	files, err := bfs.Files("/app")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"/app/config/aah.conf",
+		"/app/config/env/dev.conf",
+		"/app/config/env/prod.conf",
+		"/app/config/routes.conf",
+		"/app/config/security.conf",
+		"/app/static/css/aah.css",
+		"/app/static/img/aah-framework-logo.png",
+		"/app/static/img/favicon.ico",
+		"/app/static/js/aah.js",
+		"/app/static/robots.txt",
+	}, files)
+
+	// gzip data stays compressed
+	f, err := bfs.Open("/app/config/security.conf")
+	assert.Nil(t, err)
+	assert.True(t, f.(Gziper).IsGzip())
+	assert.Nil(t, f.Close())
+	for _, name := range []string{"config/security.conf", "static/img/favicon.ico"} {
+		expected, err := ioutil.ReadFile(filepath.Join(testdataBaseDir(), "vfstest", name))
+		assert.Nil(t, err)
+		got, err := bfs.ReadFile("/app/" + name)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, got)
+	}
+
+	efi, err := fs.Stat("/app/config/env/dev.conf")
+	assert.Nil(t, err)
+	bfi, err := bfs.Stat("/app/config/env/dev.conf")
+	assert.Nil(t, err)
+	assert.True(t, efi.ModTime().Equal(bfi.ModTime()))
+	assert.Equal(t, efi.Size(), bfi.Size())
+
+	err = bfs.MountBlob(bytes.NewReader([]byte("no blob")), 7)
+	assert.Equal(t, ErrBlobNotFound, err)
+	err = bfs.MountBlob(bytes.NewReader(data[20:]), int64(len(data)-20))
+	assert.Equal(t, ErrBlobCorrupt, err)
+	assert.Equal(t, ErrBlobNotFound, bfs.MountExecutable())
+}
+
+func TestVFSBlobCorrupt(t *testing.T) {
+	blob := func(payload, index []byte, payloadLen, indexLen uint64) []byte {
+		trailer := make([]byte, blobTrailerSize)
+		binary.BigEndian.PutUint64(trailer[0:8], payloadLen)
+		binary.BigEndian.PutUint64(trailer[8:16], indexLen)
+		copy(trailer[16:], blobMagic)
+		return append(append(append([]byte{}, payload...), index...), trailer...)
+	}
+
+	// lengths overflow on sum
+	data := blob(nil, nil, 1<<63-1, 1<<63-1)
+	fs := new(VFS)
+	assert.Equal(t, ErrBlobCorrupt, fs.MountBlob(bytes.NewReader(data), int64(len(data))))
+	_, _, err := parseBlob(data)
+	assert.Equal(t, ErrBlobCorrupt, err)
+	data = blob(nil, nil, 1, 0)
+	assert.Equal(t, ErrBlobCorrupt, fs.MountBlob(bytes.NewReader(data), int64(len(data))))
+
+	// entry offset and length overflow on sum
+	index := []byte(`{"mounts":["/app"],"entries":[{"p":"/app/a.txt","t":0,"o":2,"l":9223372036854775807}]}`)
+	data = blob([]byte("abc"), index, 3, uint64(len(index)))
+	err = fs.MountBlob(bytes.NewReader(data), int64(len(data)))
+	assert.Equal(t, ErrBlobCorrupt, err.(*os.PathError).Err)
+
+	index = []byte(`{"mounts":["/app"],"entries":[{"p":"/app/a.txt","t":0,"o":1,"l":2}]}`)
+	data = blob([]byte("abc"), index, 3, uint64(len(index)))
+	assert.Nil(t, fs.MountBlob(bytes.NewReader(data), int64(len(data))))
+	got, err := fs.ReadFile("/app/a.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, "bc", string(got))
+}
+
+func TestVFSBlobFile(t *testing.T) {
+	fs := createVFS(t)
+	fs.SetEmbeddedMode()
+
+	exe, err := ioutil.TempFile("", "vfs-blob")
+	assert.Nil(t, err)
+	defer func() { _ = os.Remove(exe.Name()) }()
+	_, err = exe.Write([]byte("executable content"))
+	assert.Nil(t, err)
+	assert.Nil(t, exe.Close())
+	assert.Nil(t, AppendBlob(exe.Name(), fs, "/app/config", "/app/static"))
+
+	bfs := new(VFS)
+	assert.Nil(t, bfs.MountBlobFile(exe.Name()))
+	for _, name := range []string{"config/env/dev.conf", "static/img/favicon.ico"} {
+		expected, err := ioutil.ReadFile(filepath.Join(testdataBaseDir(), "vfstest", name))
+		assert.Nil(t, err)
+		got, err := bfs.ReadFile("/app/" + name)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, got)
+	}
+
+	// mapping is shared by the blob mounts, opened file holds it
+	m, err := bfs.FindMount("/app/config")
+	assert.Nil(t, err)
+	b := m.mapping.b
+	assert.Equal(t, 2, b.refs)
+	f, err := bfs.Open("/app/config/env/dev.conf")
+	assert.Nil(t, err)
+	assert.Nil(t, bfs.Close())
+	assert.Equal(t, 0, b.refs)
+	assert.NotNil(t, b.unmap)
+	got, err := ioutil.ReadAll(f)
+	assert.Nil(t, err)
+	assert.True(t, len(got) > 0)
+	assert.Nil(t, f.Close())
+	assert.Nil(t, b.unmap)
+	assert.Nil(t, bfs.Close())
+
+	// reads after close
+	_, err = m.ReadFile("/app/config/env/dev.conf")
+	assert.Equal(t, os.ErrClosed, err.(*os.PathError).Err)
+	_, err = m.Open("/app/config/env/dev.conf")
+	assert.Equal(t, os.ErrClosed, err.(*os.PathError).Err)
+	assert.Equal(t, os.ErrClosed, m.Warm("/app/config/env/dev.conf").(*os.PathError).Err)
+	_, err = m.Stat("/app/config/env/dev.conf")
+	assert.Nil(t, err)
+
+	// blob without mounts is unmapped
+	unmapped := false
+	assert.Nil(t, bfs.mountBlob(&blobIndex{}, nil, &blobMapping{unmap: func() error { unmapped = true; return nil }}))
+	assert.True(t, unmapped)
+
+	err = bfs.MountBlobFile(filepath.Join(testdataBaseDir(), "vfstest", "config", "aah.conf"))
+	assert.Equal(t, ErrBlobNotFound, err)
+}
+
+func TestVFSBlobDedup(t *testing.T) {
+	m, err := NewMount("/app", "")
+	assert.Nil(t, err)
+	font := bytes.Repeat([]byte("font data "), 100)
+	assert.Nil(t, m.AddDir(&NodeInfo{Path: "/app/a", Dir: true}))
+	assert.Nil(t, m.AddFile(&NodeInfo{Path: "/app/a/font.woff", DataSize: 1000}, font))
+	assert.Nil(t, m.AddFile(&NodeInfo{Path: "/app/b.woff", DataSize: 1000}, font))
+
+	var buf bytes.Buffer
+	assert.Nil(t, WriteBlob(&buf, m, "/app"))
+	assert.True(t, buf.Len() < 2*len(font))
+
+	bfs := new(VFS)
+	assert.Nil(t, bfs.MountBlob(bytes.NewReader(buf.Bytes()), int64(buf.Len())))
+	for _, name := range []string{"/app/a/font.woff", "/app/b.woff"} {
+		got, err := bfs.ReadFile(name)
+		assert.Nil(t, err)
+		assert.Equal(t, font, got)
+	}
+
+	bm, err := bfs.FindMount("/app")
+	assert.Nil(t, err)
+	a, _ := bm.tree.lookup("a/font.woff")
+	b, _ := bm.tree.lookup("b.woff")
+	assert.True(t, &a.data[0] == &b.data[0])
+}
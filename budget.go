@@ -0,0 +1,199 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+)
+
+// MemoryStats struct represents the gauges of VFS memory budget.
+type MemoryStats struct {
+	// Limit is the memory budget in bytes, 0 means unlimited.
+	Limit int64
+
+	// Used is the bytes held by the caches.
+	Used int64
+
+	// Entries is no. of cached entries.
+	Entries int
+
+	// Evictions is no. of entries evicted so far.
+	Evictions uint64
+}
+
+// String method Stringer interface.
+func (s MemoryStats) String() string {
+	return fmt.Sprintf("memory(limit=%d used=%d entries=%d evictions=%d)",
+		s.Limit, s.Used, s.Entries, s.Evictions)
+}
+
+// SetMemoryLimit method sets the memory budget in bytes shared by the caches
+// of all the mounts, i.e. decompression cache (`Mount.Warm`), compressed
+// physical file cache (`vfs.CompressCache`) and lazy tree (`vfs.Lazy`).
+// Least recently used entries are evicted when it exceeds the budget. Mounts
+// added later shares the same budget. Value 0 means unlimited, caches are
+// still accounted in `VFS.MemoryStats`.
+func (v *VFS) SetMemoryLimit(limit int64) {
+	v.mu.Lock()
+	if v.budget == nil {
+		v.budget = newMemoryBudget()
+	}
+	b := v.budget
+	mounts := make([]*Mount, 0, len(v.mounts))
+	for _, m := range v.mounts {
+		mounts = append(mounts, m)
+	}
+	v.mu.Unlock()
+
+	b.setLimit(limit)
+	for _, m := range mounts {
+		m.setBudget(b)
+	}
+}
+
+// MemoryStats method returns the gauges of memory budget, zero value if
+// `VFS.SetMemoryLimit` is not called.
+func (v *VFS) MemoryStats() MemoryStats {
+	v.mu.RLock()
+	b := v.budget
+	v.mu.RUnlock()
+	if b == nil {
+		return MemoryStats{}
+	}
+	return b.stats()
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Memory budget unexported types and methods
+//______________________________________________________________________________
+
+// budgetOwner is implemented by the caches accounted in memory budget. Evict
+// removes the entry from cache, it is called without holding budget lock.
+type budgetOwner interface {
+	evict(key string)
+}
+
+type budgetKey struct {
+	owner budgetOwner
+	key   string
+}
+
+type budgetEntry struct {
+	budgetKey
+	size int64
+}
+
+// memoryBudget tracks the cache entries of all the owners in LRU order.
+type memoryBudget struct {
+	mu        sync.Mutex
+	limit     int64
+	used      int64
+	evictions uint64
+	lru       *list.List
+	entries   map[budgetKey]*list.Element
+}
+
+func newMemoryBudget() *memoryBudget {
+	return &memoryBudget{lru: list.New(), entries: make(map[budgetKey]*list.Element)}
+}
+
+func (b *memoryBudget) setLimit(limit int64) {
+	b.mu.Lock()
+	b.limit = limit
+	victims := b.shrink()
+	b.mu.Unlock()
+	evictAll(victims)
+}
+
+// add method accounts the entry as most recently used and evicts the least
+// recently used entries beyond the limit.
+func (b *memoryBudget) add(owner budgetOwner, key string, size int64) {
+	k := budgetKey{owner: owner, key: key}
+	b.mu.Lock()
+	if e, found := b.entries[k]; found {
+		b.used -= e.Value.(*budgetEntry).size
+		b.lru.Remove(e)
+	}
+	b.entries[k] = b.lru.PushFront(&budgetEntry{budgetKey: k, size: size})
+	b.used += size
+	victims := b.shrink()
+	b.mu.Unlock()
+	evictAll(victims)
+}
+
+// touch method marks the entry as most recently used.
+func (b *memoryBudget) touch(owner budgetOwner, key string) {
+	b.mu.Lock()
+	if e, found := b.entries[budgetKey{owner: owner, key: key}]; found {
+		b.lru.MoveToFront(e)
+	}
+	b.mu.Unlock()
+}
+
+// remove method drops the entry from accounting.
+func (b *memoryBudget) remove(owner budgetOwner, key string) {
+	k := budgetKey{owner: owner, key: key}
+	b.mu.Lock()
+	if e, found := b.entries[k]; found {
+		b.used -= e.Value.(*budgetEntry).size
+		b.lru.Remove(e)
+		delete(b.entries, k)
+	}
+	b.mu.Unlock()
+}
+
+// removeOwner method drops all the entries of given owner from accounting.
+func (b *memoryBudget) removeOwner(owner budgetOwner) {
+	b.mu.Lock()
+	for k, e := range b.entries {
+		if k.owner == owner {
+			b.used -= e.Value.(*budgetEntry).size
+			b.lru.Remove(e)
+			delete(b.entries, k)
+		}
+	}
+	b.mu.Unlock()
+}
+
+func (b *memoryBudget) stats() MemoryStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return MemoryStats{Limit: b.limit, Used: b.used, Entries: len(b.entries), Evictions: b.evictions}
+}
+
+// shrink method removes the least recently used entries until it fits the
+// limit and returns them, caller must hold the lock.
+func (b *memoryBudget) shrink() []budgetKey {
+	var victims []budgetKey
+	for b.limit > 0 && b.used > b.limit && b.lru.Len() > 0 {
+		e := b.lru.Back()
+		be := e.Value.(*budgetEntry)
+		b.lru.Remove(e)
+		delete(b.entries, be.budgetKey)
+		b.used -= be.size
+		b.evictions++
+		victims = append(victims, be.budgetKey)
+	}
+	return victims
+}
+
+func evictAll(victims []budgetKey) {
+	for _, v := range victims {
+		v.owner.evict(v.key)
+	}
+}
+
+// setBudget method accounts the mount caches in given budget.
+func (m *Mount) setBudget(b *memoryBudget) {
+	m.cache.setBudget(b)
+	if m.gzCache != nil {
+		m.gzCache.setBudget(b)
+	}
+	if m.lazy != nil {
+		m.lazy.setBudget(b)
+	}
+}
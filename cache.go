@@ -0,0 +1,251 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+// CompressCache option caches the physical files gzip compressed in memory,
+// mirroring the single binary representation. So that development mode
+// (physical filesystem) exercises the same decompression code path and
+// memory profile as production. Files larger than maxSize bytes are not
+// cached, value 0 means no limit. Cached file is refreshed when physical
+// file modification time or size changes.
+func CompressCache(maxSize int64) MountOption {
+	return func(m *Mount) {
+		m.gzCache = newDataCache()
+		m.gzMaxSize = maxSize
+	}
+}
+
+// Warm method eagerly decompresses the gzip data of given virtual files and
+// caches them in memory, so the first read of critical assets (for e.g.
+// index.html, main bundle) does not pay decompression cost. Directory warms
+// all the files under it recursively. For physical filesystem fallback with
+// `Lazy` option, it populates the lazy tree.
+//
+// It warms all the given paths and returns the first error.
+func (m *Mount) Warm(paths ...string) error {
+	var err error
+	for _, name := range paths {
+		if werr := m.warm(name); err == nil {
+			err = werr
+		}
+	}
+	return err
+}
+
+// WarmGlob method warms the files matching the given patterns, see
+// `Mount.Warm`.
+func (m *Mount) WarmGlob(patterns ...string) error {
+	var err error
+	for _, pattern := range patterns {
+		matches, gerr := m.Glob(pattern)
+		if gerr == nil {
+			gerr = m.Warm(matches...)
+		}
+		if err == nil {
+			err = gerr
+		}
+	}
+	return err
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Cache unexported types and methods
+//______________________________________________________________________________
+
+// dataCache holds the cached data of files by virtual path, i.e.
+// decompressed data of virtual files and compressed node of physical files.
+type dataCache struct {
+	mu      sync.RWMutex
+	entries map[string]cacheEntry
+	budget  *memoryBudget
+}
+
+type cacheEntry struct {
+	value interface{}
+	size  int64
+}
+
+func newDataCache() *dataCache {
+	return &dataCache{entries: make(map[string]cacheEntry)}
+}
+
+func (c *dataCache) get(name string) (interface{}, bool) {
+	c.mu.RLock()
+	e, found := c.entries[name]
+	b := c.budget
+	c.mu.RUnlock()
+	if found && b != nil {
+		b.touch(c, name)
+	}
+	return e.value, found
+}
+
+func (c *dataCache) put(name string, value interface{}, size int64) {
+	c.mu.Lock()
+	c.entries[name] = cacheEntry{value: value, size: size}
+	b := c.budget
+	c.mu.Unlock()
+	if b != nil {
+		b.add(c, name, size)
+	}
+}
+
+// size method returns no. of entries and their total size.
+func (c *dataCache) size() (int, int64) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var size int64
+	for _, e := range c.entries {
+		size += e.size
+	}
+	return len(c.entries), size
+}
+
+// flush method drops all the entries and their budget accounting.
+func (c *dataCache) flush() {
+	c.mu.Lock()
+	c.entries = make(map[string]cacheEntry)
+	b := c.budget
+	c.mu.Unlock()
+	if b != nil {
+		b.removeOwner(c)
+	}
+}
+
+// evict method implements `budgetOwner`.
+func (c *dataCache) evict(name string) {
+	c.mu.Lock()
+	delete(c.entries, name)
+	c.mu.Unlock()
+}
+
+// remove method drops the entry from cache and its budget accounting.
+func (c *dataCache) remove(name string) {
+	c.mu.Lock()
+	delete(c.entries, name)
+	b := c.budget
+	c.mu.Unlock()
+	if b != nil {
+		b.remove(c, name)
+	}
+}
+
+// setBudget method moves the cache accounting to given budget, nil removes
+// it from current budget.
+func (c *dataCache) setBudget(b *memoryBudget) {
+	c.mu.Lock()
+	if c.budget == b {
+		c.mu.Unlock()
+		return
+	}
+	if c.budget != nil {
+		c.budget.removeOwner(c)
+	}
+	c.budget = b
+	sizes := make(map[string]int64, len(c.entries))
+	for name, e := range c.entries {
+		sizes[name] = e.size
+	}
+	c.mu.Unlock()
+
+	if b != nil {
+		for name, size := range sizes {
+			b.add(c, name, size)
+		}
+	}
+}
+
+// openCompressed method opens the physical file from compressed cache, it
+// returns false if the file is not cacheable.
+func (m *Mount) openCompressed(name, pname string) (*file, bool, error) {
+	fi, err := os.Stat(pname)
+	if err != nil || !fi.Mode().IsRegular() || (m.gzMaxSize > 0 && fi.Size() > m.gzMaxSize) {
+		return nil, false, nil
+	}
+
+	if v, found := m.gzCache.get(name); found {
+		n := v.(*node)
+		if n.ModTime().Equal(fi.ModTime()) && n.Size() == fi.Size() {
+			return newFile(n), true, nil
+		}
+	}
+
+	data, err := ioutil.ReadFile(pname)
+	if err != nil {
+		return nil, true, err
+	}
+
+	buf := new(bytes.Buffer)
+	gw := gzip.NewWriter(buf)
+	if _, err = gw.Write(data); err == nil {
+		err = gw.Close()
+	}
+	if err != nil {
+		return nil, true, err
+	}
+
+	n := newNode(name, fi)
+	n.DataSize = int64(len(data))
+	n.Gzip = true
+	n.data = buf.Bytes()
+	m.gzCache.put(name, n, int64(len(n.data)))
+	return newFile(n), true, nil
+}
+
+func (m *Mount) warm(name string) error {
+	f, err := m.open(name)
+	if err != nil {
+		// physical filesystem fallback, stat populates lazy tree
+		_, err = m.Stat(name)
+		return err
+	}
+	release, err := m.acquireData("warm", name)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return m.warmNode(f.node)
+}
+
+func (m *Mount) warmNode(n *node) error {
+	if n.IsDir() {
+		m.treeMu.RLock()
+		infos := n.childInfos
+		m.treeMu.RUnlock()
+		for _, c := range infos {
+			if err := m.warmNode(c.(*node)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := n.loadData(); err != nil {
+		return err
+	}
+	if !n.IsGzip() {
+		return nil
+	}
+	if _, found := m.cache.get(n.Path); found {
+		return nil
+	}
+
+	f := newFile(n)
+	defer func() { _ = f.Close() }()
+	data, err := readAllSize(f, n.DataSize)
+	if err != nil {
+		return err
+	}
+	m.cache.put(n.Path, data, int64(len(data)))
+	return nil
+}
@@ -0,0 +1,64 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ErrInvalidPath is returned for the path rejected by `vfs.CleanPath`.
+var ErrInvalidPath = errors.New("vfs: invalid path")
+
+// MaxPathLength is the maximum length of the path accepted by
+// `vfs.CleanPath`, 0 means no limit.
+var MaxPathLength = 0
+
+// CleanPath method returns the canonical virtual path of given name, it is
+// used by `VFS`, `Mount` and `MemFS` so all of them agree on valid path.
+//
+//   - OS path separators are converted into slash and leading slash is added
+//   - `.` elements and trailing slash are removed
+//   - empty name, NUL byte and empty element (`a//b`) are rejected
+//   - parent element `..` is rejected, it would escape the root
+//   - name longer than `vfs.MaxPathLength` is rejected
+//
+// Rejected name returns `*os.PathError` with `ErrInvalidPath`, name with
+// parent element returns it with `os.ErrPermission`.
+func CleanPath(name string) (string, error) {
+	return cleanPath("cleanpath", name)
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// CleanPath unexported methods
+//______________________________________________________________________________
+
+func cleanPath(op, name string) (string, error) {
+	invalid := &os.PathError{Op: op, Path: name, Err: ErrInvalidPath}
+	if name == "" || strings.IndexByte(name, 0) != -1 ||
+		(MaxPathLength > 0 && len(name) > MaxPathLength) {
+		return "", invalid
+	}
+
+	// physical path with volume name, i.e. on Windows
+	if filepath.VolumeName(name) != "" {
+		return filepath.Clean(name), nil
+	}
+
+	p := strings.TrimSuffix(filepath.ToSlash(name), "/")
+	for i, s := range strings.Split(p, "/") {
+		if s == ".." {
+			return "", &os.PathError{Op: op, Path: name, Err: os.ErrPermission}
+		}
+		if s == "" && i > 0 {
+			return "", invalid
+		}
+	}
+
+	return path.Clean("/" + p), nil
+}
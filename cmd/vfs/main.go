@@ -0,0 +1,293 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Command vfs generates the Go source code of asset directory for single
+// binary build, so the assets can be embedded without writing wrapper code
+// around `vfs.Binary`. For e.g.:
+//
+//	vfs gen --mount /app --src ./assets --out generated_vfs.go --pkg assets
+//
+// Generated code declares `AppVFS` unless `--var` or `--aah` is given, run
+// `vfs gen --help` for all the flags.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"aahframework.org/vfs.v0"
+)
+
+const usage = `Usage: vfs <command> [flags]
+
+Commands:
+  gen       generates the Go source code of asset directory
+  version   prints the version
+
+Run 'vfs gen --help' for the flags.
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run method runs the command of given args and returns the exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	switch args[0] {
+	case "gen":
+		if err := gen(args[1:], stdout, stderr); err != nil {
+			if err != flag.ErrHelp {
+				fmt.Fprintf(stderr, "vfs: %v\n", err)
+			}
+			return 1
+		}
+	case "version":
+		fmt.Fprintf(stdout, "vfs v%s\n", vfs.Version)
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, usage)
+	default:
+		fmt.Fprintf(stderr, "vfs: unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+	return 0
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// gen command
+//______________________________________________________________________________
+
+// stringList is the repeatable flag value.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+var literals = map[string]vfs.BinaryLiteral{
+	"string": vfs.LiteralString,
+	"bytes":  vfs.LiteralByteSlice,
+	"base64": vfs.LiteralBase64,
+}
+
+func gen(args []string, stdout, stderr io.Writer) error {
+	var excludes, includes, skipExts stringList
+	fset := flag.NewFlagSet("vfs gen", flag.ContinueOnError)
+	fset.SetOutput(stderr)
+	mountPath := fset.String("mount", "/", "mount path of the assets in VFS")
+	src := fset.String("src", "", "asset directory (required)")
+	out := fset.String("out", "", "output file, standard output if empty")
+	pkg := fset.String("pkg", "main", "package name of generated code")
+	varName := fset.String("var", "", "package level *vfs.VFS variable populated by generated code")
+	aah := fset.Bool("aah", false, "populate aah.AppVFS() of aah framework")
+	fset.Var(&excludes, "exclude", "exclude pattern of name or relative path, repeatable")
+	follow := fset.Bool("follow-symlinks", false, "embed the targets of symbolic links, otherwise links are skipped")
+	fset.Var(&includes, "include", "include pattern of name or relative path, for e.g. '**/*.html', repeatable")
+	level := fset.Int("level", 0, "gzip compression level 1-9, default is best compression")
+	minSize := fset.Int64("min-compress", 0, "size in bytes below which files are stored as-is")
+	maxSize := fset.Int64("max-embed", 0, "size in bytes above which files are served from -src directory at runtime, not embedded")
+	fset.Var(&skipExts, "skip-ext", "extension stored as-is, repeatable (default is vfs.DefaultSkipCompressExts)")
+	literal := fset.String("literal", "string", "data literal: string, bytes or base64")
+	shards := fset.Int("shards", 0, "split generated code into n files, requires -out")
+	splitDirs := fset.Bool("split-dirs", false, "split generated code per top level directory, requires -out")
+	manifest := fset.String("manifest", "", "asset manifest file, CSV or YAML per .csv, .yaml or .yml extension otherwise JSON")
+	mtime := fset.Int64("mtime", 0, "fixed modification time in Unix seconds, for reproducible output")
+	keyFile := fset.String("key-file", "", "file of raw AES key, 16, 24 or 32 bytes, to encrypt embedded file data")
+	workers := fset.Int("workers", 0, "no. of files encoded concurrently, default is GOMAXPROCS")
+	dryRun := fset.Bool("dry-run", false, "list the files would be embedded with projected stored size, no code is generated")
+	report := fset.Bool("report", false, "report the size statistics of embedded files on standard error")
+	progress := fset.Bool("progress", false, "report embedded and skipped files on standard error")
+	signKeyFile := fset.String("sign-key-file", "", "file of raw ed25519 private key, 64 bytes, to sign the embedded file checksums")
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+
+	if *src == "" {
+		return errors.New("-src is required")
+	}
+	if fset.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %v", fset.Args())
+	}
+	lit, found := literals[*literal]
+	if !found {
+		return fmt.Errorf("invalid literal %q", *literal)
+	}
+	if *shards < 0 || *shards > 0 && *splitDirs {
+		return errors.New("-shards must be positive and it cannot be used with -split-dirs")
+	}
+	split := *shards > 0 || *splitDirs
+	if split && *out == "" {
+		return errors.New("-out is required to split generated code")
+	}
+
+	opts := vfs.BinaryOptions{
+		PackageName:      *pkg,
+		VFSVarName:       *varName,
+		SkipAahImports:   !*aah,
+		CompressionLevel: *level,
+		MinCompressSize:  *minSize,
+		MaxEmbedSize:     *maxSize,
+		Literal:          lit,
+		Includes:         includes,
+		FollowSymlinks:   *follow,
+		Workers:          *workers,
+	}
+	if len(skipExts) > 0 {
+		opts.SkipCompressExts = skipExts
+	}
+	if *mtime != 0 {
+		opts.ModTime = time.Unix(*mtime, 0)
+	}
+	if *keyFile != "" {
+		var err error
+		if opts.EncryptionKey, err = ioutil.ReadFile(*keyFile); err != nil {
+			return err
+		}
+	}
+	if *report {
+		opts.Report = new(vfs.Report)
+	}
+	if *progress {
+		opts.Progress = func(p vfs.BinaryProgress) { reportProgress(stderr, p) }
+	}
+	if *signKeyFile != "" {
+		var err error
+		if opts.SigningKey, err = ioutil.ReadFile(*signKeyFile); err != nil {
+			return err
+		}
+	}
+
+	if *dryRun {
+		entries, err := vfs.BinaryDryRun(*mountPath, *src, excludes, opts)
+		if err == nil {
+			writeDryRun(stdout, entries)
+			if opts.Report != nil {
+				writeReport(stderr, *opts.Report)
+			}
+		}
+		return err
+	}
+
+	var mf *os.File
+	if *manifest != "" {
+		var err error
+		if mf, err = os.Create(*manifest); err != nil {
+			return err
+		}
+		defer func() { _ = mf.Close() }()
+		opts.Manifest = mf
+		switch {
+		case strings.HasSuffix(*manifest, ".csv"):
+			opts.ManifestFormat = vfs.ManifestCSV
+		case strings.HasSuffix(*manifest, ".yaml"), strings.HasSuffix(*manifest, ".yml"):
+			opts.ManifestFormat = vfs.ManifestYAML
+		}
+	}
+
+	var err error
+	switch {
+	case split:
+		err = genSplit(*mountPath, *src, *out, excludes, opts, *shards)
+	case *out == "":
+		err = vfs.BinaryTo(stdout, *mountPath, *src, excludes, opts)
+	default:
+		var code []byte
+		if code, err = vfs.BinaryWithOptions(*mountPath, *src, excludes, opts); err == nil {
+			err = ioutil.WriteFile(*out, code, 0644)
+		}
+	}
+	if err == nil && mf != nil {
+		err = mf.Close()
+	}
+	if err == nil && opts.Report != nil {
+		writeReport(stderr, *opts.Report)
+	}
+	return err
+}
+
+// writeDryRun method writes the files would be embedded and the totals.
+func writeDryRun(w io.Writer, entries []vfs.ManifestEntry) {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	var size, stored int64
+	fmt.Fprintln(tw, "size\tstored\t")
+	for _, e := range entries {
+		fmt.Fprintf(tw, "%d\t%d\t  %s\n", e.Size, e.StoredSize, e.Path)
+		size += e.Size
+		stored += e.StoredSize
+	}
+	fmt.Fprintf(tw, "%d\t%d\t  total %d files\n", size, stored, len(entries))
+	_ = tw.Flush()
+}
+
+// writeReport method writes the totals, extension breakdown and largest
+// files of report.
+func writeReport(w io.Writer, r vfs.Report) {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "files\tsize\tstored\t")
+	for _, es := range r.Extensions {
+		ext := es.Ext
+		if ext == "" {
+			ext = "(none)"
+		}
+		fmt.Fprintf(tw, "%d\t%d\t%d\t  %s\n", es.Files, es.Size, es.StoredSize, ext)
+	}
+	fmt.Fprintf(tw, "%d\t%d\t%d\t  total (%.0f%%)\n", r.Files, r.Size, r.StoredSize, r.Ratio()*100)
+	_ = tw.Flush()
+
+	fmt.Fprintln(w, "largest files:")
+	for _, e := range r.Largest {
+		fmt.Fprintf(w, "  %d  %s\n", e.StoredSize, e.Path)
+	}
+}
+
+// reportProgress method writes the line of embedded or skipped file.
+func reportProgress(w io.Writer, p vfs.BinaryProgress) {
+	switch {
+	case p.Skipped:
+		fmt.Fprintf(w, "skip %s (%s)\n", p.Path, p.Reason)
+	case p.Physical:
+		fmt.Fprintf(w, "[%d/%d] %s %d bytes (physical)\n", p.Files, p.TotalFiles, p.Path, p.Size)
+	default:
+		fmt.Fprintf(w, "[%d/%d] %s %d => %d bytes (%.0f%%)\n", p.Files, p.TotalFiles, p.Path,
+			p.Size, p.StoredSize, p.Ratio()*100)
+	}
+}
+
+// genSplit method writes the main code into out and shards next to it with
+// suffix `_shard<n>`, for e.g.: `generated_vfs_shard1.go`.
+func genSplit(mountPath, src, out string, excludes []string, opts vfs.BinaryOptions, n int) error {
+	codes, err := vfs.BinarySplit(mountPath, src, excludes, opts, n)
+	if err != nil {
+		return err
+	}
+
+	base := strings.TrimSuffix(out, filepath.Ext(out))
+	for i, code := range codes {
+		name := out
+		if i > 0 {
+			name = fmt.Sprintf("%s_shard%d.go", base, i)
+		}
+		if err = ioutil.WriteFile(name, code, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
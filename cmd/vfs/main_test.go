@@ -0,0 +1,102 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"aahframework.org/test.v0/assert"
+	"aahframework.org/vfs.v0"
+)
+
+func TestCmdVFSGen(t *testing.T) {
+	src := filepath.Join("..", "..", "testdata", "vfstest", "static")
+	dir, err := ioutil.TempDir("", "vfs-cmd")
+	assert.FailNowOnError(t, err, "")
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	runCmd := func(args ...string) (int, string, string) {
+		stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+		code := run(args, stdout, stderr)
+		return code, stdout.String(), stderr.String()
+	}
+
+	// standard output
+	code, stdout, _ := runCmd("gen", "--mount", "/assets", "--src", src, "--pkg", "assets", "--literal", "base64")
+	assert.Equal(t, 0, code)
+	assert.True(t, strings.HasPrefix(stdout, "// Code generated by aah vfs, DO NOT EDIT.\n\npackage assets\n"))
+	assert.True(t, strings.Contains(stdout, "var AppVFS = new(vfs.VFS)"))
+	assert.True(t, strings.Contains(stdout, `vfsBase64("`))
+	assert.False(t, strings.Contains(stdout, "aahframework.org/aah.v0"))
+
+	// output file, excludes and manifest
+	out := filepath.Join(dir, "generated_vfs.go")
+	manifest := filepath.Join(dir, "manifest.json")
+	code, _, _ = runCmd("gen", "--src", src, "--out", out, "--exclude", "*.js",
+		"--exclude", "img", "--manifest", manifest, "--aah")
+	assert.Equal(t, 0, code)
+	data, err := ioutil.ReadFile(out)
+	assert.Nil(t, err)
+	assert.True(t, bytes.Contains(data, []byte("fs := aah.AppVFS()")))
+	assert.False(t, bytes.Contains(data, []byte(".js\"")))
+	var entries []vfs.ManifestEntry
+	data, err = ioutil.ReadFile(manifest)
+	assert.Nil(t, err)
+	assert.Nil(t, json.Unmarshal(data, &entries))
+	assert.True(t, len(entries) > 0)
+
+	// progress
+	code, _, stderr := runCmd("gen", "--src", src, "--out", out, "--exclude", "img", "--min-compress", "1000000", "--progress")
+	assert.Equal(t, 0, code)
+	assert.True(t, strings.HasPrefix(stderr, "skip /img (excluded)\n[1/3] /css/aah.css "))
+	assert.True(t, strings.Contains(stderr, "[3/3] /robots.txt "))
+	assert.True(t, strings.Contains(stderr, " bytes (100%)\n"))
+
+	// report
+	code, _, stderr = runCmd("gen", "--src", src, "--out", out, "--report")
+	assert.Equal(t, 0, code)
+	assert.True(t, strings.Contains(stderr, "  .ico\n"))
+	assert.True(t, strings.Contains(stderr, "largest files:\n"))
+
+	// dry run
+	code, stdout, _ = runCmd("gen", "--src", src, "--exclude", "img", "--dry-run")
+	assert.Equal(t, 0, code)
+	assert.False(t, strings.Contains(stdout, "package main"))
+	assert.True(t, strings.Contains(stdout, "  /robots.txt\n"))
+	assert.True(t, strings.Contains(stdout, "  total 3 files\n"))
+
+	// split per top level directory
+	code, _, _ = runCmd("gen", "--src", src, "--out", out, "--split-dirs")
+	assert.Equal(t, 0, code)
+	_, err = os.Stat(filepath.Join(dir, "generated_vfs_shard1.go"))
+	assert.Nil(t, err)
+
+	// errors
+	for _, args := range [][]string{
+		{"gen"},
+		{"gen", "--src", src, "--literal", "hex"},
+		{"gen", "--src", src, "--shards", "2"},
+		{"gen", "--src", src, "--out", out, "--shards", "2", "--split-dirs"},
+		{"gen", "--src", filepath.Join(dir, "not-exists")},
+		{"gen", "--unknown"},
+	} {
+		code, _, _ = runCmd(args...)
+		assert.Equal(t, 1, code)
+	}
+
+	code, _, stderr = runCmd("build")
+	assert.Equal(t, 2, code)
+	assert.True(t, strings.Contains(stderr, `unknown command "build"`))
+
+	code, stdout, _ = runCmd("version")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "vfs v"+vfs.Version+"\n", stdout)
+}
@@ -0,0 +1,139 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Path sources reported by `VFS.Conflicts`.
+const (
+	SourceVirtual  = "virtual"
+	SourcePhysical = "physical"
+)
+
+// MountConflict struct represents the virtual path resolvable via more than
+// one mount. Mount of longest mount path wins and serves the path, see
+// `VFS.FindMount`.
+type MountConflict struct {
+	// Path is the virtual path.
+	Path string
+
+	// Winner is the mount path of the mount which serves the path.
+	Winner string
+
+	// WinnerSource is source of the path in winner mount, empty if winner
+	// does not have the path, so the path is not reachable anymore.
+	WinnerSource string
+
+	// Shadowed is the mount path of the mount whose path is shadowed.
+	Shadowed string
+
+	// ShadowedSource is source of the path in shadowed mount.
+	ShadowedSource string
+}
+
+// String method Stringer interface.
+func (c MountConflict) String() string {
+	if c.WinnerSource == "" {
+		return fmt.Sprintf("conflict(path=%s hidden-by=%s shadowed=%s:%s)",
+			c.Path, c.Winner, c.Shadowed, c.ShadowedSource)
+	}
+	return fmt.Sprintf("conflict(path=%s winner=%s:%s shadowed=%s:%s)",
+		c.Path, c.Winner, c.WinnerSource, c.Shadowed, c.ShadowedSource)
+}
+
+// Conflicts method reports the paths of a mount shadowed by another mount
+// mounted on its sub path, for e.g.: `/app/static/robots.txt` exists on both
+// `/app` and `/app/static` mounts. Source of the path is virtual tree or
+// physical filesystem fallback. It is useful to debug the "wrong file served"
+// issues. Results are sorted by mount path of shadowed mount and path.
+//
+// Divergence between virtual tree and physical source of same mount is
+// reported by `vfs.Shadow`.
+func (v *VFS) Conflicts() ([]MountConflict, error) {
+	t := v.newMountTree()
+	var conflicts []MountConflict
+	for _, outer := range t.mounts {
+		for _, inner := range t.nestedMounts(outer) {
+			fi, err := outer.Lstat(inner.Vroot)
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			err = walk(outer, inner.Vroot, fi, func(fpath string, _ os.FileInfo, err error) error {
+				if err != nil {
+					return err
+				}
+				winner := t.owner(fpath)
+				conflicts = append(conflicts, MountConflict{
+					Path:           fpath,
+					Winner:         winner.Vroot,
+					WinnerSource:   winner.sourceOf(fpath),
+					Shadowed:       outer.Vroot,
+					ShadowedSource: outer.sourceOf(fpath),
+				})
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	sort.SliceStable(conflicts, func(i, j int) bool {
+		if conflicts[i].Shadowed != conflicts[j].Shadowed {
+			return conflicts[i].Shadowed < conflicts[j].Shadowed
+		}
+		return conflicts[i].Path < conflicts[j].Path
+	})
+	return conflicts, nil
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Conflict unexported methods
+//______________________________________________________________________________
+
+// nestedMounts method returns the top most mounts mounted under the given
+// mount, in the order of mount path.
+func (t *mountTree) nestedMounts(outer *Mount) []*Mount {
+	var nested []*Mount
+	for _, m := range t.mounts {
+		if m == outer || !isPathWithin(m.Vroot, outer.Vroot) {
+			continue
+		}
+		if !isNestedIn(m, nested) {
+			nested = append(nested, m)
+		}
+	}
+	return nested
+}
+
+func isNestedIn(m *Mount, mounts []*Mount) bool {
+	for _, o := range mounts {
+		if isPathWithin(m.Vroot, o.Vroot) {
+			return true
+		}
+	}
+	return false
+}
+
+// sourceOf method returns the source of the name in mount, empty if it does
+// not exist.
+func (m *Mount) sourceOf(name string) string {
+	if _, err := m.open(name); err == nil {
+		return SourceVirtual
+	}
+	if m.hasPhysical() {
+		if _, err := os.Lstat(m.toPhysicalPath(name)); err == nil {
+			return SourcePhysical
+		}
+	}
+	return ""
+}
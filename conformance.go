@@ -0,0 +1,252 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+//go:build go1.16
+// +build go1.16
+
+package vfs
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"testing/fstest"
+)
+
+// TestConformance method tests the FileSystem implementation with
+// `fstest.TestFS` via `vfs.AsIOFS` and vfs invariants, for e.g. custom
+// FileSystem of the application
+//
+//	func TestAssetsFS(t *testing.T) {
+//		vfs.TestConformance(t, newAssetsFS())
+//	}
+//
+// Invariants are checked for each directory and regular file of the tree:
+//
+//   - Readdir and Readdirnames with n > 0 page through the same entries in
+//     same order as n <= 0, then return io.EOF.
+//   - Seek from start, current and end positions the read offset, negative
+//     position is an error and read at the end returns io.EOF.
+//   - Stat of opened file, Stat of name and ReadFile agree with the content.
+//
+// Failures are reported via t.Errorf, test continues with next path.
+func TestConformance(t *testing.T, fsys FileSystem) {
+	t.Helper()
+	iofsys := AsIOFS(fsys)
+
+	var dirs, files []string
+	err := fs.WalkDir(iofsys, ".", func(name string, d fs.DirEntry, err error) error {
+		switch {
+		case err != nil:
+			return err
+		case d.IsDir():
+			dirs = append(dirs, name)
+		case d.Type().IsRegular():
+			files = append(files, name)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("vfs: conformance walk: %v", err)
+		return
+	}
+	if err = fstest.TestFS(iofsys, files...); err != nil {
+		t.Errorf("vfs: conformance fstest: %v", err)
+	}
+
+	// directory listing of VFS is checked on its tree, see `vfs.AsIOFS`
+	src, root := iofsys.(*ioFS).src, iofsys.(*ioFS).root
+	for _, name := range dirs {
+		if err = checkReaddir(src, path.Join(root, name)); err != nil {
+			t.Errorf("vfs: conformance %s: %v", name, err)
+		}
+	}
+	for _, name := range files {
+		if err = checkFileSeek(fsys, path.Join(root, name)); err != nil {
+			t.Errorf("vfs: conformance %s: %v", name, err)
+		}
+	}
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Conformance unexported methods
+//______________________________________________________________________________
+
+// checkReaddir method checks the Readdir and Readdirnames pagination of
+// directory against its full listing.
+func checkReaddir(fsys ioSource, name string) error {
+	var all []string
+	err := withFile(fsys, name, func(f File) error {
+		infos, err := f.Readdir(-1)
+		for _, fi := range infos {
+			all = append(all, fi.Name())
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	var paged []string
+	err = withFile(fsys, name, func(f File) error {
+		for {
+			infos, err := f.Readdir(1)
+			if err == io.EOF {
+				if len(infos) > 0 {
+					return fmt.Errorf("readdir returned %d entries with io.EOF", len(infos))
+				}
+				if _, err = f.Readdir(1); err != io.EOF {
+					return fmt.Errorf("readdir after end returned %v, expected io.EOF", err)
+				}
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if len(infos) != 1 {
+				return fmt.Errorf("readdir(1) returned %d entries", len(infos))
+			}
+			paged = append(paged, infos[0].Name())
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if !equalNames(all, paged) {
+		return fmt.Errorf("readdir pages %v, expected %v", paged, all)
+	}
+
+	var pagedNames []string
+	err = withFile(fsys, name, func(f File) error {
+		for {
+			names, err := f.Readdirnames(2)
+			pagedNames = append(pagedNames, names...)
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if len(names) == 0 {
+				return fmt.Errorf("readdirnames(2) returned no names without io.EOF")
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if !equalNames(all, pagedNames) {
+		return fmt.Errorf("readdirnames pages %v, expected %v", pagedNames, all)
+	}
+	return nil
+}
+
+// checkFileSeek method checks the stat, read and seek semantics of file
+// against its ReadFile content.
+func checkFileSeek(fsys FileSystem, name string) error {
+	data, err := fsys.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	size := int64(len(data))
+	fi, err := fsys.Stat(name)
+	if err != nil {
+		return err
+	}
+	if fi.Size() != size {
+		return fmt.Errorf("stat size is %d, read %d bytes", fi.Size(), size)
+	}
+
+	return withFile(fsys, name, func(f File) error {
+		if fi, err = f.Stat(); err != nil {
+			return err
+		}
+		if fi.Size() != size || fi.IsDir() {
+			return fmt.Errorf("file stat size is %d dir=%v, expected %d", fi.Size(), fi.IsDir(), size)
+		}
+
+		check := func(offset int64, whence int, expected int64) error {
+			pos, err := f.Seek(offset, whence)
+			if err != nil {
+				return fmt.Errorf("seek(%d, %d): %v", offset, whence, err)
+			}
+			if pos != expected {
+				return fmt.Errorf("seek(%d, %d) returned %d, expected %d", offset, whence, pos, expected)
+			}
+			return nil
+		}
+		readRest := func(from int64) error {
+			b, err := ioutil.ReadAll(f)
+			if err != nil {
+				return err
+			}
+			if !bytes.Equal(b, data[from:]) {
+				return fmt.Errorf("read from %d does not match content", from)
+			}
+			return nil
+		}
+
+		if err = readRest(0); err != nil {
+			return err
+		}
+		if err = check(0, io.SeekEnd, size); err != nil {
+			return err
+		}
+		if n, err := f.Read(make([]byte, 1)); n != 0 || err != io.EOF {
+			return fmt.Errorf("read at end returned %d, %v, expected io.EOF", n, err)
+		}
+		mid := size / 2
+		if err = check(mid, io.SeekStart, mid); err != nil {
+			return err
+		}
+		if err = readRest(mid); err != nil {
+			return err
+		}
+		if err = check(mid, io.SeekStart, mid); err != nil {
+			return err
+		}
+		if err = check(size-mid, io.SeekCurrent, size); err != nil {
+			return err
+		}
+		if err = check(-size, io.SeekEnd, 0); err != nil {
+			return err
+		}
+		if err = readRest(0); err != nil {
+			return err
+		}
+		if _, err = f.Seek(-1, io.SeekStart); err == nil {
+			return fmt.Errorf("seek(-1, %d) did not fail", io.SeekStart)
+		}
+		return nil
+	})
+}
+
+func withFile(fsys ioSource, name string, fn func(f File) error) error {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	err = fn(f)
+	if cerr := f.Close(); err == nil && cerr != nil {
+		err = &os.PathError{Op: "close", Path: name, Err: cerr}
+	}
+	return err
+}
+
+func equalNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,36 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+//go:build go1.16
+// +build go1.16
+
+package vfs
+
+import (
+	"path/filepath"
+	"testing"
+
+	"aahframework.org/test.v0/assert"
+)
+
+func TestVFSConformance(t *testing.T) {
+	v := createVFS(t)
+	TestConformance(t, v)
+
+	m, err := v.FindMount("/app")
+	assert.FailNowOnError(t, err, "")
+	TestConformance(t, m)
+
+	pm, err := NewMount("/assets", filepath.Join(testdataBaseDir(), "vfstest", "static"))
+	assert.FailNowOnError(t, err, "")
+	TestConformance(t, pm)
+
+	mfs := NewMemFS()
+	assert.Nil(t, mfs.Mkdir("/docs", 0755))
+	for _, name := range []string{"/docs/a.txt", "/docs/b.txt", "/docs/c.txt", "/readme.md"} {
+		assert.Nil(t, mfs.WriteFile(name, []byte("content of "+name), 0644))
+	}
+	assert.Nil(t, mfs.WriteFile("/empty.txt", nil, 0644))
+	TestConformance(t, mfs)
+}
@@ -0,0 +1,78 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"os"
+	"time"
+)
+
+// ContentCache option caches the data of physical filesystem fallback files
+// read via `Mount.ReadFile` in memory, for e.g. development mode and the
+// files beyond `BinaryOptions.MaxEmbedSize`. Cached data is served while the
+// modification time and size of the file are unchanged, so the file is
+// opened and stat'ed but not read again.
+//
+// Total size of cached data is bounded by maxBytes, least recently used files
+// are evicted beyond it and the file larger than maxBytes is not cached.
+// Value 0 means no limit. Use `Mount.FlushContentCache` to drop the cached
+// data, `Mount.Watch` does it for changed paths.
+func ContentCache(maxBytes int64) MountOption {
+	return func(m *Mount) {
+		c := &contentCache{max: maxBytes, data: newDataCache(), budget: newMemoryBudget()}
+		c.budget.setLimit(maxBytes)
+		c.data.setBudget(c.budget)
+		m.contentCache = c
+	}
+}
+
+// FlushContentCache method drops all the cached data of `vfs.ContentCache`.
+// It is no-op if the option is not set.
+func (m *Mount) FlushContentCache() {
+	if m.contentCache != nil {
+		m.contentCache.data.flush()
+	}
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Content cache unexported types and methods
+//______________________________________________________________________________
+
+// contentCache holds the physical file data by physical path, its own memory
+// budget keeps it in LRU order within the max bytes.
+type contentCache struct {
+	max    int64
+	data   *dataCache
+	budget *memoryBudget
+}
+
+type contentEntry struct {
+	data    []byte
+	modTime time.Time
+	size    int64
+}
+
+// readFile method returns the copy of cached data of opened physical file,
+// data is read and cached on miss or change. Caller owns the returned data.
+func (c *contentCache) readFile(f *physicalFile, fi os.FileInfo) ([]byte, error) {
+	pname := f.Name()
+	if v, found := c.data.get(pname); found {
+		e := v.(contentEntry)
+		if e.size == fi.Size() && e.modTime.Equal(fi.ModTime()) {
+			return append([]byte(nil), e.data...), nil
+		}
+		c.data.remove(pname)
+	}
+
+	data, err := readAllSize(f, fi.Size())
+	if err != nil {
+		return nil, err
+	}
+	if size := int64(len(data)); size == fi.Size() && (c.max <= 0 || size <= c.max) {
+		cached := append([]byte(nil), data...)
+		c.data.put(pname, contentEntry{data: cached, modTime: fi.ModTime(), size: size}, size)
+	}
+	return data, nil
+}
@@ -0,0 +1,125 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+)
+
+// contentTypes is the extension based content types, its used over system
+// mime types so that result is same on all the platforms.
+var contentTypes = map[string]string{
+	".css":         "text/css; charset=utf-8",
+	".csv":         "text/csv; charset=utf-8",
+	".eot":         "application/vnd.ms-fontobject",
+	".gif":         "image/gif",
+	".htm":         "text/html; charset=utf-8",
+	".html":        "text/html; charset=utf-8",
+	".ico":         "image/x-icon",
+	".jpeg":        "image/jpeg",
+	".jpg":         "image/jpeg",
+	".js":          "application/javascript",
+	".json":        "application/json",
+	".map":         "application/json",
+	".md":          "text/markdown; charset=utf-8",
+	".mjs":         "application/javascript",
+	".otf":         "font/otf",
+	".pdf":         "application/pdf",
+	".png":         "image/png",
+	".svg":         "image/svg+xml",
+	".ttf":         "font/ttf",
+	".txt":         "text/plain; charset=utf-8",
+	".wasm":        "application/wasm",
+	".webmanifest": "application/manifest+json",
+	".webp":        "image/webp",
+	".woff":        "font/woff",
+	".woff2":       "font/woff2",
+	".xml":         "text/xml; charset=utf-8",
+}
+
+// ContentType method returns the content type of given file. First 512
+// bytes of file content is sniffed per `http.DetectContentType`, content is
+// read decompressed in case of gzip data, so gzip magic bytes is never
+// detected as content type of embedded file. Generic result, for e.g.:
+// `text/plain` of CSS file, falls back to the content type of file
+// extension if its known. For e.g.:
+//
+//	ctype, err := vfs.ContentType(aah.AppVFS(), "/app/static/css/aah.css")
+//
+// It uses `os.Open` if fs == nil otherwise FileSystem.
+func ContentType(fs FileSystem, name string) (string, error) {
+	var f File
+	var err error
+	if fs == nil {
+		f, err = os.Open(name)
+	} else {
+		f, err = fs.Open(name)
+	}
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	if fi.IsDir() {
+		return "", &os.PathError{Op: "read", Path: name, Err: errors.New("is a directory")}
+	}
+	return contentType(name, f)
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Content type unexported methods
+//______________________________________________________________________________
+
+// contentType method sniffs the content of r and falls back to extension
+// content type of name on generic result.
+func contentType(name string, r io.Reader) (string, error) {
+	var buf [512]byte
+	n, err := io.ReadFull(r, buf[:])
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+
+	sniffed := http.DetectContentType(buf[:n])
+	if !isGenericContentType(sniffed) {
+		return sniffed, nil
+	}
+	if ctype := extContentType(name); ctype != "" {
+		return ctype, nil
+	}
+	return sniffed, nil
+}
+
+// extContentType method returns the content type of file extension, empty
+// if its not known.
+func extContentType(name string) string {
+	ext := strings.ToLower(path.Ext(name))
+	if ctype, found := contentTypes[ext]; found {
+		return ctype
+	}
+	return mime.TypeByExtension(ext)
+}
+
+// isGenericContentType method reports whether sniffed content type is not
+// specific to the content, for e.g.: CSS and JavaScript are sniffed as plain
+// text, SVG as XML.
+func isGenericContentType(ctype string) bool {
+	switch ctype {
+	case "application/octet-stream", "text/plain; charset=utf-8",
+		"text/plain; charset=utf-16be", "text/plain; charset=utf-16le",
+		"text/xml; charset=utf-8":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"path/filepath"
+	"testing"
+
+	"aahframework.org/test.v0/assert"
+)
+
+func TestVFSContentType(t *testing.T) {
+	fs := createVFS(t)
+
+	for name, expected := range map[string]string{
+		// gzip data is sniffed decompressed
+		"/app/config/aah.conf":                   "text/plain; charset=utf-8",
+		"/app/static/css/aah.css":                "text/css; charset=utf-8",
+		"/app/static/js/aah.js":                  "application/javascript",
+		"/app/static/img/aah-framework-logo.png": "image/png",
+		"/app/views/layouts/master.html":         "text/html; charset=utf-8",
+	} {
+		ctype, err := ContentType(fs, name)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, ctype)
+	}
+
+	ctype, err := ContentType(nil, filepath.Join(testdataBaseDir(), "vfstest", "static", "css", "aah.css"))
+	assert.Nil(t, err)
+	assert.Equal(t, "text/css; charset=utf-8", ctype)
+
+	_, err = ContentType(fs, "/app/static")
+	assert.NotNil(t, err)
+	_, err = ContentType(fs, "/app/static/not-exists.css")
+	assert.NotNil(t, err)
+}
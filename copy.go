@@ -0,0 +1,236 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// ErrDestinationExists returned by Copy when destination exists and
+// conflict policy is `CopyFail`.
+var ErrDestinationExists = errors.New("vfs: destination already exists")
+
+// ConflictPolicy type is used to specify Copy behaviour when destination
+// file already exists.
+type ConflictPolicy uint8
+
+// Copy conflict policies
+const (
+	CopyOverwrite ConflictPolicy = iota
+	CopySkip
+	CopyFail
+)
+
+// CopyProgress struct is reported to `CopyOptions.Progress` after each file
+// is copied or skipped.
+type CopyProgress struct {
+	// Src is the source file path.
+	Src string
+
+	// Dst is the destination file path.
+	Dst string
+
+	// Bytes is no. of bytes copied for the file, 0 if skipped.
+	Bytes int64
+
+	// Skipped is true if file skipped due to conflict policy.
+	Skipped bool
+
+	// Files is no. of files copied so far.
+	Files int
+
+	// TotalBytes is no. of bytes copied so far.
+	TotalBytes int64
+}
+
+// CopyOptions struct is used to specify conflict policy and progress
+// callback of Copy.
+type CopyOptions struct {
+	// Conflict is the policy for existing destination file, default is
+	// `CopyOverwrite`.
+	Conflict ConflictPolicy
+
+	// Progress func is called after each file.
+	Progress func(CopyProgress)
+}
+
+// Copy method copies the file or directory (recursively) srcPath from srcFS
+// into dstPath of dstFS, existing destination files are overwritten. For e.g.:
+// embedded assets into temp directory
+//
+//	vfs.Copy(nil, "/tmp/app/views", aah.AppVFS(), "/app/views")
+//
+// It operates on physical filesystem if dstFS == nil or srcFS == nil.
+// Parent directory of dstPath must exist.
+func Copy(dstFS WritableFileSystem, dstPath string, srcFS FileSystem, srcPath string) error {
+	return CopyWithOptions(dstFS, dstPath, srcFS, srcPath, CopyOptions{})
+}
+
+// CopyWithOptions method is same as `vfs.Copy` with conflict policy and
+// progress callback per given options.
+//
+// Files are created with source permission plus owner write, since virtual
+// files are read-only `0444`. Directories are created with source permission
+// plus owner full access.
+func CopyWithOptions(dstFS WritableFileSystem, dstPath string, srcFS FileSystem, srcPath string, opts CopyOptions) error {
+	c := &copier{dst: dstFS, src: srcFS, opts: opts}
+	fi, err := c.srcStat(srcPath)
+	if err != nil {
+		return err
+	}
+	return c.copy(dstPath, srcPath, fi)
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Copy unexported types and methods
+//______________________________________________________________________________
+
+type copier struct {
+	dst   WritableFileSystem
+	src   FileSystem
+	opts  CopyOptions
+	files int
+	total int64
+}
+
+func (c *copier) copy(dstPath, srcPath string, fi os.FileInfo) error {
+	if !fi.IsDir() {
+		return c.copyFile(dstPath, srcPath, fi)
+	}
+
+	dfi, err := c.dstStat(dstPath)
+	switch {
+	case os.IsNotExist(err):
+		if err = c.dstMkdir(dstPath, fi.Mode().Perm()|0700); err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	case !dfi.IsDir():
+		return &os.PathError{Op: "copy", Path: dstPath, Err: ErrDestinationExists}
+	}
+
+	list, err := c.srcReadDir(srcPath)
+	if err != nil {
+		return err
+	}
+	for _, cfi := range list {
+		if err = c.copy(c.dstJoin(dstPath, cfi.Name()), c.srcJoin(srcPath, cfi.Name()), cfi); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *copier) copyFile(dstPath, srcPath string, fi os.FileInfo) error {
+	if dfi, err := c.dstStat(dstPath); err == nil {
+		switch {
+		case dfi.IsDir() || c.opts.Conflict == CopyFail:
+			return &os.PathError{Op: "copy", Path: dstPath, Err: ErrDestinationExists}
+		case c.opts.Conflict == CopySkip:
+			c.report(CopyProgress{Src: srcPath, Dst: dstPath, Skipped: true})
+			return nil
+		}
+	}
+
+	sf, err := c.srcOpen(srcPath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = sf.Close() }()
+
+	df, err := c.dstOpenFile(dstPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, fi.Mode().Perm()|0200)
+	if err != nil {
+		return err
+	}
+
+	w, ok := df.(io.Writer)
+	if !ok {
+		_ = df.Close()
+		return &os.PathError{Op: "write", Path: dstPath, Err: ErrReadOnly}
+	}
+
+	n, err := io.Copy(w, sf)
+	if cerr := df.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	c.files++
+	c.total += n
+	c.report(CopyProgress{Src: srcPath, Dst: dstPath, Bytes: n})
+	return nil
+}
+
+func (c *copier) report(p CopyProgress) {
+	if c.opts.Progress != nil {
+		p.Files = c.files
+		p.TotalBytes = c.total
+		c.opts.Progress(p)
+	}
+}
+
+func (c *copier) srcStat(name string) (os.FileInfo, error) {
+	if c.src == nil {
+		return os.Stat(name)
+	}
+	return c.src.Stat(name)
+}
+
+func (c *copier) srcReadDir(dirname string) ([]os.FileInfo, error) {
+	if c.src == nil {
+		return ioutil.ReadDir(dirname)
+	}
+	return c.src.ReadDir(dirname)
+}
+
+func (c *copier) srcOpen(name string) (File, error) {
+	if c.src == nil {
+		return os.Open(name)
+	}
+	return c.src.Open(name)
+}
+
+func (c *copier) srcJoin(elem ...string) string {
+	if c.src == nil {
+		return filepath.Join(elem...)
+	}
+	return path.Join(elem...)
+}
+
+func (c *copier) dstStat(name string) (os.FileInfo, error) {
+	if c.dst == nil {
+		return os.Stat(name)
+	}
+	return c.dst.Stat(name)
+}
+
+func (c *copier) dstMkdir(name string, perm os.FileMode) error {
+	if c.dst == nil {
+		return os.Mkdir(name, perm)
+	}
+	return c.dst.Mkdir(name, perm)
+}
+
+func (c *copier) dstOpenFile(name string, flag int, perm os.FileMode) (File, error) {
+	if c.dst == nil {
+		return os.OpenFile(name, flag, perm)
+	}
+	return c.dst.OpenFile(name, flag, perm)
+}
+
+func (c *copier) dstJoin(elem ...string) string {
+	if c.dst == nil {
+		return filepath.Join(elem...)
+	}
+	return path.Join(elem...)
+}
@@ -0,0 +1,71 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"aahframework.org/test.v0/assert"
+)
+
+func TestVFSCopy(t *testing.T) {
+	fs := createVFS(t)
+
+	// embedded => memfs
+	mfs := NewMemFS()
+	var progress []CopyProgress
+	err := CopyWithOptions(mfs, "/config", fs, "/app/config", CopyOptions{
+		Progress: func(p CopyProgress) { progress = append(progress, p) },
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(progress))
+	assert.Equal(t, "/app/config/aah.conf", progress[0].Src)
+	assert.Equal(t, "/config/env/dev.conf", progress[1].Dst)
+	assert.Equal(t, 5, progress[4].Files)
+
+	for _, name := range []string{"aah.conf", "env/dev.conf", "env/prod.conf", "routes.conf", "security.conf"} {
+		expected, err := ioutil.ReadFile(filepath.Join(testdataBaseDir(), "vfstest", "config", name))
+		assert.Nil(t, err)
+		data, err := mfs.ReadFile("/config/" + name)
+		assert.Nil(t, err)
+		assert.Equal(t, string(expected), string(data))
+	}
+
+	// conflict policies
+	progress = nil
+	err = CopyWithOptions(mfs, "/config", fs, "/app/config", CopyOptions{
+		Conflict: CopySkip,
+		Progress: func(p CopyProgress) { progress = append(progress, p) },
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(progress))
+	assert.True(t, progress[0].Skipped)
+	assert.Equal(t, 0, progress[4].Files)
+
+	err = CopyWithOptions(mfs, "/config", fs, "/app/config", CopyOptions{Conflict: CopyFail})
+	assert.Equal(t, &os.PathError{Op: "copy", Path: "/config/aah.conf", Err: ErrDestinationExists}, err)
+
+	// memfs => physical
+	dir, err := ioutil.TempDir("", "vfs-copy")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	assert.Nil(t, Copy(nil, filepath.Join(dir, "config"), mfs, "/config"))
+	data, err := ioutil.ReadFile(filepath.Join(dir, "config", "env", "prod.conf"))
+	assert.Nil(t, err)
+	expected, err := mfs.ReadFile("/config/env/prod.conf")
+	assert.Nil(t, err)
+	assert.Equal(t, string(expected), string(data))
+
+	// overwrite read-only copies, then single file
+	assert.Nil(t, Copy(nil, filepath.Join(dir, "config"), fs, "/app/config"))
+	assert.Nil(t, Copy(mfs, "/robots.txt", nil, filepath.Join(testdataBaseDir(), "vfstest", "static", "robots.txt")))
+	assert.True(t, mfs.IsExists("/robots.txt"))
+
+	assert.True(t, os.IsNotExist(Copy(mfs, "/x", nil, filepath.Join(dir, "not-exists"))))
+}
@@ -0,0 +1,127 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"os"
+)
+
+// Decryption errors
+var (
+	ErrNoDecryptionKey = errors.New("vfs: encrypted file, decryption key is not set")
+	ErrDecryption      = errors.New("vfs: file decryption failed, invalid key or data")
+)
+
+// SetDecryptionKey method sets the AES key of encrypted files of the mount,
+// see `BinaryOptions.EncryptionKey`. Key is 16, 24 or 32 bytes to select
+// AES-128, AES-192 or AES-256, it has to be the key used at generation.
+// Encrypted file data stays encrypted in memory and its decrypted on each
+// open, nil key removes the key.
+func (m *Mount) SetDecryptionKey(key []byte) error {
+	var gcm cipher.AEAD
+	if key != nil {
+		var err error
+		if gcm, err = newGCM(key); err != nil {
+			return &os.PathError{Op: "setdecryptionkey", Path: m.Vroot, Err: err}
+		}
+	}
+	m.aead.Store(aeadHolder{gcm})
+	return nil
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Crypt unexported types and methods
+//______________________________________________________________________________
+
+// aeadHolder wraps the cipher, since `atomic.Value` does not store nil.
+type aeadHolder struct {
+	gcm cipher.AEAD
+}
+
+// encryptedInfo is implemented by the info of virtual node, see
+// `NodeInfo.Encrypted`.
+type encryptedInfo interface {
+	isEncrypted() bool
+}
+
+func (n NodeInfo) isEncrypted() bool {
+	return n.Encrypted
+}
+
+// gzipInfo is implemented by the info of virtual node, see `NodeInfo.Gzip`.
+type gzipInfo interface {
+	isGzipData() bool
+}
+
+func (n NodeInfo) isGzipData() bool {
+	return n.Gzip
+}
+
+// rawInfo is implemented by the info of virtual node, see `NodeInfo.Raw`.
+type rawInfo interface {
+	isRawData() bool
+}
+
+func (n NodeInfo) isRawData() bool {
+	return n.Raw
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encryptData method seals the data with AES-GCM, result is nonce followed
+// by ciphertext. Nonce is derived from key and data via HMAC-SHA256, so that
+// generated output is reproducible; same data yields same ciphertext.
+func encryptData(gcm cipher.AEAD, key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write(data)
+	nonce := mac.Sum(nil)[:gcm.NonceSize()]
+	return gcm.Seal(nonce, nonce, data, nil)
+}
+
+// decryptData method opens the data sealed by `encryptData`.
+func decryptData(gcm cipher.AEAD, data []byte) ([]byte, error) {
+	if len(data) < gcm.NonceSize() {
+		return nil, ErrDecryption
+	}
+	nonce := data[:gcm.NonceSize()]
+	plain, err := gcm.Open(nil, nonce, data[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, ErrDecryption
+	}
+	return plain, nil
+}
+
+// decrypt method returns the decrypted data of encrypted node.
+func (m *Mount) decrypt(n *node) ([]byte, error) {
+	h, _ := m.aead.Load().(aeadHolder)
+	if h.gcm == nil {
+		return nil, ErrNoDecryptionKey
+	}
+	return decryptData(h.gcm, n.data)
+}
+
+// openDecrypted method returns the file of encrypted node with its data
+// decrypted, so that gzip and encoded variants are served as usual.
+func (m *Mount) openDecrypted(f *file) (*file, error) {
+	data, err := m.decrypt(f.node)
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: f.node.Path, Err: err}
+	}
+	n := newNode(f.node.Path, f.node)
+	n.Encrypted = false
+	n.data = data
+	return newFile(n), nil
+}
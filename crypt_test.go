@@ -0,0 +1,84 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"aahframework.org/test.v0/assert"
+)
+
+func TestVFSEncryptedPack(t *testing.T) {
+	src := filepath.Join(testdataBaseDir(), "vfstest", "static")
+	key := []byte("0123456789abcdef0123456789abcdef")
+	var buf bytes.Buffer
+	assert.Nil(t, Pack(&buf, "/app/static", src, nil, BinaryOptions{EncryptionKey: key}))
+	assert.False(t, bytes.Contains(buf.Bytes(), []byte("User-agent")))
+
+	// reproducible output
+	var again bytes.Buffer
+	assert.Nil(t, Pack(&again, "/app/static", src, nil, BinaryOptions{EncryptionKey: key}))
+	assert.Equal(t, buf.Bytes(), again.Bytes())
+
+	m, err := LoadPack(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.FailNowOnError(t, err, "")
+	fi, err := m.Stat("/app/static/robots.txt")
+	assert.Nil(t, err)
+	assert.True(t, fi.(encryptedInfo).isEncrypted())
+
+	_, err = m.Open("/app/static/robots.txt")
+	assert.Equal(t, ErrNoDecryptionKey, err.(*os.PathError).Err)
+	assert.False(t, m.Verify().OK())
+
+	assert.NotNil(t, m.SetDecryptionKey([]byte("short")))
+	assert.Nil(t, m.SetDecryptionKey([]byte("fedcba9876543210fedcba9876543210")))
+	_, err = m.ReadFile("/app/static/robots.txt")
+	assert.Equal(t, ErrDecryption, err.(*os.PathError).Err)
+
+	assert.Nil(t, m.SetDecryptionKey(key))
+	for _, name := range []string{"robots.txt", "css/aah.css", "img/aah-framework-logo.png"} {
+		expected, err := ioutil.ReadFile(filepath.Join(src, filepath.FromSlash(name)))
+		assert.Nil(t, err)
+		data, err := m.ReadFile("/app/static/" + name)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, data)
+	}
+	f, err := m.Open("/app/static/css/aah.css")
+	assert.Nil(t, err)
+	assert.True(t, f.(*file).IsGzip())
+	assert.Nil(t, f.Close())
+	assert.True(t, m.Verify().OK())
+
+	assert.Nil(t, m.SetDecryptionKey(nil))
+	_, err = m.ReadFile("/app/static/robots.txt")
+	assert.NotNil(t, err)
+}
+
+func TestVFSBinaryEncrypted(t *testing.T) {
+	src := filepath.Join(testdataBaseDir(), "vfstest", "static")
+	key := []byte("0123456789abcdef")
+	code, err := BinaryWithOptions("/app/static", src, nil, BinaryOptions{
+		EncryptionKey: key,
+		Encoders:      map[string]func([]byte) ([]byte, error){"identity": func(b []byte) ([]byte, error) { return b[:1], nil }},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 5, bytes.Count(code, []byte(`Encrypted: true`)))
+	assert.False(t, bytes.Contains(code, []byte("AddEncoded")))
+
+	gcm, err := newGCM(key)
+	assert.FailNowOnError(t, err, "")
+	expected, err := ioutil.ReadFile(filepath.Join(src, "robots.txt"))
+	assert.Nil(t, err)
+	data, err := decryptData(gcm, parseBinaryFiles(t, code)["/app/static/robots.txt"])
+	assert.Nil(t, err)
+	assert.Equal(t, expected, data)
+
+	_, err = BinaryWithOptions("/app/static", src, nil, BinaryOptions{EncryptionKey: []byte("short")})
+	assert.NotNil(t, err)
+}
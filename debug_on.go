@@ -0,0 +1,11 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+//go:build vfsdebug
+// +build vfsdebug
+
+package vfs
+
+// debugMode enables the runtime consistency checks, see `Mount.Validate`.
+const debugMode = true
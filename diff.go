@@ -0,0 +1,176 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Change kinds reported by `vfs.Diff`.
+const (
+	ChangeAdded    = "added"
+	ChangeRemoved  = "removed"
+	ChangeModified = "modified"
+)
+
+// Change struct represents the difference of a path between the virtual
+// tree of mount and its physical root.
+type Change struct {
+	// Path is the virtual path.
+	Path string
+
+	// Kind is the change kind of physical path relative to virtual node, for
+	// e.g.: `ChangeAdded` means path exists only in physical root.
+	Kind string
+
+	// Detail describes the modification, for e.g.: `size 10 => 12`. It is
+	// empty for added and removed paths.
+	Detail string
+}
+
+// String method Stringer interface.
+func (c Change) String() string {
+	return fmt.Sprintf("change(path=%s kind=%s detail=%s)", c.Path, c.Kind, c.Detail)
+}
+
+// Diff method compares the virtual tree of mount, i.e. embedded nodes,
+// against its physical root and returns the changes sorted by path, nil
+// means embedded nodes are up-to-date. It is meant for "embedded assets are
+// stale, regenerate" warning in development mode.
+//
+// File is modified if its type, size or symbolic link target differs, then
+// SHA-256 checksum is compared if the node has it otherwise modification
+// time. Directory is compared only on existence and type.
+func Diff(m *Mount) ([]Change, error) {
+	if m.Proot == "" {
+		return nil, &os.PathError{Op: "diff", Path: m.Vroot, Err: errors.New("mount has no physical root")}
+	}
+
+	physical := make(map[string]os.FileInfo)
+	err := filepath.Walk(m.Proot, func(fpath string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		physical[m.physicalToVirtual(fpath)] = fi
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	virtual := make(map[string]*NodeInfo)
+	m.treeMu.RLock()
+	if m.tree != nil {
+		collectNodeInfos(m.tree, virtual)
+	}
+	m.treeMu.RUnlock()
+
+	var changes []Change
+	for name, ni := range virtual {
+		fi, found := physical[name]
+		if !found {
+			changes = append(changes, Change{Path: name, Kind: ChangeRemoved})
+			continue
+		}
+		detail, err := diffDetail(m, name, ni, fi)
+		if err != nil {
+			return nil, err
+		}
+		if detail != "" {
+			changes = append(changes, Change{Path: name, Kind: ChangeModified, Detail: detail})
+		}
+	}
+	for name := range physical {
+		if _, found := virtual[name]; !found {
+			changes = append(changes, Change{Path: name, Kind: ChangeAdded})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Diff unexported methods
+//______________________________________________________________________________
+
+// collectNodeInfos method adds the info of node and its descendants into
+// infos by path, caller holds the tree lock.
+func collectNodeInfos(n *node, infos map[string]*NodeInfo) {
+	infos[n.Path] = n.NodeInfo
+	for _, c := range n.childs {
+		collectNodeInfos(c, infos)
+	}
+}
+
+// diffDetail method returns the description of modification between virtual
+// node and physical file info, empty if not modified.
+func diffDetail(m *Mount, name string, ni *NodeInfo, fi os.FileInfo) (string, error) {
+	vtype, ptype := nodeType(ni.IsDir(), ni.Symlink != ""), nodeType(fi.IsDir(), fi.Mode()&os.ModeSymlink != 0)
+	switch {
+	case vtype != ptype:
+		return fmt.Sprintf("type %s => %s", vtype, ptype), nil
+	case ni.IsDir():
+		return "", nil
+	case ni.Symlink != "":
+		target, err := os.Readlink(m.toPhysicalPath(name))
+		if err != nil {
+			return "", err
+		}
+		if filepath.ToSlash(target) != ni.Symlink {
+			return fmt.Sprintf("target %s => %s", ni.Symlink, target), nil
+		}
+		return "", nil
+	case ni.Size() != fi.Size():
+		return fmt.Sprintf("size %d => %d", ni.Size(), fi.Size()), nil
+	}
+
+	if ni.SHA256 != "" {
+		sum, err := physicalChecksum(m.toPhysicalPath(name))
+		if err != nil {
+			return "", err
+		}
+		if sum != ni.SHA256 {
+			return fmt.Sprintf("checksum %s => %s", ni.SHA256, sum), nil
+		}
+		return "", nil
+	}
+	if !ni.ModTime().Equal(fi.ModTime()) {
+		return fmt.Sprintf("modtime %v => %v", ni.ModTime(), fi.ModTime()), nil
+	}
+	return "", nil
+}
+
+func nodeType(dir, symlink bool) string {
+	switch {
+	case symlink:
+		return "symlink"
+	case dir:
+		return "directory"
+	}
+	return "file"
+}
+
+// physicalChecksum method returns the hex SHA-256 checksum of physical file.
+func physicalChecksum(fpath string) (string, error) {
+	f, err := os.Open(fpath)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
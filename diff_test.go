@@ -0,0 +1,109 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"aahframework.org/test.v0/assert"
+)
+
+func TestVFSDiff(t *testing.T) {
+	fs := createVFS(t)
+	m, err := fs.FindMount("/app")
+	assert.Nil(t, err)
+	changes, err := Diff(m)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(changes))
+
+	pdir, err := ioutil.TempDir("", "vfs-diff")
+	assert.FailNowOnError(t, err, "")
+	defer func() { _ = os.RemoveAll(pdir) }()
+	assert.Nil(t, os.Mkdir(filepath.Join(pdir, "config"), 0755))
+	files := map[string]string{
+		"config/aah.conf":    "name = \"app\"",
+		"config/routes.conf": "routes {a}",
+		"config/env.conf":    "env = dev",
+	}
+	for name, content := range files {
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(pdir, name), []byte(content), 0644))
+	}
+
+	em, err := NewMount("/app", pdir)
+	assert.Nil(t, err)
+	err = filepath.Walk(pdir, func(fpath string, fi os.FileInfo, err error) error {
+		ni := &NodeInfo{Dir: fi.IsDir(), Path: em.toVirtualPath(fpath), Time: fi.ModTime()}
+		if fi.IsDir() {
+			return em.AddDir(ni)
+		}
+		data, err := ioutil.ReadFile(fpath)
+		if err != nil {
+			return err
+		}
+		ni.DataSize = fi.Size()
+		if fi.Name() == "aah.conf" {
+			h := sha256.Sum256(data)
+			ni.SHA256 = hex.EncodeToString(h[:])
+		}
+		return em.AddFile(ni, data)
+	})
+	assert.Nil(t, err)
+
+	changes, err = Diff(em)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(changes))
+
+	// same size edits, checksum and modification time
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(pdir, "config/aah.conf"), []byte("name = \"web\""), 0644))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(pdir, "config/routes.conf"), []byte("routes {b}"), 0644))
+	mtime := time.Now().Add(time.Hour)
+	assert.Nil(t, os.Chtimes(filepath.Join(pdir, "config/routes.conf"), mtime, mtime))
+	assert.Nil(t, os.Remove(filepath.Join(pdir, "config/env.conf")))
+	assert.Nil(t, os.Mkdir(filepath.Join(pdir, "views"), 0755))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(pdir, "views/index.html"), []byte("<html>"), 0644))
+
+	changes, err = Diff(em)
+	assert.Nil(t, err)
+	var kinds []string
+	for _, c := range changes {
+		kinds = append(kinds, c.Kind+" "+c.Path)
+	}
+	assert.Equal(t, []string{
+		"modified /app/config/aah.conf",
+		"removed /app/config/env.conf",
+		"modified /app/config/routes.conf",
+		"added /app/views",
+		"added /app/views/index.html",
+	}, kinds)
+	assert.True(t, strings.HasPrefix(changes[0].Detail, "checksum"))
+	assert.True(t, strings.HasPrefix(changes[2].Detail, "modtime"))
+
+	// physical root lies under virtual root path
+	vroot := filepath.ToSlash(filepath.Dir(pdir))
+	um, err := NewMount(vroot, pdir)
+	assert.Nil(t, err)
+	assert.Nil(t, um.AddDir(&NodeInfo{Dir: true, Path: vroot + "/views"}))
+	changes, err = Diff(um)
+	assert.Nil(t, err)
+	kinds = nil
+	for _, c := range changes {
+		kinds = append(kinds, c.Kind+" "+c.Path)
+	}
+	assert.Equal(t, []string{"added " + vroot + "/config", "added " + vroot + "/config/aah.conf",
+		"added " + vroot + "/config/routes.conf", "added " + vroot + "/views/index.html"}, kinds)
+
+	// no physical root
+	vm, err := NewMount("/app", "")
+	assert.Nil(t, err)
+	_, err = Diff(vm)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,65 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+//go:build go1.16
+// +build go1.16
+
+package vfs
+
+import (
+	"embed"
+	"io/fs"
+	"path"
+)
+
+// NewEmbedMount method creates the mount of `embed.FS` directory subdir at
+// given virtual root, for e.g.:
+//
+//	//go:embed static views
+//	var assets embed.FS
+//
+//	m, err := vfs.NewEmbedMount("/app/static", assets, "static")
+//
+// Tree is populated from embed.FS directory entries, file data is read on
+// first read and kept in memory. Value "" or "." of subdir mounts the embed.FS
+// root. Embedded files do not have modification time, so it is zero time.
+// File data is mounted as-is, embedded gzip file is not decompressed on read.
+func NewEmbedMount(vroot string, efs embed.FS, subdir string, opts ...MountOption) (*Mount, error) {
+	if subdir == "" {
+		subdir = "."
+	}
+	sub, err := fs.Sub(efs, subdir)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := newMount(vroot, "", opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	err = fs.WalkDir(sub, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || name == "." {
+			return err
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		ni := &NodeInfo{Dir: fi.IsDir(), Path: path.Join(m.Vroot, name), Time: fi.ModTime()}
+		if ni.Dir {
+			return m.AddDir(ni)
+		}
+		ni.DataSize, ni.Raw = fi.Size(), true
+		return m.addSourceNode(ni, func() ([]byte, error) {
+			return fs.ReadFile(sub, name)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	m.sealReadOnly()
+	return m, nil
+}
@@ -0,0 +1,71 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+//go:build go1.16
+// +build go1.16
+
+package vfs
+
+import (
+	"embed"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"aahframework.org/test.v0/assert"
+)
+
+//go:embed testdata/vfstest/config testdata/vfstest/views testdata/raw
+var testEmbedFS embed.FS
+
+func TestVFSEmbedMount(t *testing.T) {
+	m, err := NewEmbedMount("/app", testEmbedFS, "testdata/vfstest")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(m.Validate()))
+
+	data, err := m.ReadFile("/app/config/env/dev.conf")
+	assert.Nil(t, err)
+	expected, err := ioutil.ReadFile("testdata/vfstest/config/env/dev.conf")
+	assert.Nil(t, err)
+	assert.Equal(t, expected, data)
+
+	fi, err := m.Lstat("/app/views/errors")
+	assert.Nil(t, err)
+	assert.True(t, fi.IsDir())
+
+	matches, err := m.Glob("/app/config/env/*.conf")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/app/config/env/dev.conf", "/app/config/env/prod.conf"}, matches)
+
+	assert.True(t, m.IsExists("/app/views/layouts/master.html"))
+	assert.False(t, m.IsExists("/app/static"))
+
+	fs := new(VFS)
+	assert.Nil(t, fs.AttachMount(m))
+	data, err = fs.ReadFile("/app/config/env/dev.conf")
+	assert.Nil(t, err)
+	assert.Equal(t, expected, data)
+	assert.NotNil(t, fs.AttachMount(m))
+
+	m, err = NewEmbedMount("/assets", testEmbedFS, "")
+	assert.Nil(t, err)
+	assert.True(t, m.IsExists("/assets/testdata/vfstest/config/aah.conf"))
+
+	// gzip file as-is
+	m, err = NewEmbedMount("/assets", testEmbedFS, "testdata/raw")
+	assert.Nil(t, err)
+	expected, err = ioutil.ReadFile("testdata/raw/app.js.gz")
+	assert.Nil(t, err)
+	fi, err = m.Stat("/assets/app.js.gz")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(len(expected)), fi.Size())
+	data, err = m.ReadFile("/assets/app.js.gz")
+	assert.Nil(t, err)
+	assert.Equal(t, expected, data)
+
+	_, err = NewEmbedMount("/app", testEmbedFS, "../testdata")
+	assert.NotNil(t, err)
+	_, err = NewEmbedMount("/app", testEmbedFS, "testdata/not-exists")
+	assert.True(t, os.IsNotExist(err))
+}
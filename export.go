@@ -0,0 +1,95 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+)
+
+// ExportFormat type is used to specify the asset listing format of
+// `vfs.ExportAssets`.
+type ExportFormat uint8
+
+// Asset listing formats
+const (
+	ExportJSON ExportFormat = iota
+	ExportTypeScript
+)
+
+// fingerprintLen is no. of hex chars of SHA-256 used as fingerprint.
+const fingerprintLen = 16
+
+// ExportAssets method writes the listing of files under root of fs, virtual
+// path => fingerprint (hex prefix of content SHA-256), for frontend build
+// tooling. So that single page application references the server embedded
+// assets without duplicating the list. For e.g.: TypeScript module
+//
+//	export const assets: { readonly [path: string]: string } = {
+//	  "/static/css/aah.css": "3f2a9c1b0e7d4a55",
+//	};
+//
+// Entries are in lexical order of path, content is same for same assets.
+func ExportAssets(w io.Writer, fs FileSystem, root string, format ExportFormat) error {
+	root = path.Clean("/" + root)
+	info, err := fs.Lstat(root)
+	if err != nil {
+		return err
+	}
+
+	var paths, fingerprints []string
+	err = walk(fs, root, info, func(fpath string, fi os.FileInfo, err error) error {
+		if err != nil || fi.IsDir() {
+			return err
+		}
+		data, err := fs.ReadFile(fpath)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		paths = append(paths, fpath)
+		fingerprints = append(fingerprints, hex.EncodeToString(sum[:])[:fingerprintLen])
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	if format == ExportTypeScript {
+		fmt.Fprint(bw, "// Code generated by aah vfs, DO NOT EDIT.\n\n")
+		fmt.Fprint(bw, "export const assets: { readonly [path: string]: string } = {\n")
+		for i, p := range paths {
+			fmt.Fprintf(bw, "  %s: %q,\n", jsonString(p), fingerprints[i])
+		}
+		fmt.Fprint(bw, "};\n")
+	} else {
+		fmt.Fprint(bw, "{")
+		for i, p := range paths {
+			if i > 0 {
+				fmt.Fprint(bw, ",")
+			}
+			fmt.Fprintf(bw, "\n  %s: %q", jsonString(p), fingerprints[i])
+		}
+		if len(paths) > 0 {
+			fmt.Fprint(bw, "\n")
+		}
+		fmt.Fprint(bw, "}\n")
+	}
+	return bw.Flush()
+}
+
+// jsonString method returns the JSON string literal of s, it is valid
+// TypeScript string literal too.
+func jsonString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
@@ -0,0 +1,49 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"aahframework.org/test.v0/assert"
+)
+
+func TestVFSExportAssets(t *testing.T) {
+	fs := createVFS(t)
+
+	buf := new(bytes.Buffer)
+	assert.Nil(t, ExportAssets(buf, fs, "/app/static", ExportJSON))
+	var assets map[string]string
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &assets))
+	assert.Equal(t, 5, len(assets))
+
+	data, err := ioutil.ReadFile(filepath.Join(testdataBaseDir(), "vfstest", "static", "css", "aah.css"))
+	assert.Nil(t, err)
+	sum := sha256.Sum256(data)
+	assert.Equal(t, hex.EncodeToString(sum[:])[:16], assets["/app/static/css/aah.css"])
+
+	buf.Reset()
+	assert.Nil(t, ExportAssets(buf, fs, "/app/static/css", ExportTypeScript))
+	assert.Equal(t, "// Code generated by aah vfs, DO NOT EDIT.\n\n"+
+		"export const assets: { readonly [path: string]: string } = {\n"+
+		"  \"/app/static/css/aah.css\": \""+assets["/app/static/css/aah.css"]+"\",\n"+
+		"};\n", buf.String())
+
+	buf.Reset()
+	mfs := NewMemFS()
+	assert.Nil(t, mfs.Mkdir("/empty", 0755))
+	assert.Nil(t, ExportAssets(buf, mfs, "/empty", ExportJSON))
+	assert.Equal(t, "{}\n", buf.String())
+
+	err = ExportAssets(buf, fs, "/app/not-exists", ExportJSON)
+	assert.True(t, strings.Contains(err.Error(), "not-exists"))
+}
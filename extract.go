@@ -0,0 +1,102 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Extract method writes the tree of root from fs into physical directory
+// destDir, for e.g.: embedded `/app/views` into `/tmp/app/views`. Gzip
+// virtual files are written decompressed and modification times of files
+// and directories are preserved. Symbolic link to a file is written with its
+// target content, symbolic link to a directory is skipped, same as
+// `vfs.WriteTar`.
+//
+// destDir and missing parents are created, existing files are overwritten.
+// Files are created with source permission plus owner write, directories
+// with source permission plus owner full access.
+func Extract(fs FileSystem, root, destDir string) error {
+	root, err := cleanPath("extract", root)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(destDir, 0755); err != nil {
+		return err
+	}
+
+	var dirs []extractedDir
+	err = Walk(fs, root, func(fpath string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.Mode()&os.ModeSymlink != 0 {
+			if fi, err = fs.Stat(fpath); err != nil {
+				return err
+			}
+			if fi.IsDir() {
+				return nil
+			}
+		}
+
+		dst := filepath.Join(destDir, filepath.FromSlash(strings.TrimPrefix(fpath, root)))
+		if fi.IsDir() {
+			if err = os.MkdirAll(dst, fi.Mode().Perm()|0700); err != nil {
+				return err
+			}
+			dirs = append(dirs, extractedDir{name: dst, modTime: fi.ModTime()})
+			return nil
+		}
+		return extractFile(fs, fpath, dst, fi)
+	})
+	if err != nil {
+		return err
+	}
+
+	// directory time is set last, since writing of its entries modifies it
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err = os.Chtimes(dirs[i].name, dirs[i].modTime, dirs[i].modTime); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Extract unexported types and methods
+//______________________________________________________________________________
+
+type extractedDir struct {
+	name    string
+	modTime time.Time
+}
+
+// extractFile method writes the content of virtual file name into physical
+// file dst and sets its modification time.
+func extractFile(fs FileSystem, name, dst string, fi os.FileInfo) error {
+	sf, err := fs.Open(path.Clean(name))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = sf.Close() }()
+
+	df, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, fi.Mode().Perm()|0200)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(df, sf)
+	if cerr := df.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Chtimes(dst, fi.ModTime(), fi.ModTime())
+}
@@ -0,0 +1,66 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"aahframework.org/test.v0/assert"
+)
+
+func TestVFSExtract(t *testing.T) {
+	fs := createVFS(t)
+	destDir, err := ioutil.TempDir("", "vfs-extract")
+	assert.FailNowOnError(t, err, "")
+	defer func() { _ = os.RemoveAll(destDir) }()
+
+	assert.Nil(t, Extract(fs, "/app/config", destDir))
+	for _, name := range []string{"aah.conf", "env/dev.conf", "env/prod.conf", "routes.conf", "security.conf"} {
+		expected, err := fs.ReadFile("/app/config/" + name)
+		assert.Nil(t, err)
+		data, err := ioutil.ReadFile(filepath.Join(destDir, filepath.FromSlash(name)))
+		assert.Nil(t, err)
+		assert.Equal(t, string(expected), string(data))
+
+		vfi, err := fs.Stat("/app/config/" + name)
+		assert.Nil(t, err)
+		pfi, err := os.Stat(filepath.Join(destDir, filepath.FromSlash(name)))
+		assert.Nil(t, err)
+		assert.Equal(t, vfi.ModTime().Unix(), pfi.ModTime().Unix())
+	}
+	vfi, err := fs.Stat("/app/config/env")
+	assert.Nil(t, err)
+	pfi, err := os.Stat(filepath.Join(destDir, "env"))
+	assert.Nil(t, err)
+	assert.Equal(t, vfi.ModTime().Unix(), pfi.ModTime().Unix())
+
+	// gzip node is written decompressed
+	mtime := time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+	content := strings.Repeat("generated view line\n", 100)
+	m, err := NewMount("/app", "", CompressAdded(gzip.BestSpeed))
+	assert.Nil(t, err)
+	assert.Nil(t, m.AddDir(&NodeInfo{Dir: true, Path: "/app/views", Time: mtime}))
+	assert.Nil(t, m.AddFileFromReader("/app/views/gen.html", &NodeInfo{Time: mtime}, strings.NewReader(content)))
+	gzDir := filepath.Join(destDir, "gz")
+	assert.Nil(t, Extract(m, "/app", gzDir))
+	data, err := ioutil.ReadFile(filepath.Join(gzDir, "views", "gen.html"))
+	assert.Nil(t, err)
+	assert.Equal(t, content, string(data))
+	pfi, err = os.Stat(filepath.Join(gzDir, "views", "gen.html"))
+	assert.Nil(t, err)
+	assert.True(t, mtime.Equal(pfi.ModTime()))
+	pfi, err = os.Stat(filepath.Join(gzDir, "views"))
+	assert.Nil(t, err)
+	assert.True(t, mtime.Equal(pfi.ModTime()))
+
+	// not exists
+	assert.True(t, os.IsNotExist(Extract(fs, "/app/notexists", destDir)))
+}
@@ -13,14 +13,30 @@ import (
 
 var _ File = (*file)(nil)
 var _ Gziper = (*file)(nil)
+var _ Compressed = (*file)(nil)
+var _ io.ReaderAt = (*file)(nil)
+var _ File = (*physicalFile)(nil)
+var _ io.ReaderAt = (*physicalFile)(nil)
 
 // File struct represents the virtual file or directory.
 //
+// Each open creates new file with its own read offset and directory listing
+// position over the node data shared by all the opens, data is never
+// modified once added. So the files of same node can be read concurrently
+// without coordination, however single file is not safe for concurrent use,
+// same as `os.File`.
+//
 // Implements interface `vfs.File`.
 type file struct {
 	*node
-	rs  io.ReadSeeker
-	pos int
+	rs      io.ReadSeeker
+	pos     int
+	onClose func()
+
+	// directory entries and encoded variants taken on open, they shadow
+	// the node fields
+	childInfos []os.FileInfo
+	encoded    map[string][]byte
 }
 
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
@@ -35,20 +51,39 @@ func (f *file) Seek(offset int64, whence int) (int64, error) {
 	return f.rs.Seek(offset, whence)
 }
 
+// ReadAt method behaviour is same as `os.File.ReadAt`, it does not affect
+// the read offset of file. Node data is served in-place, gzip data is
+// decompressed once on first call.
+func (f *file) ReadAt(b []byte, off int64) (int, error) {
+	if f.IsDir() {
+		return 0, &os.PathError{Op: "read", Path: f.NodeInfo.Path, Err: errors.New("is a directory")}
+	}
+	if off < 0 {
+		return 0, &os.PathError{Op: "readat", Path: f.NodeInfo.Path, Err: errors.New("negative offset")}
+	}
+	return f.rs.(io.ReaderAt).ReadAt(b, off)
+}
+
+// EncodedBytes method returns the bytes of given content encoding, see
+// `vfs.Compressed`.
+func (f *file) EncodedBytes(encoding string) ([]byte, bool) {
+	return encodedBytes(f.data, f.IsGzip(), f.encoded, encoding)
+}
+
 func (f *file) Readdir(count int) ([]os.FileInfo, error) {
 	if !f.IsDir() {
 		return []os.FileInfo{}, &os.PathError{Op: "read", Path: f.NodeInfo.Path, Err: errors.New("vfs: cannot find the specified path")}
 	}
 
-	if f.pos >= len(f.node.childInfos) && count > 0 {
+	if f.pos >= len(f.childInfos) && count > 0 {
 		return nil, io.EOF
 	}
 
-	if count <= 0 || count > len(f.node.childInfos)-f.pos {
-		count = len(f.node.childInfos) - f.pos
+	if count <= 0 || count > len(f.childInfos)-f.pos {
+		count = len(f.childInfos) - f.pos
 	}
 
-	ci := f.node.childInfos[f.pos : f.pos+count]
+	ci := f.childInfos[f.pos : f.pos+count]
 	f.pos += count
 
 	return ci, nil
@@ -73,8 +108,12 @@ func (f *file) Stat() (os.FileInfo, error) {
 }
 
 func (f *file) Close() error {
-	if f.IsGzip() {
-		return f.rs.(io.Closer).Close()
+	if f.onClose != nil {
+		f.onClose()
+		f.onClose = nil
+	}
+	if c, ok := f.rs.(io.Closer); ok {
+		return c.Close()
 	}
 	return nil
 }
@@ -84,3 +123,22 @@ func (f file) String() string {
 	return fmt.Sprintf(`file(name=%s dir=%v gzip=%v size=%v, modtime=%v)`,
 		f.Name(), f.IsDir(), f.IsGzip(), f.Size(), f.ModTime())
 }
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// physicalFile type and methods
+//______________________________________________________________________________
+
+// physicalFile represents the opened file from physical filesystem, it
+// notifies the mount on close for open file accounting.
+type physicalFile struct {
+	*os.File
+	onClose func()
+}
+
+func (f *physicalFile) Close() error {
+	if f.onClose != nil {
+		f.onClose()
+		f.onClose = nil
+	}
+	return f.File.Close()
+}
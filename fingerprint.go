@@ -0,0 +1,140 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// Fingerprint method returns the cache-busting virtual path of given file,
+// fingerprint of file content (same as `vfs.ExportAssets`) is inserted
+// before the extension, for e.g.: `/app/static/css/aah.css` =>
+// `/app/static/css/aah.3f2a9c1b0e7d4a55.css`.
+// So the file can be served with far future cache headers and its URL
+// changes when content changes. Use `Mount.ResolveFingerprint` to map the
+// requested path back to the file.
+//
+// Checksum of the file is used if its known, see `vfs.Checksummer`,
+// otherwise content is hashed once and cached until the file modification
+// time or size changes.
+func (m *Mount) Fingerprint(name string) (string, error) {
+	sum, err := m.fingerprint(name)
+	if err != nil {
+		return "", err
+	}
+
+	name = path.Clean(name)
+	dir, base := path.Split(name)
+	ext := path.Ext(base)
+	if ext == base {
+		ext = "" // dot file, for e.g. .htaccess
+	}
+	return dir + strings.TrimSuffix(base, ext) + "." + sum + ext, nil
+}
+
+// ResolveFingerprint method returns the virtual path of fingerprinted name,
+// see `Mount.Fingerprint`. It returns false if name is not fingerprinted or
+// fingerprint does not match the current file content.
+func (m *Mount) ResolveFingerprint(name string) (string, bool) {
+	name = path.Clean(name)
+	dir, base := path.Split(name)
+
+	// hash is either before the extension or the extension itself
+	ext := path.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	for _, c := range []struct{ original, sum string }{
+		{strings.TrimSuffix(stem, path.Ext(stem)) + ext, strings.TrimPrefix(path.Ext(stem), ".")},
+		{stem, strings.TrimPrefix(ext, ".")},
+	} {
+		if c.original == "" || c.original == ext || !isFingerprint(c.sum) {
+			continue
+		}
+		original := dir + c.original
+		if sum, err := m.fingerprint(original); err == nil && sum == c.sum {
+			return original, true
+		}
+	}
+	return "", false
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Fingerprint unexported types and methods
+//______________________________________________________________________________
+
+// fingerprintEntry is the cached fingerprint of file, its valid until the
+// file modification time or size changes.
+type fingerprintEntry struct {
+	sum     string
+	modTime time.Time
+	size    int64
+}
+
+// fingerprint method returns the fingerprint, hex prefix of SHA-256 checksum
+// of file content.
+func (m *Mount) fingerprint(name string) (string, error) {
+	f, err := m.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	if fi.IsDir() {
+		return "", &os.PathError{Op: "fingerprint", Path: name, Err: errors.New("is a directory")}
+	}
+	if c, ok := fi.(Checksummer); ok {
+		if sum, found := c.Checksum(); found && len(sum) >= fingerprintLen {
+			return sum[:fingerprintLen], nil
+		}
+	}
+
+	key := path.Clean(name)
+	m.fpMu.Lock()
+	e, found := m.fingerprints[key]
+	m.fpMu.Unlock()
+	if found && e.modTime.Equal(fi.ModTime()) && e.size == fi.Size() {
+		return e.sum, nil
+	}
+
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+	e = fingerprintEntry{
+		sum:     hex.EncodeToString(h.Sum(nil))[:fingerprintLen],
+		modTime: fi.ModTime(),
+		size:    fi.Size(),
+	}
+
+	m.fpMu.Lock()
+	if m.fingerprints == nil {
+		m.fingerprints = make(map[string]fingerprintEntry)
+	}
+	m.fingerprints[key] = e
+	m.fpMu.Unlock()
+	return e.sum, nil
+}
+
+func isFingerprint(s string) bool {
+	if len(s) != fingerprintLen {
+		return false
+	}
+	for _, c := range s {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"aahframework.org/test.v0/assert"
+)
+
+func TestVFSMountFingerprint(t *testing.T) {
+	fs := createVFS(t)
+	m, err := fs.FindMount("/app")
+	assert.Nil(t, err)
+
+	data, err := m.ReadFile("/app/static/css/aah.css")
+	assert.Nil(t, err)
+	sum := sha256.Sum256(data)
+	fp := hex.EncodeToString(sum[:])[:16]
+
+	name, err := m.Fingerprint("/app/static/css/aah.css")
+	assert.Nil(t, err)
+	assert.Equal(t, "/app/static/css/aah."+fp+".css", name)
+
+	// same fingerprint as ExportAssets
+	buf := new(bytes.Buffer)
+	assert.Nil(t, ExportAssets(buf, m, "/app/static/css", ExportJSON))
+	var assets map[string]string
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &assets))
+	assert.Equal(t, fp, assets["/app/static/css/aah.css"])
+
+	original, found := m.ResolveFingerprint(name)
+	assert.True(t, found)
+	assert.Equal(t, "/app/static/css/aah.css", original)
+
+	for _, name := range []string{
+		"/app/static/css/aah.css",
+		"/app/static/css/aah.0000000000000000.css",
+		"/app/static/css/not-exists." + fp + ".css",
+		"/app/static/css." + fp,
+	} {
+		_, found = m.ResolveFingerprint(name)
+		assert.False(t, found)
+	}
+
+	// known checksum is used, file without extension
+	assert.Nil(t, m.AddFile(&NodeInfo{Path: "/app/static/LICENSE", DataSize: 3, Time: time.Now(),
+		SHA256: "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"}, []byte("MIT")))
+	name, err = m.Fingerprint("/app/static/LICENSE")
+	assert.Nil(t, err)
+	assert.Equal(t, "/app/static/LICENSE.0123456789abcdef", name)
+	original, found = m.ResolveFingerprint(name)
+	assert.True(t, found)
+	assert.Equal(t, "/app/static/LICENSE", original)
+
+	_, err = m.Fingerprint("/app/static/css")
+	assert.NotNil(t, err)
+	_, err = m.Fingerprint("/app/static/not-exists.css")
+	assert.NotNil(t, err)
+}
+
+func TestVFSMountFingerprintPhysicalChange(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vfs-fingerprint")
+	assert.FailNowOnError(t, err, "")
+	defer func() { _ = os.RemoveAll(dir) }()
+	fpath := filepath.Join(dir, "app.js")
+	assert.FailNowOnError(t, ioutil.WriteFile(fpath, []byte("var v = 1;"), 0644), "")
+
+	m, err := NewMount("/assets", dir)
+	assert.FailNowOnError(t, err, "")
+	name1, err := m.Fingerprint("/assets/app.js")
+	assert.Nil(t, err)
+
+	assert.FailNowOnError(t, ioutil.WriteFile(fpath, []byte("var v = 22;"), 0644), "")
+	name2, err := m.Fingerprint("/assets/app.js")
+	assert.Nil(t, err)
+	assert.NotEqual(t, name1, name2)
+
+	_, found := m.ResolveFingerprint(name1)
+	assert.False(t, found)
+	original, found := m.ResolveFingerprint(name2)
+	assert.True(t, found)
+	assert.Equal(t, "/assets/app.js", original)
+}
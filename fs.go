@@ -6,19 +6,24 @@ package vfs
 
 import (
 	"errors"
+	"io"
 	"os"
 	"path"
 	"path/filepath"
-	"time"
+	"sync"
 )
 
 var _ FileSystem = (*VFS)(nil)
+var _ io.Closer = (*VFS)(nil)
 
 // VFS errors
 var (
-	ErrMountExists    = errors.New("vfs: mount already exists")
-	ErrMountNotExists = errors.New("vfs: mount does not exist")
-	ErrNotAbsolutPath = errors.New("vfs: not a absolute path")
+	ErrMountExists      = errors.New("vfs: mount already exists")
+	ErrMountNotExists   = errors.New("vfs: mount does not exist")
+	ErrNotAbsolutPath   = errors.New("vfs: not a absolute path")
+	ErrReadOnly         = errors.New("vfs: read-only file system")
+	ErrTooManyOpenFiles = errors.New("vfs: too many open files")
+	ErrFileTooLarge     = errors.New("vfs: file too large to read into memory, use Open to stream it")
 )
 
 // VFS represents Virtual FileSystem (VFS), it operates in-memory.
@@ -30,7 +35,14 @@ var (
 // Single point of access for all mounted virtual directories in aah application.
 type VFS struct {
 	embeddedMode bool
+	baseDir      string
+	mountOpts    []MountOption
+	budget       *memoryBudget
+	mu           sync.RWMutex
 	mounts       map[string]*Mount
+	archives     map[string]*archiveMount
+	managed      map[string]string
+	binds        map[string]string
 }
 
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
@@ -39,57 +51,109 @@ type VFS struct {
 
 // Open method behaviour is same as `os.Open`.
 func (v *VFS) Open(name string) (File, error) {
-	m, err := v.FindMount(name)
+	m, name, err := v.resolve("open", name)
 	if err != nil {
 		return nil, err
 	}
-	return m.Open(m.toVirtualPath(name))
+	return m.Open(name)
+}
+
+// OpenFile method behaviour is same as `os.OpenFile`. VFS is Read-Only, so
+// flags other than `os.O_RDONLY` returns `ErrReadOnly`.
+func (v *VFS) OpenFile(name string, flag int, perm os.FileMode) (File, error) {
+	m, name, err := v.resolve("open", name)
+	if err != nil {
+		return nil, err
+	}
+	return m.OpenFile(name, flag, perm)
 }
 
 // Lstat method behaviour is same as `os.Lstat`.
 func (v *VFS) Lstat(name string) (os.FileInfo, error) {
-	m, err := v.FindMount(name)
+	m, vname, err := v.resolve("lstat", name)
 	if err != nil {
 		return nil, err
 	}
-	return m.Lstat(m.toVirtualPath(name))
+	fi, err := m.Lstat(vname)
+	return v.boundInfo(name, fi), err
 }
 
 // Stat method behaviour is same as `os.Stat`
 func (v *VFS) Stat(name string) (os.FileInfo, error) {
-	m, err := v.FindMount(name)
+	m, vname, err := v.resolve("stat", name)
 	if err != nil {
 		return nil, err
 	}
-	return m.Stat(m.toVirtualPath(name))
+	fi, err := m.Stat(vname)
+	return v.boundInfo(name, fi), err
+}
+
+// Readlink method behaviour is same as `os.Readlink`.
+func (v *VFS) Readlink(name string) (string, error) {
+	m, name, err := v.resolve("readlink", name)
+	if err != nil {
+		return "", err
+	}
+	return m.Readlink(name)
 }
 
 // ReadFile method behaviour is same as `ioutil.ReadFile`.
 func (v *VFS) ReadFile(filename string) ([]byte, error) {
-	m, err := v.FindMount(filename)
+	m, filename, err := v.resolve("open", filename)
 	if err != nil {
 		return nil, err
 	}
-	return m.ReadFile(m.toVirtualPath(filename))
+	return m.ReadFile(filename)
 }
 
 // ReadDir method behaviour is same as `ioutil.ReadDir`.
 func (v *VFS) ReadDir(dirname string) ([]os.FileInfo, error) {
-	m, err := v.FindMount(dirname)
+	m, dirname, err := v.resolve("open", dirname)
 	if err != nil {
 		return nil, err
 	}
-	return m.ReadDir(m.toVirtualPath(dirname))
+	return m.ReadDir(dirname)
 }
 
-// Glob method somewhat similar to `filepath.Glob`, since aah vfs does pattern
-// match only on `filepath.Base` value.
+// ReadDirFunc method calls fn for each entry of directory, see
+// `Mount.ReadDirFunc`.
+func (v *VFS) ReadDirFunc(dirname string, fn func(os.FileInfo) error) error {
+	m, dirname, err := v.resolve("open", dirname)
+	if err != nil {
+		return err
+	}
+	return m.ReadDirFunc(dirname, fn)
+}
+
+// Glob method behaviour is same as `filepath.Glob`, it spans all the mounts.
+// Pattern is matched against every mount whose mount path could match and
+// the results are merged, sorted and returned. For e.g.: `/*/i18n/*.ftl`
+//
+// Pattern within the physical root of mount is matched as its virtual path,
+// in case it does not match any virtual path.
 func (v *VFS) Glob(pattern string) ([]string, error) {
-	m, err := v.FindMount(pattern)
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	ppattern := pattern
+	pattern, err := cleanPath("glob", pattern)
 	if err != nil {
 		return nil, err
 	}
-	return m.Glob(m.toVirtualPath(pattern))
+	if b, found := v.bindOf(pattern); found {
+		matches, err := v.glob(b.toSource(pattern))
+		for i, m := range matches {
+			matches[i] = b.toTarget(m)
+		}
+		return matches, err
+	}
+	matches, err := v.glob(pattern)
+	if err == nil && len(matches) == 0 {
+		if vpattern, found := v.physicalToVirtual(ppattern); found {
+			return v.glob(vpattern)
+		}
+	}
+	return matches, err
 }
 
 // IsExists method is helper to find existence.
@@ -112,25 +176,52 @@ func (v *VFS) SetEmbeddedMode() {
 	v.embeddedMode = true
 }
 
-// Walk method behaviour is same as `filepath.Walk`.
-func (v *VFS) Walk(root string, walkFn filepath.WalkFunc) error {
-	m, err := v.FindMount(root)
-	if err != nil {
-		return err
-	}
+// BaseDir method returns the base directory used to resolve relative
+// physical paths, empty string if not set.
+func (v *VFS) BaseDir() string {
+	return v.baseDir
+}
 
-	if m.isTreeEmpty() {
-		// virtual is empty, move on with physical filesystem
-		// Proot := filepath.Join(m.Proot, strings.TrimPrefix(root, m.Vroot))
-		return filepath.Walk(m.toPhysicalPath(root),
-			func(fpath string, fi os.FileInfo, err error) error {
-				return walkFn(m.toVirtualPath(fpath), fi, err)
-			})
+// SetBaseDir method sets the base directory, for e.g. application base
+// directory. Relative physical paths given to `AddMount`, `SyncMounts`,
+// `MountArchives` and `WatchArchives` are resolved against it. Base
+// directory must be absolute path, see `vfs.ExpandPath`.
+func (v *VFS) SetBaseDir(dir string) error {
+	if !filepath.IsAbs(dir) {
+		return ErrNotAbsolutPath
 	}
+	v.baseDir = filepath.Clean(dir)
+	return nil
+}
 
-	info, err := m.Lstat(root)
+// SetMountOptions method sets the options applied to the mounts created by
+// VFS, i.e. `AddMount` and `SyncMounts`. For e.g.: `vfs.TrackAccess(20)`
+func (v *VFS) SetMountOptions(opts ...MountOption) {
+	v.mountOpts = opts
+}
+
+// Walk method behaviour is same as `filepath.Walk`. It traverses all the
+// mounts as one tree in lexical order, root could be any virtual path, for
+// e.g.: `/`. Overlapping path is served by the mount of longest matching
+// mount path, see `VFS.FindMount`. Ancestor directories of mount paths are
+// reported as directories. Physical path within the physical root of mount is
+// walked as its virtual path.
+func (v *VFS) Walk(root string, walkFn filepath.WalkFunc) error {
+	proot := root
+	root = path.Clean("/" + filepath.ToSlash(root))
+	var t walkFileSystem = v.newMountTree()
+	if b, found := v.bindOf(root); found {
+		t = boundTree{t: t, b: b}
+	}
+	info, err := t.Lstat(root)
+	if err != nil {
+		if vroot, found := v.physicalToVirtual(proot); found {
+			root = vroot
+			info, err = t.Lstat(root)
+		}
+	}
 	if err == nil {
-		err = walk(m, root, info, walkFn)
+		err = walk(t, root, info, walkFn)
 	} else {
 		err = walkFn(root, nil, err)
 	}
@@ -145,6 +236,9 @@ func (v *VFS) Walk(root string, walkFn filepath.WalkFunc) error {
 func (v *VFS) Dirs(root string) ([]string, error) {
 	var dirs []string
 	err := v.Walk(root, func(fpath string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
 		if fi.IsDir() {
 			dirs = append(dirs, fpath)
 		}
@@ -157,6 +251,9 @@ func (v *VFS) Dirs(root string) ([]string, error) {
 func (v *VFS) Files(root string) ([]string, error) {
 	var files []string
 	err := v.Walk(root, func(fpath string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
 		if !fi.IsDir() {
 			files = append(files, fpath)
 		}
@@ -165,40 +262,57 @@ func (v *VFS) Files(root string) ([]string, error) {
 	return files, err
 }
 
+// Close method closes all the mounts and its backend resources, it returns
+// the first error occurred. Mounts are closed in the order of mount path.
+func (v *VFS) Close() error {
+	var err error
+	for _, m := range v.sortedMounts() {
+		if cerr := m.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
 // FindMount method finds the mounted virtual directory by mount path.
-// if found then returns `Mount` instance otherwise nil and error.
+// if found then returns `Mount` instance otherwise nil and error. In case of
+// overlapping mounts, mount of longest matching mount path is returned.
 //
 // Mount implements `vfs.FileSystem`, its a combination of package `os` and `ioutil`
 // focused on Read-Only operations.
 func (v *VFS) FindMount(name string) (*Mount, error) {
 	name = path.Clean(name)
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	var found *Mount
 	for _, m := range v.mounts {
-		if m.match(name) {
-			return m, nil
+		if m.match(name) && (found == nil || len(m.Vroot) > len(found.Vroot)) {
+			found = m
 		}
 	}
+	if found != nil {
+		return found, nil
+	}
 	return nil, &os.PathError{Op: "read", Path: name, Err: ErrMountNotExists}
 }
 
 // AddMount method used to mount physical directory as a virtual mounted directory.
+// Physical path is taken as-is, use `vfs.ExpandPath` to expand the
+// environment variables. Relative physical path is resolved against the base
+// directory, see `VFS.SetBaseDir`.
 //
 // Basically aah scans and application source files and builds each file from
 // mounted source directory into binary for single binary build.
 func (v *VFS) AddMount(mountPath, physicalPath string) error {
-	pp := filepath.Clean(physicalPath)
-	if !filepath.IsAbs(physicalPath) {
+	pp := filepath.Clean(v.resolvePath(physicalPath))
+	if !filepath.IsAbs(pp) {
 		return ErrNotAbsolutPath
 	}
 
 	if !v.embeddedMode {
-		fi, err := os.Lstat(pp)
-		if err != nil {
+		if err := checkPhysicalDir(pp); err != nil {
 			return err
 		}
-
-		if !fi.IsDir() {
-			return &os.PathError{Op: "addmount", Path: pp, Err: errors.New("is a file")}
-		}
 	}
 
 	mp := filepath.ToSlash(path.Clean(mountPath))
@@ -207,19 +321,141 @@ func (v *VFS) AddMount(mountPath, physicalPath string) error {
 	}
 	mp = path.Clean("/" + mp)
 
+	m, err := newMount(mp, pp, v.mountOpts...)
+	if err != nil {
+		return err
+	}
+
+	return v.attach(m)
+}
+
+// AttachMount method adds the given mount to VFS at its mount path, for e.g.:
+// mount created via `vfs.NewEmbedMount`, `vfs.NewZipMount` or
+// `vfs.NewTarMount`. It returns `ErrMountExists` if mount path is in use.
+func (v *VFS) AttachMount(m *Mount) error {
+	return v.attach(m)
+}
+
+// Unmount method removes the mount of given mount path and closes it, see
+// `Mount.Close`. Mounts under the mount path are not affected, for e.g.:
+// unmount of `/app` keeps `/app/static`. It returns `ErrMountNotExists` if
+// mount path is not mounted.
+func (v *VFS) Unmount(mountPath string) error {
+	mp := path.Clean("/" + filepath.ToSlash(mountPath))
+	m := v.detach(mp)
+	if m == nil {
+		return &os.PathError{Op: "unmount", Path: mp, Err: ErrMountNotExists}
+	}
+	v.forgetArchive(mp)
+	v.setManaged(mp, "")
+	return m.Close()
+}
+
+// ListMounts method returns the mounts of VFS sorted by mount path.
+func (v *VFS) ListMounts() []*Mount {
+	return v.sortedMounts()
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// VFS unexported methods
+//______________________________________________________________________________
+
+// resolvePath method resolves the given physical path against the base
+// directory if it is relative.
+func (v *VFS) resolvePath(p string) string {
+	if p != "" && !filepath.IsAbs(p) && v.baseDir != "" {
+		p = filepath.Join(v.baseDir, p)
+	}
+	return p
+}
+
+// resolve method cleans the given name and returns the mount serving it
+// along with virtual path within the mount, see `vfs.CleanPath`.
+func (v *VFS) resolve(op, name string) (*Mount, string, error) {
+	name, err := cleanPath(op, name)
+	if err != nil {
+		return nil, "", err
+	}
+	name = v.unbound(name)
+	m, err := v.FindMount(name)
+	if err != nil {
+		return nil, "", err
+	}
+	return m, m.toVirtualPath(name), nil
+}
+
+// physicalToVirtual method returns the virtual path of given physical path
+// within the physical root of mount, false if none of the mounts has it.
+func (v *VFS) physicalToVirtual(name string) (string, bool) {
+	if !filepath.IsAbs(name) {
+		return "", false
+	}
+	name = filepath.Clean(name)
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	var found *Mount
+	for _, m := range v.mounts {
+		if m.hasPhysical() && isPhysicalWithin(name, m.Proot) &&
+			(found == nil || len(m.Proot) > len(found.Proot)) {
+			found = m
+		}
+	}
+	if found == nil {
+		return "", false
+	}
+	return found.physicalToVirtual(name), true
+}
+
+func (v *VFS) attach(m *Mount) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
 	if v.mounts == nil {
 		v.mounts = make(map[string]*Mount)
 	}
 
-	if _, found := v.mounts[mp]; found {
-		return &os.PathError{Op: "addmount", Path: mp, Err: ErrMountExists}
+	if _, found := v.mounts[m.Vroot]; found {
+		return &os.PathError{Op: "addmount", Path: m.Vroot, Err: ErrMountExists}
 	}
-
-	v.mounts[mp] = &Mount{
-		Vroot: mp,
-		Proot: pp,
-		tree:  newNode(mp, &NodeInfo{Dir: true, Time: time.Now().UTC()}),
+	debugValidate(m, m.tree, true)
+	v.mounts[m.Vroot] = m
+	if v.budget != nil {
+		m.setBudget(v.budget)
 	}
 
 	return nil
 }
+
+// replace method puts the given mount in place of existing mount of same
+// mount path and returns the existing mount, if any.
+func (v *VFS) replace(m *Mount) *Mount {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.mounts == nil {
+		v.mounts = make(map[string]*Mount)
+	}
+
+	old := v.mounts[m.Vroot]
+	v.mounts[m.Vroot] = m
+	if v.budget != nil {
+		m.setBudget(v.budget)
+	}
+	if old != nil {
+		old.setBudget(nil)
+	}
+	m.inheritAccess(old)
+	m.inheritInstrumentation(old)
+	return old
+}
+
+// detach method removes the mount of given mount path and returns it,
+// if any.
+func (v *VFS) detach(mountPath string) *Mount {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	m := v.mounts[mountPath]
+	delete(v.mounts, mountPath)
+	if m != nil {
+		m.setBudget(nil)
+	}
+	return m
+}
@@ -0,0 +1,17 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package fusefs mounts any `vfs.FileSystem` read-only via FUSE, so the
+// embedded tree of running binary can be explored with normal shell tools
+// (`ls`, `cat`, `grep`, `diff`), for e.g. debug or ops tooling. It is
+// available on Linux and FreeBSD, see bazil.org/fuse.
+//
+//	unmount, err := fusefs.Mount("/tmp/appvfs", aah.AppVFS())
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer unmount()
+//
+// Use `vfs.Sub` to present the mount directory as root of the FUSE mount.
+package fusefs
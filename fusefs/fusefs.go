@@ -0,0 +1,227 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+//go:build linux || freebsd
+// +build linux freebsd
+
+package fusefs
+
+import (
+	"context"
+	"hash/fnv"
+	"io"
+	"os"
+	"path"
+	"sync"
+	"syscall"
+
+	"aahframework.org/vfs.v0"
+	"bazil.org/fuse"
+	"bazil.org/fuse/fs"
+)
+
+var _ fs.FS = (*FS)(nil)
+var _ fs.NodeStringLookuper = (*node)(nil)
+var _ fs.NodeOpener = (*node)(nil)
+var _ fs.NodeReadlinker = (*node)(nil)
+var _ fs.HandleReadDirAller = (*node)(nil)
+var _ fs.HandleReader = (*handle)(nil)
+var _ fs.HandleReleaser = (*handle)(nil)
+
+// FS is the read-only FUSE filesystem backed by `vfs.FileSystem`, its root
+// is the `/` of FileSystem.
+type FS struct {
+	fs vfs.FileSystem
+}
+
+// New method returns the FUSE filesystem of given FileSystem, it can be
+// served on FUSE connection via `bazil.org/fuse/fs.Serve`.
+func New(filesys vfs.FileSystem) *FS {
+	return &FS{fs: filesys}
+}
+
+// Mount method mounts the given FileSystem read-only on directory
+// mountpoint and serves it in the background until unmount func is called.
+func Mount(mountpoint string, filesys vfs.FileSystem) (unmount func() error, err error) {
+	c, err := fuse.Mount(mountpoint,
+		fuse.ReadOnly(),
+		fuse.FSName("aahvfs"),
+		fuse.Subtype("aahvfs"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- fs.Serve(c, New(filesys)) }()
+	<-c.Ready
+	if err = c.MountError; err != nil {
+		_ = c.Close()
+		return nil, err
+	}
+
+	var once sync.Once
+	return func() error {
+		once.Do(func() {
+			if err = fuse.Unmount(mountpoint); err == nil {
+				err = <-done
+			}
+			if cerr := c.Close(); err == nil {
+				err = cerr
+			}
+		})
+		return err
+	}, nil
+}
+
+// Root method implements `fs.FS`.
+func (f *FS) Root() (fs.Node, error) {
+	return &node{fs: f.fs, name: "/"}, nil
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Unexported types and methods
+//______________________________________________________________________________
+
+// node is the file, directory or symbolic link of FileSystem.
+type node struct {
+	fs   vfs.FileSystem
+	name string
+}
+
+func (n *node) Attr(ctx context.Context, a *fuse.Attr) error {
+	fi, err := n.fs.Lstat(n.name)
+	if err != nil {
+		return errno(err)
+	}
+	fillAttr(n.name, fi, a)
+	return nil
+}
+
+func (n *node) Lookup(ctx context.Context, name string) (fs.Node, error) {
+	cname := path.Join(n.name, name)
+	if _, err := n.fs.Lstat(cname); err != nil {
+		return nil, errno(err)
+	}
+	return &node{fs: n.fs, name: cname}, nil
+}
+
+func (n *node) ReadDirAll(ctx context.Context) ([]fuse.Dirent, error) {
+	infos, err := n.fs.ReadDir(n.name)
+	if err != nil {
+		return nil, errno(err)
+	}
+
+	entries := make([]fuse.Dirent, 0, len(infos))
+	for _, fi := range infos {
+		typ := fuse.DT_File
+		switch {
+		case fi.IsDir():
+			typ = fuse.DT_Dir
+		case fi.Mode()&os.ModeSymlink != 0:
+			typ = fuse.DT_Link
+		}
+		entries = append(entries, fuse.Dirent{
+			Inode: inode(path.Join(n.name, fi.Name())),
+			Type:  typ,
+			Name:  fi.Name(),
+		})
+	}
+	return entries, nil
+}
+
+func (n *node) Readlink(ctx context.Context, req *fuse.ReadlinkRequest) (string, error) {
+	target, err := n.fs.Readlink(n.name)
+	if err != nil {
+		return "", errno(err)
+	}
+	return target, nil
+}
+
+// Open method returns the node itself for directory, otherwise handle of
+// opened file. Write access returns EROFS.
+func (n *node) Open(ctx context.Context, req *fuse.OpenRequest, resp *fuse.OpenResponse) (fs.Handle, error) {
+	if !req.Flags.IsReadOnly() {
+		return nil, fuse.Errno(syscall.EROFS)
+	}
+	if req.Dir {
+		return n, nil
+	}
+
+	f, err := n.fs.Open(n.name)
+	if err != nil {
+		return nil, errno(err)
+	}
+	resp.Flags |= fuse.OpenKeepCache
+	return &handle{f: f}, nil
+}
+
+// handle is the opened file, read is served via `io.ReaderAt` if available.
+type handle struct {
+	mu sync.Mutex
+	f  vfs.File
+}
+
+func (h *handle) Read(ctx context.Context, req *fuse.ReadRequest, resp *fuse.ReadResponse) error {
+	buf := make([]byte, req.Size)
+	var n int
+	var err error
+	if ra, ok := h.f.(io.ReaderAt); ok {
+		n, err = ra.ReadAt(buf, req.Offset)
+	} else {
+		h.mu.Lock()
+		if _, err = h.f.Seek(req.Offset, io.SeekStart); err == nil {
+			n, err = io.ReadFull(h.f, buf)
+		}
+		h.mu.Unlock()
+	}
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return errno(err)
+	}
+	resp.Data = buf[:n]
+	return nil
+}
+
+func (h *handle) Release(ctx context.Context, req *fuse.ReleaseRequest) error {
+	return h.f.Close()
+}
+
+// fillAttr method fills the FUSE attributes of file info, write permission
+// bits are cleared.
+func fillAttr(name string, fi os.FileInfo, a *fuse.Attr) {
+	a.Inode = inode(name)
+	a.Mtime = fi.ModTime()
+	a.Mode = fi.Mode() &^ 0222
+	switch {
+	case fi.IsDir():
+		a.Mode = os.ModeDir | 0555
+	case fi.Mode()&os.ModeSymlink != 0:
+		a.Mode = os.ModeSymlink | 0444
+	default:
+		a.Size = uint64(fi.Size())
+		if a.Mode.Perm() == 0 {
+			a.Mode |= 0444
+		}
+	}
+}
+
+func inode(name string) uint64 {
+	h := fnv.New64a()
+	_, _ = io.WriteString(h, name)
+	return h.Sum64()
+}
+
+// errno method maps the FileSystem error to FUSE error number.
+func errno(err error) error {
+	switch {
+	case os.IsNotExist(err):
+		return fuse.ENOENT
+	case os.IsPermission(err):
+		return fuse.EPERM
+	}
+	if pe, ok := err.(*os.PathError); ok && pe.Err == vfs.ErrReadOnly {
+		return fuse.Errno(syscall.EROFS)
+	}
+	return err
+}
@@ -0,0 +1,74 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+//go:build linux || freebsd
+// +build linux freebsd
+
+package fusefs
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"aahframework.org/test.v0/assert"
+	"aahframework.org/vfs.v0"
+	"bazil.org/fuse"
+)
+
+// Nodes are exercised directly, mounting requires FUSE privilege.
+func TestFUSENodes(t *testing.T) {
+	m, err := vfs.NewMount("/app", "")
+	assert.Nil(t, err)
+	now := time.Now()
+	assert.Nil(t, m.AddDir(&vfs.NodeInfo{Dir: true, Path: "/app/css", Time: now}))
+	assert.Nil(t, m.AddFile(&vfs.NodeInfo{Path: "/app/css/app.css", DataSize: 15, Time: now}, []byte("body { m: 0; }\n")))
+	assert.Nil(t, m.Symlink("/app/css/app.css", "/app/app.css"))
+	sub, err := vfs.Sub(m, "/app")
+	assert.Nil(t, err)
+
+	ctx := context.Background()
+	root, err := New(sub).Root()
+	assert.Nil(t, err)
+	var a fuse.Attr
+	assert.Nil(t, root.Attr(ctx, &a))
+	assert.Equal(t, os.ModeDir|0555, a.Mode)
+
+	entries, err := root.(*node).ReadDirAll(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, "app.css", entries[0].Name)
+	assert.Equal(t, fuse.DT_Link, entries[0].Type)
+	assert.Equal(t, fuse.DT_Dir, entries[1].Type)
+
+	link, err := root.(*node).Lookup(ctx, "app.css")
+	assert.Nil(t, err)
+	target, err := link.(*node).Readlink(ctx, &fuse.ReadlinkRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, "/app/css/app.css", target)
+
+	css, err := root.(*node).Lookup(ctx, "css")
+	assert.Nil(t, err)
+	fnode, err := css.(*node).Lookup(ctx, "app.css")
+	assert.Nil(t, err)
+	assert.Nil(t, fnode.Attr(ctx, &a))
+	assert.Equal(t, uint64(15), a.Size)
+	assert.Equal(t, os.FileMode(0), a.Mode.Perm()&0222)
+
+	_, err = root.(*node).Lookup(ctx, "missing.css")
+	assert.Equal(t, fuse.ENOENT, err)
+
+	// read and write access
+	_, err = fnode.(*node).Open(ctx, &fuse.OpenRequest{Flags: fuse.OpenReadWrite}, &fuse.OpenResponse{})
+	assert.Equal(t, fuse.Errno(syscall.EROFS), err)
+
+	h, err := fnode.(*node).Open(ctx, &fuse.OpenRequest{Flags: fuse.OpenReadOnly}, &fuse.OpenResponse{})
+	assert.Nil(t, err)
+	resp := &fuse.ReadResponse{}
+	assert.Nil(t, h.(*handle).Read(ctx, &fuse.ReadRequest{Offset: 5, Size: 100}, resp))
+	assert.Equal(t, "{ m: 0; }\n", string(resp.Data))
+	assert.Nil(t, h.(*handle).Release(ctx, &fuse.ReleaseRequest{}))
+}
@@ -0,0 +1,414 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// GlobSortBy type is used to specify the sort key of Glob results.
+type GlobSortBy uint8
+
+// Glob sort keys
+const (
+	SortByPath GlobSortBy = iota
+	SortByModTime
+)
+
+// GlobOptions struct is used to sort and limit the Glob results.
+type GlobOptions struct {
+	// SortBy is the sort key, default is `SortByPath`.
+	SortBy GlobSortBy
+
+	// Reverse sorts the results in descending order.
+	Reverse bool
+
+	// Limit is maximum no. of results to return, 0 means all.
+	Limit int
+}
+
+// GlobWithOptions method returns the Glob results sorted and limited per
+// given options. For e.g.: newest matching file
+//
+//    vfs.GlobWithOptions(fs, "/app/migrations/*.sql", vfs.GlobOptions{
+//      SortBy:  vfs.SortByModTime,
+//      Reverse: true,
+//      Limit:   1,
+//    })
+//
+// It calls `filepath.Glob` and `os.Lstat` if fs == nil otherwise FileSystem.
+func GlobWithOptions(fs FileSystem, pattern string, opts GlobOptions) ([]string, error) {
+	var matches []string
+	var err error
+	if fs == nil {
+		matches, err = filepath.Glob(pattern)
+	} else {
+		matches, err = fs.Glob(pattern)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch opts.SortBy {
+	case SortByModTime:
+		if matches, err = sortByModTime(fs, matches); err != nil {
+			return nil, err
+		}
+	default:
+		sort.Strings(matches)
+	}
+
+	if opts.Reverse {
+		for i, j := 0, len(matches)-1; i < j; i, j = i+1, j-1 {
+			matches[i], matches[j] = matches[j], matches[i]
+		}
+	}
+
+	if opts.Limit > 0 && len(matches) > opts.Limit {
+		matches = matches[:opts.Limit]
+	}
+
+	return matches, nil
+}
+
+// GlobStar method is same as Glob with support of recursive wildcard and
+// brace expansion, for e.g.:
+//
+//	vfs.GlobStar(fs, "/app/views/**/*.html")
+//	vfs.GlobStar(fs, "/app/static/**/*.{css,js}")
+//
+// Path element `**` matches zero or more path elements, `{a,b}` expands to
+// each alternative and it can be nested. Other elements are matched per
+// `path.Match`. Pattern is matched on the walk of FileSystem, see `vfs.Walk`,
+// so results include both virtual tree and physical fallback of the mount.
+// Results are sorted and de-duplicated.
+//
+// It walks physical filesystem if fs == nil otherwise FileSystem.
+func GlobStar(fs FileSystem, pattern string) ([]string, error) {
+	patterns, err := expandBraces(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var matches []string
+	for _, p := range patterns {
+		if _, err = path.Match(p, ""); err != nil {
+			return nil, err
+		}
+		list, err := globStar(fs, path.Clean(filepath.ToSlash(p)))
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range list {
+			if !seen[m] {
+				seen[m] = true
+				matches = append(matches, m)
+			}
+		}
+	}
+
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// sortByModTime method sorts the given paths by modification time in
+// ascending order, paths with same time are sorted by path.
+func sortByModTime(fs FileSystem, matches []string) ([]string, error) {
+	times := make(map[string]time.Time, len(matches))
+	for _, p := range matches {
+		var fi os.FileInfo
+		var err error
+		if fs == nil {
+			fi, err = os.Lstat(p)
+		} else {
+			fi, err = fs.Lstat(p)
+		}
+		if err != nil {
+			return nil, err
+		}
+		times[p] = fi.ModTime()
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		ti, tj := times[matches[i]], times[matches[j]]
+		if ti.Equal(tj) {
+			return matches[i] < matches[j]
+		}
+		return ti.Before(tj)
+	})
+	return matches, nil
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Glob unexported methods
+//______________________________________________________________________________
+
+// glob method fans out the pattern to all the mounts and merges the results.
+// If pattern is shorter than mount path, matching ancestor of mount path is
+// part of results, for e.g.: `/*` => `/app`.
+func (v *VFS) glob(pattern string) ([]string, error) {
+	psegs := splitPath(pattern)
+	seen := make(map[string]bool)
+	var matches []string
+	add := func(p string) {
+		if !seen[p] {
+			seen[p] = true
+			matches = append(matches, p)
+		}
+	}
+
+	for _, m := range v.sortedMounts() {
+		msegs := splitPath(m.Vroot)
+		n := len(msegs)
+		if len(psegs) < n {
+			n = len(psegs)
+		}
+
+		matched := true
+		for i := 0; i < n; i++ {
+			if ok, _ := path.Match(psegs[i], msegs[i]); !ok {
+				matched = false
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+
+		if len(psegs) <= len(msegs) {
+			add("/" + strings.Join(msegs[:len(psegs)], "/"))
+			continue
+		}
+
+		list, err := globMount(m, path.Join(m.Vroot, path.Join(psegs[len(msegs):]...)))
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range list {
+			add(p)
+		}
+	}
+
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// sortedMounts method returns the mounts sorted by mount path.
+func (v *VFS) sortedMounts() []*Mount {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	mounts := make([]*Mount, 0, len(v.mounts))
+	for _, m := range v.mounts {
+		mounts = append(mounts, m)
+	}
+	sort.Slice(mounts, func(i, j int) bool { return mounts[i].Vroot < mounts[j].Vroot })
+	return mounts
+}
+
+// globMount method expands the pattern within mount, unlike `Mount.Glob`
+// pattern may have wildcards in any path element.
+func globMount(m *Mount, pattern string) ([]string, error) {
+	dir, file := path.Split(pattern)
+	dir = path.Clean(dir)
+	if !hasMeta(dir) {
+		return m.Glob(pattern)
+	}
+
+	dirs, err := globMount(m, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []string
+	for _, d := range dirs {
+		if fi, err := m.Stat(d); err != nil || !fi.IsDir() {
+			continue
+		}
+		list, err := m.Glob(path.Join(d, file))
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, list...)
+	}
+	return matches, nil
+}
+
+// globStar method walks the static prefix of pattern and matches the visited
+// paths, directories which could not match are skipped.
+func globStar(fs FileSystem, pattern string) ([]string, error) {
+	psegs := splitPath(pattern)
+	i := 0
+	for i < len(psegs) && psegs[i] != "**" && !hasMeta(psegs[i]) {
+		i++
+	}
+	root := strings.Join(psegs[:i], "/")
+	if strings.HasPrefix(pattern, "/") {
+		root = "/" + root
+	} else if root == "" {
+		root = "."
+	}
+
+	if i == len(psegs) {
+		if _, err := lstat(fs, root); err != nil {
+			return nil, nil
+		}
+		return []string{root}, nil
+	}
+
+	psegs = psegs[i:]
+	var matches []string
+	err := Walk(fs, root, func(fpath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil // same as Glob, I/O errors are ignored
+		}
+
+		rest := filepath.ToSlash(fpath)
+		if root == "." {
+			rest = strings.TrimPrefix(rest, ".")
+		} else {
+			rest = strings.TrimPrefix(rest, root)
+		}
+		segs := splitPath(rest)
+		if matchStar(psegs, segs) {
+			matches = append(matches, fpath)
+		}
+		if info.IsDir() && !matchStarPrefix(psegs, segs) {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return matches, err
+}
+
+// matchStar method reports whether the path elements match the pattern
+// elements, element `**` matches zero or more path elements.
+func matchStar(psegs, segs []string) bool {
+	for len(psegs) > 0 {
+		if psegs[0] == "**" {
+			for i := 0; i <= len(segs); i++ {
+				if matchStar(psegs[1:], segs[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segs) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(psegs[0], segs[0]); !ok {
+			return false
+		}
+		psegs, segs = psegs[1:], segs[1:]
+	}
+	return len(segs) == 0
+}
+
+// matchStarPrefix method reports whether the directory path elements or its
+// descendants could match the pattern elements.
+func matchStarPrefix(psegs, segs []string) bool {
+	for len(segs) > 0 {
+		if len(psegs) == 0 {
+			return false
+		}
+		if psegs[0] == "**" {
+			return true
+		}
+		if ok, _ := path.Match(psegs[0], segs[0]); !ok {
+			return false
+		}
+		psegs, segs = psegs[1:], segs[1:]
+	}
+	return true
+}
+
+// expandBraces method expands the brace alternatives of pattern, for e.g.:
+// `*.{css,js}` => `*.css`, `*.js`. Unbalanced brace returns
+// `path.ErrBadPattern`.
+func expandBraces(pattern string) ([]string, error) {
+	start, depth := -1, 0
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case '{':
+			if depth == 0 {
+				start = i
+			}
+			depth++
+		case '}':
+			if depth--; depth < 0 {
+				return nil, path.ErrBadPattern
+			}
+			if depth > 0 {
+				continue
+			}
+
+			var result []string
+			for _, alt := range splitAlternatives(pattern[start+1 : i]) {
+				list, err := expandBraces(pattern[:start] + alt + pattern[i+1:])
+				if err != nil {
+					return nil, err
+				}
+				result = append(result, list...)
+			}
+			return result, nil
+		}
+	}
+	if depth != 0 {
+		return nil, path.ErrBadPattern
+	}
+	return []string{pattern}, nil
+}
+
+// splitAlternatives method splits the brace content on top level commas.
+func splitAlternatives(s string) []string {
+	var alts []string
+	depth, last := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				alts = append(alts, s[last:i])
+				last = i + 1
+			}
+		}
+	}
+	return append(alts, s[last:])
+}
+
+// lstat method calls `os.Lstat` if fs == nil otherwise FileSystem.
+func lstat(fs FileSystem, name string) (os.FileInfo, error) {
+	if fs == nil {
+		return os.Lstat(name)
+	}
+	return fs.Lstat(name)
+}
+
+// hasMeta method reports whether path contains any of the magic characters
+// recognized by `path.Match`.
+func hasMeta(p string) bool {
+	return strings.ContainsAny(p, `*?[\`)
+}
+
+// splitPath method returns the path elements of given slash separated path.
+func splitPath(p string) []string {
+	p = strings.Trim(p, "/")
+	if p == "" {
+		return nil
+	}
+	return strings.Split(p, "/")
+}
@@ -0,0 +1,214 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+)
+
+var _ http.FileSystem = (*httpFS)(nil)
+
+// HTTP method returns the `http.FileSystem` of given FileSystem. URL path is
+// resolved under the mount path in case of `*vfs.Mount`, otherwise under
+// `/` and ancestors of VFS mount paths are served as directories. Not found
+// errors of vfs, for e.g.: `ErrMountNotExists` are reported as
+// `os.ErrNotExist`, so that `http.FileServer` responds with 404.
+func HTTP(fs FileSystem) http.FileSystem {
+	h := &httpFS{open: fs.Open, root: "/"}
+	switch t := fs.(type) {
+	case *Mount:
+		h.root = t.Vroot
+	case *VFS:
+		h.open = vfsTree{v: t}.Open
+	}
+	return h
+}
+
+// FileServer method returns the handler that serves HTTP requests with the
+// files of given FileSystem, see `vfs.HTTP`. It is `http.FileServer`, so
+// directory requests are served with `index.html` if exists otherwise the
+// directory listing, `Last-Modified` header is the modification time of
+// the file and range, conditional requests are supported. For e.g.:
+//
+//	http.Handle("/static/", http.StripPrefix("/static", vfs.FileServer(m)))
+//
+// Strong `ETag` header is the file checksum if its known, see
+// `vfs.Checksummer`, so `If-None-Match` requests are supported too. File is
+// opened once per request, so the access is counted once, see
+// `vfs.TrackAccess` and `vfs.Instrument`.
+func FileServer(fs FileSystem) http.Handler {
+	hfs := HTTP(fs)
+	fileServer := http.FileServer(hfs)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serveFile(w, r, hfs, fileServer, false)
+	})
+}
+
+// CompressedFileServer method is same as `vfs.FileServer` but it serves the
+// pre-compressed bytes of the file, see `vfs.Compressed`, as-is with
+// `Content-Encoding` header if the client accepts the encoding. Preference
+// order is `br`, `zstd` and `gzip`. Otherwise request is served by
+// `vfs.FileServer`. ETag of encoded bytes has the encoding suffix, for e.g.
+// `"<checksum>-br"`.
+func CompressedFileServer(fs FileSystem) http.Handler {
+	hfs := HTTP(fs)
+	fileServer := http.FileServer(hfs)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serveFile(w, r, hfs, fileServer, r.Method == http.MethodGet || r.Method == http.MethodHead)
+	})
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// HTTP adapter unexported types and methods
+//______________________________________________________________________________
+
+type httpFS struct {
+	open func(name string) (File, error)
+	root string
+}
+
+func (h *httpFS) Open(name string) (http.File, error) {
+	f, err := h.open(path.Join(h.root, path.Clean("/"+name)))
+	if err != nil {
+		return nil, httpError(name, err)
+	}
+	return f, nil
+}
+
+// httpError method maps the vfs errors to the errors recognized by
+// `http.FileServer`.
+func httpError(name string, err error) error {
+	e := err
+	if pe, ok := err.(*os.PathError); ok {
+		e = pe.Err
+	}
+	switch e {
+	case ErrMountNotExists, ErrInvalidPath:
+		return &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	case ErrReadOnly:
+		return &os.PathError{Op: "open", Path: name, Err: os.ErrPermission}
+	}
+	return err
+}
+
+// preferredEncodings is the preference order of pre-compressed encodings.
+var preferredEncodings = []string{EncodingBrotli, EncodingZstd, EncodingGzip}
+
+// serveFile method serves the requested file from single open, optionally
+// its pre-compressed bytes. Directory and index.html redirect requests are
+// left to fileServer, i.e. `http.FileServer`.
+func serveFile(w http.ResponseWriter, r *http.Request, hfs http.FileSystem, fileServer http.Handler, encoded bool) {
+	if strings.HasSuffix(r.URL.Path, "/") || strings.HasSuffix(r.URL.Path, "/index.html") {
+		fileServer.ServeHTTP(w, r)
+		return
+	}
+
+	upath := path.Clean("/" + r.URL.Path)
+	f, err := hfs.Open(upath)
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+	defer func() { _ = f.Close() }()
+	fi, err := f.Stat()
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+	if fi.IsDir() {
+		fileServer.ServeHTTP(w, r)
+		return
+	}
+
+	if encoded && serveEncoded(w, r, upath, f, fi) {
+		return
+	}
+	if sum := checksum(fi); sum != "" {
+		w.Header().Set("Etag", `"`+sum+`"`)
+	}
+	http.ServeContent(w, r, fi.Name(), fi.ModTime(), f)
+}
+
+// serveError method responds the error same as `http.FileServer`.
+func serveError(w http.ResponseWriter, err error) {
+	switch {
+	case os.IsNotExist(err):
+		http.Error(w, "404 page not found", http.StatusNotFound)
+	case os.IsPermission(err):
+		http.Error(w, "403 Forbidden", http.StatusForbidden)
+	default:
+		http.Error(w, "500 Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// serveEncoded method serves the pre-compressed bytes of opened file if
+// client accepts its encoding, it returns false if not served.
+func serveEncoded(w http.ResponseWriter, r *http.Request, upath string, f http.File, fi os.FileInfo) bool {
+	c, ok := f.(Compressed)
+	if !ok {
+		return false
+	}
+
+	accept, vary := r.Header.Get("Accept-Encoding"), false
+	for _, enc := range preferredEncodings {
+		data, found := c.EncodedBytes(enc)
+		if !found {
+			continue
+		}
+		if !vary {
+			w.Header().Add("Vary", "Accept-Encoding")
+			vary = true
+		}
+		if !acceptsEncoding(accept, enc) {
+			continue
+		}
+
+		ctype, err := contentType(upath, f)
+		if err != nil {
+			return false
+		}
+		w.Header().Set("Content-Type", ctype)
+		w.Header().Set("Content-Encoding", enc)
+		if sum := checksum(fi); sum != "" {
+			w.Header().Set("Etag", `"`+sum+"-"+enc+`"`)
+		}
+		http.ServeContent(w, r, upath, fi.ModTime(), bytes.NewReader(data))
+		return true
+	}
+	return false
+}
+
+// acceptsEncoding method returns true if Accept-Encoding header value has
+// the encoding or `*` with non-zero quality.
+func acceptsEncoding(accept, encoding string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		name := strings.ToLower(strings.TrimSpace(params[0]))
+		if name != encoding && name != "*" {
+			continue
+		}
+		for _, p := range params[1:] {
+			p = strings.Replace(strings.TrimSpace(p), " ", "", -1)
+			if p == "q=0" || strings.HasPrefix(p, "q=0.") && strings.Trim(p[4:], "0") == "" {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// checksum method returns the known checksum of file, empty if not known.
+func checksum(fi os.FileInfo) string {
+	if c, ok := fi.(Checksummer); ok {
+		sum, _ := c.Checksum()
+		return sum
+	}
+	return ""
+}
@@ -0,0 +1,194 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"aahframework.org/test.v0/assert"
+)
+
+func TestVFSHTTPFileServer(t *testing.T) {
+	fs := createVFS(t)
+	m, err := fs.FindMount("/app")
+	assert.Nil(t, err)
+
+	expected, err := fs.ReadFile("/app/static/css/aah.css")
+	assert.Nil(t, err)
+	fi, err := fs.Stat("/app/static/css/aah.css")
+	assert.Nil(t, err)
+
+	serve := func(h http.Handler, target string, hdr ...string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		for i := 0; i < len(hdr); i += 2 {
+			r.Header.Set(hdr[i], hdr[i+1])
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	h := FileServer(m)
+	w := serve(h, "/static/css/aah.css")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, string(expected), w.Body.String())
+	assert.Equal(t, fi.ModTime().UTC().Format(http.TimeFormat), w.Header().Get("Last-Modified"))
+
+	w = serve(h, "/static/css/aah.css", "If-Modified-Since", fi.ModTime().Add(time.Hour).UTC().Format(http.TimeFormat))
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	w = serve(h, "/static/css/")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, strings.Contains(w.Body.String(), `<a href="aah.css">aah.css</a>`))
+
+	w = serve(h, "/static/missing.css")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// VFS root lists the mounts
+	w = serve(FileServer(fs), "/")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, strings.Contains(w.Body.String(), `<a href="app/">app/</a>`))
+	w = serve(FileServer(fs), "/nomount/file.txt")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestVFSHTTPCompressedFileServer(t *testing.T) {
+	fs := createVFS(t)
+	m, err := fs.FindMount("/app")
+	assert.Nil(t, err)
+
+	expected, err := fs.ReadFile("/app/static/css/aah.css")
+	assert.Nil(t, err)
+	br := []byte("fake brotli payload")
+	assert.Nil(t, m.AddEncoded("/app/static/css/aah.css", EncodingBrotli, br))
+	assert.NotNil(t, m.AddEncoded("/app/static/css/not-exists.css", EncodingBrotli, br))
+	assert.NotNil(t, m.AddEncoded("/app/static/css", EncodingBrotli, br))
+
+	f, err := m.Open("/app/static/css/aah.css")
+	assert.Nil(t, err)
+	data, found := f.(Compressed).EncodedBytes(EncodingBrotli)
+	assert.True(t, found)
+	assert.Equal(t, br, data)
+	_, found = f.(Compressed).EncodedBytes(EncodingZstd)
+	assert.False(t, found)
+	_ = f.Close()
+
+	serve := func(target, accept string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		if accept != "" {
+			r.Header.Set("Accept-Encoding", accept)
+		}
+		w := httptest.NewRecorder()
+		CompressedFileServer(m).ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve("/static/css/aah.css", "gzip, deflate, br")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "br", w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+	assert.True(t, strings.HasPrefix(w.Header().Get("Content-Type"), "text/css"))
+	assert.Equal(t, string(br), w.Body.String())
+
+	for _, accept := range []string{"", "gzip", "br;q=0"} {
+		w = serve("/static/css/aah.css", accept)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "", w.Header().Get("Content-Encoding"))
+		assert.Equal(t, string(expected), w.Body.String())
+	}
+
+	// gzipped file node is served as-is
+	w = serve("/config/aah.conf", "gzip")
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	raw, err := m.Open("/app/config/aah.conf")
+	assert.Nil(t, err)
+	assert.Equal(t, string(raw.(Compressed).RawBytes()), w.Body.String())
+	_ = raw.Close()
+
+	// raw gzip file is served without content encoding
+	archive := []byte(gzipString(t, "aah framework"))
+	assert.Nil(t, m.AddFile(&NodeInfo{Path: "/app/static/app.tar.gz", DataSize: int64(len(archive)), Raw: true}, archive))
+	w = serve("/static/app.tar.gz", "gzip")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "", w.Header().Get("Content-Encoding"))
+	assert.Equal(t, string(archive), w.Body.String())
+
+	w = serve("/static/css/", "br")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "", w.Header().Get("Content-Encoding"))
+}
+
+func TestVFSHTTPFileServerETag(t *testing.T) {
+	fs := createVFS(t)
+	m, err := fs.FindMount("/app")
+	assert.Nil(t, err)
+
+	sum := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	assert.Nil(t, m.AddFile(&NodeInfo{Path: "/app/static/sum.txt", DataSize: 5, Time: time.Now(),
+		SHA256: sum}, []byte("hello")))
+	assert.Nil(t, m.AddEncoded("/app/static/sum.txt", EncodingBrotli, []byte("hb")))
+
+	serve := func(h http.Handler, target string, hdr ...string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		for i := 0; i < len(hdr); i += 2 {
+			r.Header.Set(hdr[i], hdr[i+1])
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve(FileServer(m), "/static/sum.txt")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"`+sum+`"`, w.Header().Get("Etag"))
+
+	w = serve(FileServer(m), "/static/sum.txt", "If-None-Match", `"`+sum+`"`)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	w = serve(CompressedFileServer(m), "/static/sum.txt", "Accept-Encoding", "br")
+	assert.Equal(t, `"`+sum+`-br"`, w.Header().Get("Etag"))
+	w = serve(CompressedFileServer(m), "/static/sum.txt", "Accept-Encoding", "br", "If-None-Match", `"`+sum+`-br"`)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	// checksum not known
+	w = serve(FileServer(m), "/static/css/aah.css")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "", w.Header().Get("Etag"))
+}
+
+func TestVFSHTTPFileServerSingleOpen(t *testing.T) {
+	m, err := NewMount("/app", "", Instrument(Instrumentation{}))
+	assert.Nil(t, err)
+	assert.Nil(t, m.AddFile(&NodeInfo{Path: "/app/app.js", DataSize: 4, Time: time.Now()}, []byte("aah;")))
+	assert.Nil(t, m.AddEncoded("/app/app.js", EncodingBrotli, []byte("br")))
+
+	serve := func(h http.Handler, target, accept string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		r.Header.Set("Accept-Encoding", accept)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve(FileServer(m), "/app.js", "")
+	assert.Equal(t, "aah;", w.Body.String())
+	assert.Equal(t, uint64(1), m.Metrics().EmbeddedOpens)
+	w = serve(CompressedFileServer(m), "/app.js", "br")
+	assert.Equal(t, "br", w.Body.String())
+	assert.Equal(t, uint64(2), m.Metrics().EmbeddedOpens)
+	w = serve(CompressedFileServer(m), "/app.js", "")
+	assert.Equal(t, "aah;", w.Body.String())
+	assert.True(t, strings.HasPrefix(w.Header().Get("Content-Type"), "application/javascript") ||
+		strings.HasPrefix(w.Header().Get("Content-Type"), "text/javascript"))
+	assert.Equal(t, uint64(3), m.Metrics().EmbeddedOpens)
+
+	w = serve(FileServer(m), "/missing.js", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, uint64(1), m.Metrics().Misses)
+}
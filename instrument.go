@@ -0,0 +1,145 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+)
+
+// Instrumentation struct holds the hooks of mount operations, see
+// `vfs.Instrument`. Hooks are called synchronously on the calling goroutine,
+// so keep them cheap, for e.g. increment the metric. Nil hook is skipped.
+type Instrumentation struct {
+	// OnOpen is called on successful Open and ReadFile with the name as
+	// given, physical is true if it is served from physical filesystem.
+	OnOpen func(name string, physical bool)
+
+	// OnReadFile is called on successful ReadFile with the no. of bytes
+	// read.
+	OnReadFile func(name string, n int, physical bool)
+
+	// OnMiss is called when the name does not exist in both virtual tree and
+	// physical filesystem, op is one of open, stat and lstat.
+	OnMiss func(op, name string)
+}
+
+// MountMetrics struct represents the access counters of mount, see
+// `Mount.Metrics`.
+type MountMetrics struct {
+	// EmbeddedOpens and PhysicalOpens are no. of successful opens served
+	// from virtual tree and physical filesystem, ReadFile included.
+	EmbeddedOpens uint64
+	PhysicalOpens uint64
+
+	// Misses is no. of Open, Stat and Lstat of the name which does not
+	// exist.
+	Misses uint64
+
+	// BytesRead is no. of bytes served by ReadFile.
+	BytesRead uint64
+}
+
+// String method Stringer interface.
+func (s MountMetrics) String() string {
+	return fmt.Sprintf("metrics(embedded=%d physical=%d misses=%d bytes=%d)",
+		s.EmbeddedOpens, s.PhysicalOpens, s.Misses, s.BytesRead)
+}
+
+// Instrument option makes the mount to count the opens, misses and bytes
+// served (see `Mount.Metrics`) and call the given hooks, for e.g. to export
+// via expvar or Prometheus. Nonzero `MountMetrics.PhysicalOpens` in
+// production reveals the files which are accidentally read from disk.
+//
+// When the mount gets swapped at runtime (`VFS.SyncMounts`,
+// `VFS.MountArchives`, watchers), the new mount continues the counters.
+func Instrument(hooks Instrumentation) MountOption {
+	return func(m *Mount) {
+		m.instr = &instrumentation{hooks: hooks}
+	}
+}
+
+// Metrics method returns the access counters of mount, zero value if mount
+// does not have `Instrument` option.
+func (m *Mount) Metrics() MountMetrics {
+	if m.instr == nil {
+		return MountMetrics{}
+	}
+	return m.instr.metrics()
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Instrumentation unexported types and methods
+//______________________________________________________________________________
+
+// instrumentation holds the counters, accessed atomically, and the hooks.
+// Counters are first for 64-bit alignment on 32-bit platforms.
+type instrumentation struct {
+	embeddedOpens uint64
+	physicalOpens uint64
+	misses        uint64
+	bytesRead     uint64
+	hooks         Instrumentation
+}
+
+func (in *instrumentation) open(name string, physical bool, err error) {
+	if err != nil {
+		in.miss("open", name, err)
+		return
+	}
+	if physical {
+		atomic.AddUint64(&in.physicalOpens, 1)
+	} else {
+		atomic.AddUint64(&in.embeddedOpens, 1)
+	}
+	if in.hooks.OnOpen != nil {
+		in.hooks.OnOpen(name, physical)
+	}
+}
+
+func (in *instrumentation) readFile(name string, n int, physical bool) {
+	atomic.AddUint64(&in.bytesRead, uint64(n))
+	if in.hooks.OnReadFile != nil {
+		in.hooks.OnReadFile(name, n, physical)
+	}
+}
+
+// miss method counts the error of non-existence, other errors are ignored.
+func (in *instrumentation) miss(op, name string, err error) {
+	if !os.IsNotExist(err) {
+		return
+	}
+	atomic.AddUint64(&in.misses, 1)
+	if in.hooks.OnMiss != nil {
+		in.hooks.OnMiss(op, name)
+	}
+}
+
+func (in *instrumentation) metrics() MountMetrics {
+	return MountMetrics{
+		EmbeddedOpens: atomic.LoadUint64(&in.embeddedOpens),
+		PhysicalOpens: atomic.LoadUint64(&in.physicalOpens),
+		Misses:        atomic.LoadUint64(&in.misses),
+		BytesRead:     atomic.LoadUint64(&in.bytesRead),
+	}
+}
+
+// inheritInstrumentation method continues the counters of the mount being
+// replaced, hooks of new mount take effect.
+func (m *Mount) inheritInstrumentation(old *Mount) {
+	if old == nil || old.instr == nil || old.instr == m.instr {
+		return
+	}
+	if m.instr == nil {
+		m.instr = old.instr
+		return
+	}
+	s := old.instr.metrics()
+	atomic.AddUint64(&m.instr.embeddedOpens, s.EmbeddedOpens)
+	atomic.AddUint64(&m.instr.physicalOpens, s.PhysicalOpens)
+	atomic.AddUint64(&m.instr.misses, s.Misses)
+	atomic.AddUint64(&m.instr.bytesRead, s.BytesRead)
+}
@@ -0,0 +1,160 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+//go:build go1.16
+// +build go1.16
+
+package vfs
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+)
+
+var _ fs.FS = (*ioFS)(nil)
+var _ fs.StatFS = (*ioFS)(nil)
+var _ fs.ReadDirFS = (*ioFS)(nil)
+var _ fs.ReadFileFS = (*ioFS)(nil)
+var _ fs.ReadDirFile = (*ioFile)(nil)
+
+// AsIOFS method returns the `io/fs.FS` of given FileSystem, so that a VFS or
+// Mount can be used with standard library APIs, for e.g.:
+// `template.ParseFS`, `http.FS`, `fstest.TestFS`.
+//
+// Names are unrooted slash separated paths per `io/fs` conventions. Root `.`
+// is the mount path in case of `*vfs.Mount`, otherwise `/`. Errors are
+// `*fs.PathError` carrying the io/fs name.
+func AsIOFS(fsys FileSystem) fs.FS {
+	f := &ioFS{fsys: fsys, src: fsys, root: "/"}
+	switch t := fsys.(type) {
+	case *Mount:
+		f.root = t.Vroot
+	case *VFS:
+		f.src = vfsTree{v: t}
+	}
+	return f
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// io/fs adapter unexported types and methods
+//______________________________________________________________________________
+
+type ioFS struct {
+	fsys FileSystem
+	src  ioSource
+	root string
+}
+
+// ioSource is used to open and stat, VFS is presented as one tree so that
+// ancestors of mount paths exists, see `VFS.Walk`.
+type ioSource interface {
+	Open(name string) (File, error)
+	Stat(name string) (os.FileInfo, error)
+}
+
+func (f *ioFS) Open(name string) (fs.File, error) {
+	vname, err := f.resolve("open", name)
+	if err != nil {
+		return nil, err
+	}
+	file, err := f.src.Open(vname)
+	if err != nil {
+		return nil, ioError("open", name, err)
+	}
+	return &ioFile{File: file, name: name}, nil
+}
+
+func (f *ioFS) Stat(name string) (fs.FileInfo, error) {
+	vname, err := f.resolve("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := f.src.Stat(vname)
+	if err != nil {
+		return nil, ioError("stat", name, err)
+	}
+	return fi, nil
+}
+
+func (f *ioFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	vname, err := f.resolve("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	d, err := f.src.Open(vname)
+	if err != nil {
+		return nil, ioError("readdir", name, err)
+	}
+	defer func() { _ = d.Close() }()
+
+	infos, err := d.Readdir(-1)
+	if err != nil {
+		return nil, ioError("readdir", name, err)
+	}
+	entries := dirEntries(infos)
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+func (f *ioFS) ReadFile(name string) ([]byte, error) {
+	vname, err := f.resolve("readfile", name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := f.fsys.ReadFile(vname)
+	if err != nil {
+		return nil, ioError("readfile", name, err)
+	}
+	return data, nil
+}
+
+// resolve method validates the io/fs name and returns the virtual path.
+func (f *ioFS) resolve(op, name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	return path.Join(f.root, name), nil
+}
+
+// ioFile adapts the `vfs.File` to `fs.ReadDirFile`.
+type ioFile struct {
+	File
+	name string
+}
+
+func (f *ioFile) ReadDir(n int) ([]fs.DirEntry, error) {
+	infos, err := f.File.Readdir(n)
+	if err != nil && len(infos) == 0 {
+		return nil, err
+	}
+	return dirEntries(infos), err
+}
+
+func dirEntries(infos []fs.FileInfo) []fs.DirEntry {
+	entries := make([]fs.DirEntry, 0, len(infos))
+	for _, fi := range infos {
+		entries = append(entries, fs.FileInfoToDirEntry(fi))
+	}
+	return entries
+}
+
+// ioError method maps the vfs error to io/fs conventions with io/fs name.
+func ioError(op, name string, err error) error {
+	var pe *fs.PathError
+	if errors.As(err, &pe) {
+		err = pe.Err
+	}
+	switch err {
+	case ErrInvalidPath:
+		err = fs.ErrInvalid
+	case ErrReadOnly:
+		err = fs.ErrPermission
+	case ErrMountNotExists:
+		err = fs.ErrNotExist
+	}
+	return &fs.PathError{Op: op, Path: name, Err: err}
+}
@@ -0,0 +1,48 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+//go:build go1.16
+// +build go1.16
+
+package vfs
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"aahframework.org/test.v0/assert"
+)
+
+func TestVFSAsIOFS(t *testing.T) {
+	v := createVFS(t)
+	m, err := v.FindMount("/app")
+	assert.Nil(t, err)
+
+	assert.Nil(t, fstest.TestFS(AsIOFS(m), "config/aah.conf", "config/env/dev.conf", "static/js/aah.js"))
+	assert.Nil(t, fstest.TestFS(AsIOFS(v), "app/config/security.conf"))
+
+	fsys := AsIOFS(v)
+	data, err := fs.ReadFile(fsys, "app/config/security.conf")
+	assert.Nil(t, err)
+	expected, err := v.ReadFile("/app/config/security.conf")
+	assert.Nil(t, err)
+	assert.Equal(t, expected, data)
+
+	matches, err := fs.Glob(AsIOFS(m), "config/env/*.conf")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"config/env/dev.conf", "config/env/prod.conf"}, matches)
+
+	for _, name := range []string{"/app", "app/../app", "app/"} {
+		_, err = fsys.Open(name)
+		assert.True(t, errors.Is(err, fs.ErrInvalid))
+	}
+
+	_, err = fsys.Open("app/missing.txt")
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+	assert.Equal(t, "app/missing.txt", err.(*fs.PathError).Path)
+	_, err = fsys.Open("nomount/file.txt")
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+}
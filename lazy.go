@@ -0,0 +1,209 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Lazy option enables lazy tree on physical filesystem fallback of the
+// mount. Directory entries are read from physical filesystem on first access
+// and cached, subsequent Stat, Open, ReadDir and Glob lookups are served from
+// cache, including non-existence. Cached directory expires after given ttl,
+// value 0 means never. Use `Mount.Invalidate` to drop cached directories on
+// change, for e.g. from file watcher.
+func Lazy(ttl time.Duration) MountOption {
+	return func(m *Mount) {
+		m.lazy = &lazyTree{ttl: ttl, dirs: make(map[string]*lazyDir)}
+	}
+}
+
+// Invalidate method drops the cached directory entries of given path and its
+// sub directories from lazy tree and the cached Stat results of path, its
+// descendants and its parent (see `vfs.StatCache`). It is no-op if mount is
+// neither `Lazy` nor `StatCache`.
+func (m *Mount) Invalidate(name string) {
+	if m.lazy != nil && m.hasPhysical() {
+		m.lazy.invalidate(m.toPhysicalPath(name))
+	}
+	if m.statCache != nil {
+		m.statCache.invalidate(name)
+	}
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Lazy tree unexported types and methods
+//______________________________________________________________________________
+
+// lazyEntryOverhead is the approximate bytes held by a cached entry besides
+// its name.
+const lazyEntryOverhead = 128
+
+// lazyTree caches the physical directory entries by physical directory path.
+type lazyTree struct {
+	ttl    time.Duration
+	mu     sync.RWMutex
+	dirs   map[string]*lazyDir
+	budget *memoryBudget
+}
+
+type lazyDir struct {
+	infos  []os.FileInfo
+	names  map[string]os.FileInfo
+	err    error
+	expire time.Time
+}
+
+// lstat method returns the file info of physical path from the entries of its
+// parent directory.
+func (t *lazyTree) lstat(pname, proot string) (os.FileInfo, error) {
+	if pname == proot {
+		return os.Lstat(pname)
+	}
+
+	d := t.dir(filepath.Dir(pname))
+	if d.err != nil {
+		err := d.err
+		if pe, ok := err.(*os.PathError); ok {
+			err = pe.Err
+		}
+		return nil, &os.PathError{Op: "lstat", Path: pname, Err: err}
+	}
+
+	fi, found := d.names[filepath.Base(pname)]
+	if !found {
+		return nil, &os.PathError{Op: "lstat", Path: pname, Err: os.ErrNotExist}
+	}
+	return fi, nil
+}
+
+// readDir method returns the copy of physical directory entries.
+func (t *lazyTree) readDir(pdir string) ([]os.FileInfo, error) {
+	d := t.dir(pdir)
+	if d.err != nil {
+		return nil, d.err
+	}
+	return append([]os.FileInfo{}, d.infos...), nil
+}
+
+// dir method returns the cached directory, it reads from physical filesystem
+// if not cached or expired.
+func (t *lazyTree) dir(pdir string) *lazyDir {
+	t.mu.RLock()
+	d, found := t.dirs[pdir]
+	t.mu.RUnlock()
+	if found && (t.ttl <= 0 || time.Now().Before(d.expire)) {
+		return d
+	}
+
+	d = &lazyDir{names: make(map[string]os.FileInfo)}
+	d.infos, d.err = ioutil.ReadDir(pdir)
+	for _, fi := range d.infos {
+		d.names[fi.Name()] = fi
+	}
+	if t.ttl > 0 {
+		d.expire = time.Now().Add(t.ttl)
+	}
+
+	t.mu.Lock()
+	t.dirs[pdir] = d
+	b := t.budget
+	t.mu.Unlock()
+	if b != nil {
+		b.add(t, pdir, d.size(pdir))
+	}
+	return d
+}
+
+// invalidate method drops the cached directories of given physical path, its
+// sub directories and its parent directory.
+func (t *lazyTree) invalidate(pname string) {
+	prefix := pname + string(filepath.Separator)
+	t.mu.Lock()
+	removed := []string{filepath.Dir(pname)}
+	delete(t.dirs, removed[0])
+	for pdir := range t.dirs {
+		if pdir == pname || strings.HasPrefix(pdir, prefix) {
+			delete(t.dirs, pdir)
+			removed = append(removed, pdir)
+		}
+	}
+	b := t.budget
+	t.mu.Unlock()
+
+	if b != nil {
+		for _, pdir := range removed {
+			b.remove(t, pdir)
+		}
+	}
+}
+
+// evict method implements `budgetOwner`.
+func (t *lazyTree) evict(pdir string) {
+	t.mu.Lock()
+	delete(t.dirs, pdir)
+	t.mu.Unlock()
+}
+
+// setBudget method moves the lazy tree accounting to given budget, nil
+// removes it from current budget.
+func (t *lazyTree) setBudget(b *memoryBudget) {
+	t.mu.Lock()
+	if t.budget == b {
+		t.mu.Unlock()
+		return
+	}
+	if t.budget != nil {
+		t.budget.removeOwner(t)
+	}
+	t.budget = b
+	sizes := make(map[string]int64, len(t.dirs))
+	for pdir, d := range t.dirs {
+		sizes[pdir] = d.size(pdir)
+	}
+	t.mu.Unlock()
+
+	if b != nil {
+		for pdir, size := range sizes {
+			b.add(t, pdir, size)
+		}
+	}
+}
+
+// size method returns the approximate memory held by cached directory.
+func (d *lazyDir) size(pdir string) int64 {
+	size := int64(len(pdir)) + lazyEntryOverhead
+	for _, fi := range d.infos {
+		size += int64(len(fi.Name())) + lazyEntryOverhead
+	}
+	return size
+}
+
+// lazyGlob method matches the pattern base on cached entries of pattern dir.
+func (m *Mount) lazyGlob(pattern string) ([]string, error) {
+	infos, err := m.lazy.readDir(m.toPhysicalPath(path.Dir(pattern)))
+	if err != nil {
+		return nil, nil
+	}
+
+	var matches []string
+	base := path.Base(pattern)
+	for _, fi := range infos {
+		match, err := filepath.Match(base, fi.Name())
+		if err != nil {
+			return nil, err
+		}
+		if match {
+			matches = append(matches, path.Join(path.Dir(pattern), fi.Name()))
+		}
+	}
+	return matches, nil
+}
@@ -0,0 +1,627 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+var _ WritableFileSystem = (*MemFS)(nil)
+var _ FileLocker = (*MemFS)(nil)
+var _ File = (*memFile)(nil)
+var _ io.Writer = (*memFile)(nil)
+var _ io.ReaderAt = (*memFile)(nil)
+
+// WritableFileSystem interface extends `vfs.FileSystem` with write operations.
+//
+// Files opened via `OpenFile` with write flags implements `io.Writer`.
+type WritableFileSystem interface {
+	FileSystem
+	Create(name string) (File, error)
+	Mkdir(name string, perm os.FileMode) error
+	Remove(name string) error
+	Rename(oldpath, newpath string) error
+	Truncate(name string, size int64) error
+	WriteFile(filename string, data []byte, perm os.FileMode) error
+}
+
+// FileLocker interface is implemented by writable filesystems which supports
+// advisory locking on a path. Locks are advisory, they coordinate only the
+// callers which use them and do not block regular read or write operations.
+//
+// Returned unlock func releases the lock, calling it more than once is no-op.
+type FileLocker interface {
+	Lock(name string) (unlock func(), err error)
+	RLock(name string) (unlock func(), err error)
+}
+
+// MemFS represents writable in-memory filesystem built on VFS node tree.
+// It does not have physical filesystem fallback.
+//
+// MemFS implements `vfs.WritableFileSystem` and it is safe for concurrent use.
+type MemFS struct {
+	mu      sync.RWMutex
+	tree    *node
+	locksMu sync.Mutex
+	locks   map[string]*pathLock
+}
+
+// NewMemFS method creates empty in-memory filesystem.
+func NewMemFS() *MemFS {
+	return &MemFS{
+		tree: newNode("/", &NodeInfo{Dir: true, Time: time.Now().UTC()}),
+	}
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// MemFS FileSystem interface methods
+//______________________________________________________________________________
+
+// Open method behaviour is same as `os.Open`.
+func (mfs *MemFS) Open(name string) (File, error) {
+	return mfs.OpenFile(name, os.O_RDONLY, 0)
+}
+
+// OpenFile method behaviour is same as `os.OpenFile`. It honors flags
+// `os.O_CREATE`, `os.O_EXCL`, `os.O_TRUNC` and `os.O_APPEND`. Argument perm
+// is not used, MemFS reports mode bits of `vfs.NodeInfo`.
+func (mfs *MemFS) OpenFile(name string, flag int, perm os.FileMode) (File, error) {
+	name, err := cleanPath("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if !isWriteFlag(flag) {
+		mfs.mu.RLock()
+		defer mfs.mu.RUnlock()
+		n, err := mfs.lookup("open", name)
+		if err != nil {
+			return nil, err
+		}
+		return &memFile{fs: mfs, n: n, flag: flag}, nil
+	}
+
+	mfs.mu.Lock()
+	defer mfs.mu.Unlock()
+	n, found := mfs.tree.lookup(name)
+	switch {
+	case found && flag&(os.O_CREATE|os.O_EXCL) == os.O_CREATE|os.O_EXCL:
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrExist}
+	case found && n.IsDir():
+		return nil, &os.PathError{Op: "open", Path: name, Err: errors.New("is a directory")}
+	case !found && flag&os.O_CREATE == 0:
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	case !found:
+		if n, err = mfs.create("open", name, false); err != nil {
+			return nil, err
+		}
+	}
+
+	if flag&os.O_TRUNC != 0 {
+		n.setData(n.data[:0])
+	}
+
+	return &memFile{fs: mfs, n: n, flag: flag}, nil
+}
+
+// Lstat method behaviour is same as `os.Lstat`.
+func (mfs *MemFS) Lstat(name string) (os.FileInfo, error) {
+	return mfs.Stat(name)
+}
+
+// Stat method behaviour is same as `os.Stat`.
+func (mfs *MemFS) Stat(name string) (os.FileInfo, error) {
+	name, err := cleanPath("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	mfs.mu.RLock()
+	defer mfs.mu.RUnlock()
+	n, err := mfs.lookup("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	info := *n.NodeInfo
+	return &info, nil
+}
+
+// Readlink method behaviour is same as `os.Readlink`. MemFS does not have
+// symbolic links, so it returns an error for existing name.
+func (mfs *MemFS) Readlink(name string) (string, error) {
+	if _, err := mfs.Lstat(name); err != nil {
+		return "", err
+	}
+	return "", &os.PathError{Op: "readlink", Path: name, Err: os.ErrInvalid}
+}
+
+// ReadFile method behaviour is same as `ioutil.ReadFile`.
+func (mfs *MemFS) ReadFile(filename string) ([]byte, error) {
+	filename, err := cleanPath("open", filename)
+	if err != nil {
+		return nil, err
+	}
+	mfs.mu.RLock()
+	defer mfs.mu.RUnlock()
+	n, err := mfs.lookup("open", filename)
+	if err != nil {
+		return nil, err
+	}
+	if n.IsDir() {
+		return nil, &os.PathError{Op: "read", Path: n.Path, Err: errors.New("is a directory")}
+	}
+	return append([]byte{}, n.data...), nil
+}
+
+// ReadDir method behaviour is same as `ioutil.ReadDir`.
+func (mfs *MemFS) ReadDir(dirname string) ([]os.FileInfo, error) {
+	dirname, err := cleanPath("open", dirname)
+	if err != nil {
+		return nil, err
+	}
+	mfs.mu.RLock()
+	defer mfs.mu.RUnlock()
+	n, err := mfs.lookup("open", dirname)
+	if err != nil {
+		return nil, err
+	}
+	if !n.IsDir() {
+		return nil, &os.PathError{Op: "read", Path: n.Path, Err: errors.New("is a file")}
+	}
+	return n.childInfoSnapshot(), nil
+}
+
+// Glob method somewhat similar to `filepath.Glob`, since MemFS does pattern
+// match only on `filepath.Base` value.
+func (mfs *MemFS) Glob(pattern string) ([]string, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	pattern, err := cleanPath("glob", pattern)
+	if err != nil {
+		return nil, err
+	}
+	mfs.mu.RLock()
+	defer mfs.mu.RUnlock()
+	n, found := mfs.tree.lookup(path.Dir(pattern))
+	if !found || !n.IsDir() {
+		return nil, nil
+	}
+
+	var matches []string
+	base := path.Base(pattern)
+	for _, c := range n.childInfos {
+		match, err := filepath.Match(base, c.Name())
+		if err != nil {
+			return nil, err
+		}
+		if match {
+			matches = append(matches, c.(*node).Path)
+		}
+	}
+	return matches, nil
+}
+
+// IsExists method is helper to find existence.
+func (mfs *MemFS) IsExists(name string) bool {
+	_, err := mfs.Lstat(name)
+	return err == nil
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// MemFS WritableFileSystem interface methods
+//______________________________________________________________________________
+
+// Create method behaviour is same as `os.Create`.
+func (mfs *MemFS) Create(name string) (File, error) {
+	return mfs.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+}
+
+// Mkdir method behaviour is same as `os.Mkdir`. Argument perm is not used.
+func (mfs *MemFS) Mkdir(name string, perm os.FileMode) error {
+	name, err := cleanPath("mkdir", name)
+	if err != nil {
+		return err
+	}
+	mfs.mu.Lock()
+	defer mfs.mu.Unlock()
+	_, err = mfs.create("mkdir", name, true)
+	return err
+}
+
+// Remove method behaviour is same as `os.Remove`.
+func (mfs *MemFS) Remove(name string) error {
+	name, err := cleanPath("remove", name)
+	if err != nil {
+		return err
+	}
+	mfs.mu.Lock()
+	defer mfs.mu.Unlock()
+	n, err := mfs.lookup("remove", name)
+	if err != nil {
+		return err
+	}
+	if n == mfs.tree || len(n.childs) > 0 {
+		return &os.PathError{Op: "remove", Path: name, Err: errors.New("directory not empty")}
+	}
+	parent, _ := mfs.tree.lookup(path.Dir(name))
+	parent.removeChild(n.Name())
+	return nil
+}
+
+// Rename method behaviour is same as `os.Rename`. Existing file at newpath
+// is replaced, existing directory at newpath returns an error.
+func (mfs *MemFS) Rename(oldpath, newpath string) error {
+	oldpath, err := cleanPath("rename", oldpath)
+	if err != nil {
+		return err
+	}
+	if newpath, err = cleanPath("rename", newpath); err != nil {
+		return err
+	}
+	mfs.mu.Lock()
+	defer mfs.mu.Unlock()
+	n, found := mfs.tree.lookup(oldpath)
+	if !found || n == mfs.tree {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: os.ErrNotExist}
+	}
+	if oldpath == newpath {
+		return nil
+	}
+	if strings.HasPrefix(newpath, oldpath+"/") {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: os.ErrInvalid}
+	}
+
+	parent, found := mfs.tree.lookup(path.Dir(newpath))
+	if !found || !parent.IsDir() {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: os.ErrNotExist}
+	}
+	if t, found := parent.childs[path.Base(newpath)]; found {
+		if t.IsDir() {
+			return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: os.ErrExist}
+		}
+		parent.removeChild(t.Name())
+	}
+
+	oldParent, _ := mfs.tree.lookup(path.Dir(oldpath))
+	oldParent.removeChild(n.Name())
+	n.rebase(newpath)
+	parent.addChild(n)
+	return nil
+}
+
+// Truncate method behaviour is same as `os.Truncate`.
+func (mfs *MemFS) Truncate(name string, size int64) error {
+	name, err := cleanPath("truncate", name)
+	if err != nil {
+		return err
+	}
+	mfs.mu.Lock()
+	defer mfs.mu.Unlock()
+	n, err := mfs.lookup("truncate", name)
+	if err != nil {
+		return err
+	}
+	return n.truncate("truncate", size)
+}
+
+// WriteFile method behaviour is same as `ioutil.WriteFile`, file is created
+// if not exists otherwise its content is replaced. MemFS keeps copy of the
+// data. Argument perm is not used.
+func (mfs *MemFS) WriteFile(filename string, data []byte, perm os.FileMode) error {
+	filename, err := cleanPath("write", filename)
+	if err != nil {
+		return err
+	}
+	mfs.mu.Lock()
+	defer mfs.mu.Unlock()
+	n, found := mfs.tree.lookup(filename)
+	if !found {
+		if n, err = mfs.create("write", filename, false); err != nil {
+			return err
+		}
+	}
+	if n.IsDir() {
+		return &os.PathError{Op: "write", Path: filename, Err: errors.New("is a directory")}
+	}
+	n.setData(append([]byte(nil), data...))
+	return nil
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// MemFS FileLocker interface methods
+//______________________________________________________________________________
+
+// Lock method acquires in-process exclusive advisory lock on given name,
+// it blocks until the lock is available. The name does not have to exist.
+func (mfs *MemFS) Lock(name string) (func(), error) {
+	name, err := cleanPath("lock", name)
+	if err != nil {
+		return nil, err
+	}
+	l := mfs.acquireLock(name)
+	l.Lock()
+	return mfs.unlocker(l, l.Unlock), nil
+}
+
+// RLock method acquires in-process shared advisory lock on given name,
+// it blocks while an exclusive lock is held. The name does not have to exist.
+func (mfs *MemFS) RLock(name string) (func(), error) {
+	name, err := cleanPath("rlock", name)
+	if err != nil {
+		return nil, err
+	}
+	l := mfs.acquireLock(name)
+	l.RLock()
+	return mfs.unlocker(l, l.RUnlock), nil
+}
+
+// String method Stringer interface.
+func (mfs *MemFS) String() string {
+	return "memfs(/)"
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// MemFS unexported methods
+//______________________________________________________________________________
+
+func (mfs *MemFS) lookup(op, name string) (*node, error) {
+	n, found := mfs.tree.lookup(name)
+	if !found {
+		return nil, &os.PathError{Op: op, Path: name, Err: os.ErrNotExist}
+	}
+	return n, nil
+}
+
+func (mfs *MemFS) create(op, name string, dir bool) (*node, error) {
+	if _, found := mfs.tree.lookup(name); found {
+		return nil, &os.PathError{Op: op, Path: name, Err: os.ErrExist}
+	}
+
+	parent, found := mfs.tree.lookup(path.Dir(name))
+	if !found {
+		return nil, &os.PathError{Op: op, Path: name, Err: os.ErrNotExist}
+	}
+	if !parent.IsDir() {
+		return nil, &os.PathError{Op: op, Path: name, Err: errors.New("not a directory")}
+	}
+
+	n := newNode(name, &NodeInfo{Dir: dir, Time: time.Now().UTC()})
+	parent.addChild(n)
+	return n, nil
+}
+
+func (mfs *MemFS) acquireLock(name string) *pathLock {
+	mfs.locksMu.Lock()
+	defer mfs.locksMu.Unlock()
+	if mfs.locks == nil {
+		mfs.locks = make(map[string]*pathLock)
+	}
+	l, found := mfs.locks[name]
+	if !found {
+		l = &pathLock{name: name}
+		mfs.locks[name] = l
+	}
+	l.refs++
+	return l
+}
+
+func (mfs *MemFS) unlocker(l *pathLock, unlock func()) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			unlock()
+			mfs.locksMu.Lock()
+			defer mfs.locksMu.Unlock()
+			if l.refs--; l.refs == 0 {
+				delete(mfs.locks, l.name)
+			}
+		})
+	}
+}
+
+// pathLock is reference counted lock of a path, it is removed from MemFS
+// once all the holders and waiters released it.
+type pathLock struct {
+	sync.RWMutex
+	name string
+	refs int
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// memFile type and methods
+//______________________________________________________________________________
+
+// memFile represents the opened file or directory of MemFS, every read and
+// write on node data happens under MemFS lock.
+type memFile struct {
+	fs     *MemFS
+	n      *node
+	flag   int
+	off    int64
+	pos    int
+	closed bool
+}
+
+func (f *memFile) Read(b []byte) (int, error) {
+	if err := f.check("read", os.O_WRONLY); err != nil {
+		return 0, err
+	}
+
+	f.fs.mu.RLock()
+	defer f.fs.mu.RUnlock()
+	if f.n.IsDir() {
+		return 0, &os.PathError{Op: "read", Path: f.n.Path, Err: errors.New("is a directory")}
+	}
+	if f.off >= int64(len(f.n.data)) {
+		return 0, io.EOF
+	}
+	size := copy(b, f.n.data[f.off:])
+	f.off += int64(size)
+	return size, nil
+}
+
+func (f *memFile) ReadAt(b []byte, off int64) (int, error) {
+	if err := f.check("read", os.O_WRONLY); err != nil {
+		return 0, err
+	}
+
+	f.fs.mu.RLock()
+	defer f.fs.mu.RUnlock()
+	if f.n.IsDir() {
+		return 0, &os.PathError{Op: "read", Path: f.n.Path, Err: errors.New("is a directory")}
+	}
+	if off < 0 {
+		return 0, &os.PathError{Op: "readat", Path: f.n.Path, Err: errors.New("negative offset")}
+	}
+	return bytes.NewReader(f.n.data).ReadAt(b, off)
+}
+
+func (f *memFile) Write(b []byte) (int, error) {
+	if err := f.check("write", os.O_RDONLY); err != nil {
+		return 0, err
+	}
+
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+	if f.flag&os.O_APPEND != 0 {
+		f.off = int64(len(f.n.data))
+	}
+
+	data := f.n.data
+	if end := f.off + int64(len(b)); end > int64(len(data)) {
+		size := int64(len(data))
+		if end > int64(cap(data)) {
+			grown := make([]byte, size, end*2)
+			copy(grown, data)
+			data = grown
+		}
+		data = data[:end]
+		for i := size; i < f.off; i++ { // fill the hole with zeros
+			data[i] = 0
+		}
+	}
+	size := copy(data[f.off:], b)
+	f.off += int64(size)
+	f.n.setData(data)
+
+	return size, nil
+}
+
+func (f *memFile) Seek(offset int64, whence int) (int64, error) {
+	if err := f.check("seek", -1); err != nil {
+		return 0, err
+	}
+
+	f.fs.mu.RLock()
+	defer f.fs.mu.RUnlock()
+	var abs int64
+	switch whence {
+	case io.SeekStart:
+		abs = offset
+	case io.SeekCurrent:
+		abs = f.off + offset
+	case io.SeekEnd:
+		abs = int64(len(f.n.data)) + offset
+	default:
+		return 0, fmt.Errorf("invalid whence: %v", whence)
+	}
+	if abs < 0 {
+		return 0, &os.PathError{Op: "seek", Path: f.n.Path, Err: os.ErrInvalid}
+	}
+	f.off = abs
+	return abs, nil
+}
+
+func (f *memFile) Truncate(size int64) error {
+	if err := f.check("truncate", os.O_RDONLY); err != nil {
+		return err
+	}
+
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+	return f.n.truncate("truncate", size)
+}
+
+func (f *memFile) Readdir(count int) ([]os.FileInfo, error) {
+	if err := f.check("readdir", -1); err != nil {
+		return nil, err
+	}
+
+	f.fs.mu.RLock()
+	defer f.fs.mu.RUnlock()
+	if !f.n.IsDir() {
+		return []os.FileInfo{}, &os.PathError{Op: "readdir", Path: f.n.Path, Err: errors.New("not a directory")}
+	}
+
+	infos := f.n.childInfoSnapshot()
+	if f.pos >= len(infos) && count > 0 {
+		return nil, io.EOF
+	}
+	if count <= 0 || count > len(infos)-f.pos {
+		count = len(infos) - f.pos
+	}
+	ci := infos[f.pos : f.pos+count]
+	f.pos += count
+
+	return ci, nil
+}
+
+func (f *memFile) Readdirnames(count int) ([]string, error) {
+	var list []string
+	infos, err := f.Readdir(count)
+	if err != nil {
+		return list, err
+	}
+
+	for _, v := range infos {
+		list = append(list, v.Name())
+	}
+
+	return list, nil
+}
+
+func (f *memFile) Stat() (os.FileInfo, error) {
+	if err := f.check("stat", -1); err != nil {
+		return nil, err
+	}
+
+	f.fs.mu.RLock()
+	defer f.fs.mu.RUnlock()
+	info := *f.n.NodeInfo
+	return &info, nil
+}
+
+func (f *memFile) Close() error {
+	if f.closed {
+		return &os.PathError{Op: "close", Path: f.n.Path, Err: os.ErrClosed}
+	}
+	f.closed = true
+	return nil
+}
+
+// String method Stringer interface.
+func (f *memFile) String() string {
+	return fmt.Sprintf("memfile(name=%s dir=%v)", f.n.Name(), f.n.IsDir())
+}
+
+// check method validates file is not closed and not opened with given
+// access mode. Pass -1 to skip the access mode validation.
+func (f *memFile) check(op string, deny int) error {
+	if f.closed {
+		return &os.PathError{Op: op, Path: f.n.Path, Err: os.ErrClosed}
+	}
+	if deny != -1 && f.flag&(os.O_RDONLY|os.O_WRONLY|os.O_RDWR) == deny {
+		return &os.PathError{Op: op, Path: f.n.Path, Err: os.ErrPermission}
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"aahframework.org/test.v0/assert"
+)
+
+func TestMemFSAppendAndTruncate(t *testing.T) {
+	mfs := NewMemFS()
+	assert.Nil(t, mfs.Mkdir("/logs", 0755))
+
+	f, err := mfs.OpenFile("/logs/app.log", os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	assert.Nil(t, err)
+	w := f.(io.Writer)
+	_, err = w.Write([]byte("line 1\n"))
+	assert.Nil(t, err)
+	_, err = f.Seek(0, io.SeekStart)
+	assert.Nil(t, err)
+	_, err = w.Write([]byte("line 2\n"))
+	assert.Nil(t, err)
+
+	// write only file
+	_, err = f.Read(make([]byte, 1))
+	assert.True(t, os.IsPermission(err))
+	assert.Nil(t, f.Close())
+	assert.NotNil(t, f.Close())
+
+	data, err := mfs.ReadFile("/logs/app.log")
+	assert.Nil(t, err)
+	assert.Equal(t, "line 1\nline 2\n", string(data))
+
+	// append on reopen
+	f, err = mfs.OpenFile("/logs/app.log", os.O_RDWR|os.O_APPEND, 0644)
+	assert.Nil(t, err)
+	_, err = f.(io.Writer).Write([]byte("line 3\n"))
+	assert.Nil(t, err)
+	_, err = f.Seek(0, io.SeekStart)
+	assert.Nil(t, err)
+	data, err = ioutil.ReadAll(f)
+	assert.Nil(t, err)
+	assert.Equal(t, "line 1\nline 2\nline 3\n", string(data))
+	assert.Nil(t, f.Close())
+
+	// truncate shrink and grow
+	assert.Nil(t, mfs.Truncate("/logs/app.log", 6))
+	assert.Nil(t, mfs.Truncate("/logs/app.log", 8))
+	data, err = mfs.ReadFile("/logs/app.log")
+	assert.Nil(t, err)
+	assert.Equal(t, "line 1\x00\x00", string(data))
+
+	fi, err := mfs.Stat("/logs/app.log")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(8), fi.Size())
+
+	// O_TRUNC on open
+	f, err = mfs.OpenFile("/logs/app.log", os.O_WRONLY|os.O_TRUNC, 0644)
+	assert.Nil(t, err)
+	_, err = f.Seek(3, io.SeekStart)
+	assert.Nil(t, err)
+	_, err = f.(io.Writer).Write([]byte("x"))
+	assert.Nil(t, err)
+	assert.Nil(t, f.Close())
+	data, err = mfs.ReadFile("/logs/app.log")
+	assert.Nil(t, err)
+	assert.Equal(t, "\x00\x00\x00x", string(data))
+
+	// errors
+	assert.True(t, os.IsNotExist(mfs.Truncate("/logs/not-exists.log", 0)))
+	assert.NotNil(t, mfs.Truncate("/logs", 0))
+	assert.NotNil(t, mfs.Truncate("/logs/app.log", -1))
+	_, err = mfs.OpenFile("/logs/app.log", os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	assert.True(t, os.IsExist(err))
+	_, err = mfs.OpenFile("/logs/missing.log", os.O_WRONLY, 0644)
+	assert.True(t, os.IsNotExist(err))
+	_, err = mfs.OpenFile("/nodir/app.log", os.O_WRONLY|os.O_CREATE, 0644)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestMemFSRemoveAndRename(t *testing.T) {
+	mfs := NewMemFS()
+	assert.Nil(t, mfs.Mkdir("/config", 0755))
+	assert.Nil(t, mfs.Mkdir("/config/env", 0755))
+	assert.Nil(t, WriteFileAtomic(mfs, "/config/env/dev.conf", []byte("env = dev"), 0644))
+
+	assert.Nil(t, mfs.Rename("/config", "/conf"))
+	assert.False(t, mfs.IsExists("/config/env/dev.conf"))
+	fi, err := mfs.Stat("/conf/env/dev.conf")
+	assert.Nil(t, err)
+	assert.Equal(t, "dev.conf", fi.Name())
+	assert.Equal(t, "/conf/env/dev.conf", fi.(*NodeInfo).Path)
+
+	assert.NotNil(t, mfs.Rename("/conf", "/conf/env/conf"))
+	assert.NotNil(t, mfs.Rename("/not-exists", "/conf"))
+	assert.NotNil(t, mfs.Remove("/conf/env"))
+	assert.Nil(t, mfs.Remove("/conf/env/dev.conf"))
+	assert.Nil(t, mfs.Remove("/conf/env"))
+	assert.True(t, os.IsNotExist(mfs.Remove("/conf/env")))
+}
+
+func TestMemFSCreateAndWriteFile(t *testing.T) {
+	mfs := NewMemFS()
+	assert.Nil(t, mfs.Mkdir("/views", 0755))
+
+	data := []byte("<h1>aah</h1>")
+	assert.Nil(t, mfs.WriteFile("/views/index.html", data, 0644))
+	data[1] = 'p'
+	b, err := mfs.ReadFile("/views/index.html")
+	assert.Nil(t, err)
+	assert.Equal(t, "<h1>aah</h1>", string(b))
+
+	assert.Nil(t, mfs.WriteFile("/views/index.html", []byte("home"), 0644))
+	b, err = mfs.ReadFile("/views/index.html")
+	assert.Nil(t, err)
+	assert.Equal(t, "home", string(b))
+
+	f, err := mfs.Create("/views/index.html")
+	assert.Nil(t, err)
+	_, err = f.(io.Writer).Write([]byte("index"))
+	assert.Nil(t, err)
+	_, err = f.Seek(0, io.SeekStart)
+	assert.Nil(t, err)
+	b, err = ioutil.ReadAll(f)
+	assert.Nil(t, err)
+	assert.Equal(t, "index", string(b))
+	assert.Nil(t, f.Close())
+
+	assert.NotNil(t, mfs.WriteFile("/views", data, 0644))
+	assert.True(t, os.IsNotExist(mfs.WriteFile("/layouts/master.html", data, 0644)))
+	_, err = mfs.Create("/views")
+	assert.NotNil(t, err)
+}
+
+func TestMemFSWriteFileAtomic(t *testing.T) {
+	mfs := NewMemFS()
+	assert.Nil(t, WriteFileAtomic(mfs, "/state.json", []byte(`{"v":1}`), 0644))
+	assert.Nil(t, WriteFileAtomic(mfs, "/state.json", []byte(`{"v":2}`), 0644))
+
+	data, err := mfs.ReadFile("/state.json")
+	assert.Nil(t, err)
+	assert.Equal(t, `{"v":2}`, string(data))
+
+	infos, err := mfs.ReadDir("/")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(infos))
+
+	assert.NotNil(t, WriteFileAtomic(mfs, "/not-exists/state.json", []byte(`{}`), 0644))
+
+	// physical filesystem
+	dir, err := ioutil.TempDir("", "vfs-atomic")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	name := filepath.Join(dir, "state.json")
+	assert.Nil(t, WriteFileAtomic(nil, name, []byte(`{"v":1}`), 0600))
+	data, err = ioutil.ReadFile(name)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"v":1}`, string(data))
+
+	names, err := filepath.Glob(filepath.Join(dir, "*"))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{name}, names)
+}
+
+func TestMemFSLock(t *testing.T) {
+	mfs := NewMemFS()
+
+	unlock, err := mfs.Lock("/state.json")
+	assert.Nil(t, err)
+
+	acquired := make(chan struct{})
+	go func() {
+		runlock, _ := mfs.RLock("state.json")
+		close(acquired)
+		runlock()
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("shared lock acquired while exclusive lock is held")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	unlock()
+	unlock() // no-op
+	<-acquired
+
+	r1, err := mfs.RLock("/state.json")
+	assert.Nil(t, err)
+	r2, err := mfs.RLock("/state.json")
+	assert.Nil(t, err)
+	r1()
+	r2()
+
+	mfs.locksMu.Lock()
+	assert.Equal(t, 0, len(mfs.locks))
+	mfs.locksMu.Unlock()
+}
@@ -5,26 +5,169 @@
 package vfs
 
 import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
-	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 var _ FileSystem = (*Mount)(nil)
+var _ io.Closer = (*Mount)(nil)
 
 // Mount struct represents mount of single physical directory into virtual directory.
 //
 // Mount implements `vfs.FileSystem`, its a combination of package `os` and `ioutil`
 // focused on Read-Only operations.
+//
+// Adding nodes at runtime, for e.g.: plugin asset packs, is safe while the
+// mount is being read. Opened directory lists the entries as of its open.
 type Mount struct {
 	Vroot string
 	Proot string
 	tree  *node
+	arena *dataArena
+	strs  *stringPool
+
+	// treeMu guards the virtual tree structure, opened file takes the
+	// directory entries on open
+	treeMu sync.RWMutex
+
+	strict         bool
+	caseFold       bool
+	readOnly       bool
+	sealed         bool // guarded by treeMu
+	preferPhysical bool
+	addGzip        bool
+	addLevel       int
+	lazy           *lazyTree
+	statCache      *statCache
+	instr          *instrumentation
+	contentCache   *contentCache
+	cache          *dataCache
+	gzCache        *dataCache
+	gzMaxSize      int64
+	access         *accessStats
+	prefetchWg     sync.WaitGroup // background prefetch, waited on Close
+	maxRead        int64
+
+	// physical path containment, realRoot is the Proot with symbolic links
+	// evaluated
+	externalLinks bool
+	realRootOnce  sync.Once
+	realRoot      string
+
+	closeMu sync.Mutex
+	closers []io.Closer
+	mapping *mappingRef // memory-mapped data of blob mount
+
+	fpMu         sync.Mutex
+	fingerprints map[string]fingerprintEntry
+
+	// aead is the cipher of encrypted files, see `Mount.SetDecryptionKey`
+	aead atomic.Value
+
+	// signature is the asset manifest signature, guarded by tree lock
+	signature []byte
+
+	// open file accounting, no. of symbolic links and embedded only flag,
+	// accessed atomically
+	virtualFiles  int32
+	physicalFiles int32
+	maxOpenFiles  int32
+	symlinks      int32
+	embeddedOnly  int32
+}
+
+// MountOption type is used to configure the mount created via `vfs.NewMount`.
+type MountOption func(m *Mount)
+
+// Strict option makes `Mount.AddDir` and `Mount.AddFile` to return an error
+// if the parent directory node does not exist, instead of adding it to the
+// nearest existing parent.
+func Strict() MountOption {
+	return func(m *Mount) {
+		m.strict = true
+	}
+}
+
+// CaseFold option makes virtual tree lookup case-insensitive.
+func CaseFold() MountOption {
+	return func(m *Mount) {
+		m.caseFold = true
+	}
+}
+
+// ReadOnly option seals the virtual tree of the mount once its populated,
+// see `Mount.Seal`. `vfs.NewZipMount`, `vfs.NewTarMount`, `vfs.NewEmbedMount`
+// and `vfs.LoadPack` seal the mount on return. Mount of `vfs.NewMount` is
+// populated by the caller, for e.g. `vfs.Binary` generated code, so call
+// `Mount.Seal` after adding the nodes.
+func ReadOnly() MountOption {
+	return func(m *Mount) {
+		m.readOnly = true
+	}
+}
+
+// MaxReadSize option sets the maximum file size `Mount.ReadFile` reads into
+// memory, beyond it returns `ErrFileTooLarge`. It protects the application
+// from accidentally reading a huge physical file into memory, such file can
+// be streamed via `Mount.Open`. Value 0 means no limit.
+func MaxReadSize(n int64) MountOption {
+	return func(m *Mount) {
+		m.maxRead = n
+	}
+}
+
+// AllowExternalLinks option allows the physical symbolic links resolving
+// outside of the mount source, for e.g.: dependency directories linked
+// into the project on development. By default such paths are refused with
+// `os.ErrPermission`.
+func AllowExternalLinks() MountOption {
+	return func(m *Mount) {
+		m.externalLinks = true
+	}
+}
+
+// EmbeddedOnly option disables the physical filesystem fallback of the mount,
+// lookup missing in virtual tree returns `os.ErrNotExist`. It is meant for
+// production single binary deployment, see `Mount.SetEmbeddedOnly`.
+func EmbeddedOnly() MountOption {
+	return func(m *Mount) {
+		m.embeddedOnly = 1
+	}
+}
+
+// CompressAdded option makes `Mount.AddFileFromReader` and
+// `Mount.AddFileString` to gzip compress the data on the fly with given
+// compression level, same representation as single binary. Use it for large
+// runtime injected files, small files may not benefit from compression.
+func CompressAdded(level int) MountOption {
+	return func(m *Mount) {
+		m.addGzip = true
+		m.addLevel = level
+	}
+}
+
+// NewMount method creates the mount of physical directory source into virtual
+// directory vroot. Mount can be used standalone as `vfs.FileSystem`.
+//
+// Source must be absolute path, its taken as-is, use `vfs.ExpandPath` to
+// expand the environment variables. Its existence is not verified since in
+// single binary the physical directory may not exist. Empty source creates
+// purely virtual mount without physical filesystem fallback.
+func NewMount(vroot, source string, opts ...MountOption) (*Mount, error) {
+	return newMount(vroot, source, opts...)
 }
 
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
@@ -32,42 +175,53 @@ type Mount struct {
 //______________________________________________________________________________
 
 // Open method behaviour is same as `os.Open`.
-func (m Mount) Open(name string) (File, error) {
-	f, err := m.open(name)
-	if os.IsNotExist(err) {
-		return m.openPhysical(name)
+func (m *Mount) Open(name string) (File, error) {
+	f, physical, err := m.openFile(name)
+	if m.instr != nil {
+		m.instr.open(name, physical, err)
 	}
 	return f, err
 }
 
-// Lstat method behaviour is same as `os.Lstat`.
-func (m Mount) Lstat(name string) (os.FileInfo, error) {
-	f, err := m.open(name)
-	if os.IsNotExist(err) {
-		return os.Lstat(m.toPhysicalPath(name))
+// OpenFile method behaviour is same as `os.OpenFile`. Mount is Read-Only, so
+// flags other than `os.O_RDONLY` returns `ErrReadOnly`.
+func (m *Mount) OpenFile(name string, flag int, perm os.FileMode) (File, error) {
+	if isWriteFlag(flag) {
+		return nil, &os.PathError{Op: "open", Path: name, Err: ErrReadOnly}
 	}
-	return f, err
+	return m.Open(name)
 }
 
-// Stat method behaviour is same as `os.Stat`
-func (m Mount) Stat(name string) (os.FileInfo, error) {
-	f, err := m.open(name)
-	if os.IsNotExist(err) {
-		return os.Stat(m.toPhysicalPath(name))
+// Lstat method behaviour is same as `os.Lstat`. If the file is a symbolic
+// link, the returned FileInfo describes the symbolic link.
+func (m *Mount) Lstat(name string) (os.FileInfo, error) {
+	fi, err := m.stat(name, false)
+	if m.instr != nil {
+		m.instr.miss("lstat", name, err)
 	}
-	return f, err
+	return fi, err
 }
 
-// ReadFile method behaviour is same as `ioutil.ReadFile`.
-func (m Mount) ReadFile(name string) ([]byte, error) {
-	f, err := m.Open(name)
-	if os.IsNotExist(err) {
-		f, err = m.openPhysical(name)
+// Stat method behaviour is same as `os.Stat`. It follows the symbolic link
+// and returns the FileInfo of link target.
+func (m *Mount) Stat(name string) (os.FileInfo, error) {
+	fi, err := m.stat(name, true)
+	if m.instr != nil {
+		m.instr.miss("stat", name, err)
 	}
+	return fi, err
+}
 
+// ReadFile method behaviour is same as `ioutil.ReadFile`.
+func (m *Mount) ReadFile(name string) ([]byte, error) {
+	f, physical, err := m.openFile(name)
+	if m.instr != nil {
+		m.instr.open(name, physical, err)
+	}
 	if err != nil {
 		return nil, err
 	}
+	defer func() { _ = f.Close() }()
 
 	fi, err := f.Stat()
 	if err != nil {
@@ -78,32 +232,118 @@ func (m Mount) ReadFile(name string) ([]byte, error) {
 		return nil, &os.PathError{Op: "read", Path: name, Err: errors.New("is a directory")}
 	}
 
-	return ioutil.ReadAll(f)
+	if m.maxRead > 0 && fi.Size() > m.maxRead {
+		return nil, &os.PathError{Op: "read", Path: name, Err: ErrFileTooLarge}
+	}
+
+	var data []byte
+	if pf, ok := f.(*physicalFile); ok && m.contentCache != nil {
+		data, err = m.contentCache.readFile(pf, fi)
+	} else {
+		data, err = readAllSize(f, fi.Size())
+	}
+	if err == nil && m.instr != nil {
+		m.instr.readFile(name, len(data), physical)
+	}
+	return data, err
 }
 
 // ReadDir method behaviour is same as `ioutil.ReadDir`.
-func (m Mount) ReadDir(dirname string) ([]os.FileInfo, error) {
+func (m *Mount) ReadDir(dirname string) ([]os.FileInfo, error) {
+	dirname, err := cleanPath("open", dirname)
+	if err != nil {
+		return nil, err
+	}
+	if dirname, err = m.followLinks("open", dirname, true); err != nil {
+		return nil, err
+	}
+	if m.preferPhysical {
+		if infos, err := m.readDirPhysical(dirname); !os.IsNotExist(err) {
+			return infos, err
+		}
+	}
 	f, err := m.open(dirname)
 	if os.IsNotExist(err) {
-		return ioutil.ReadDir(m.toPhysicalPath(dirname))
+		return m.readDirPhysical(dirname)
 	}
 
 	if !f.IsDir() {
 		return nil, &os.PathError{Op: "read", Path: dirname, Err: errors.New("is a file")}
 	}
 
-	list := append([]os.FileInfo{}, f.node.childInfos...)
-	sort.Sort(byName(list))
+	return append([]os.FileInfo{}, f.childInfos...), nil
+}
 
-	return list, nil
+// ReadDirFunc method calls fn for each entry of directory without building
+// the full list, it stops on first error returned by fn. If fn returns
+// `filepath.SkipDir` iteration stops and ReadDirFunc returns nil. Virtual
+// entries are in sorted order, physical entries are in directory order.
+func (m *Mount) ReadDirFunc(dirname string, fn func(os.FileInfo) error) error {
+	dirname, err := cleanPath("open", dirname)
+	if err != nil {
+		return err
+	}
+	if dirname, err = m.followLinks("open", dirname, true); err != nil {
+		return err
+	}
+	if m.preferPhysical {
+		if err = m.readDirFuncPhysical(dirname, fn); !os.IsNotExist(err) {
+			return err
+		}
+	}
+	f, err := m.open(dirname)
+	if os.IsNotExist(err) {
+		return m.readDirFuncPhysical(dirname, fn)
+	}
+
+	if !f.IsDir() {
+		return &os.PathError{Op: "read", Path: dirname, Err: errors.New("is a file")}
+	}
+
+	return ignoreStop(eachFileInfo(f.childInfos, fn))
+}
+
+// Walk method behaviour is same as `filepath.Walk`. It traverses virtual tree
+// and physical filesystem fallback in single pass, directory lists the union
+// of its virtual and physical entries in lexical order. Path exists on both
+// is visited once and served from virtual tree.
+func (m *Mount) Walk(root string, walkFn filepath.WalkFunc) error {
+	root, err := cleanPath("walk", root)
+	if err != nil {
+		return err
+	}
+
+	t := mountWalker{m: m}
+	info, err := m.Lstat(root)
+	if err == nil {
+		err = walk(t, root, info, walkFn)
+	} else {
+		err = walkFn(root, nil, err)
+	}
+
+	if err == filepath.SkipDir {
+		return nil
+	}
+	return err
 }
 
 // Glob method somewhat similar to `filepath.Glob`, since aah vfs does pattern
 // match only on `filepath.Base` value.
-func (m Mount) Glob(pattern string) ([]string, error) {
+func (m *Mount) Glob(pattern string) ([]string, error) {
+	pattern, err := cleanPath("glob", pattern)
+	if err != nil {
+		return nil, err
+	}
+
 	var matches []string
 	f, err := m.open(path.Dir(pattern))
 	if os.IsNotExist(err) {
+		if !m.hasPhysical() {
+			return nil, nil
+		}
+		if m.lazy != nil && !hasMeta(path.Dir(pattern)) {
+			return m.lazyGlob(pattern)
+		}
 		flist, err := filepath.Glob(m.toPhysicalPath(pattern))
 		if err != nil {
 			return nil, err
@@ -115,26 +355,26 @@ func (m Mount) Glob(pattern string) ([]string, error) {
 	}
 
 	base := path.Base(pattern)
-	for _, c := range f.childs {
+	for _, c := range f.childInfos {
 		match, err := filepath.Match(base, c.Name())
 		if err != nil {
 			return nil, err
 		}
 		if match {
-			matches = append(matches, c.Path)
+			matches = append(matches, c.(*node).Path)
 		}
 	}
 	return matches, nil
 }
 
 // IsExists method is helper to find existence.
-func (m Mount) IsExists(name string) bool {
+func (m *Mount) IsExists(name string) bool {
 	_, err := m.Lstat(name)
 	return err == nil
 }
 
 // String method Stringer interface.
-func (m Mount) String() string {
+func (m *Mount) String() string {
 	return fmt.Sprintf("mount(%s => %s)", m.Vroot, m.Proot)
 }
 
@@ -149,24 +389,471 @@ func (m *Mount) Name() string {
 
 // AddDir method is to add directory node into VFS from mounted source directory.
 func (m *Mount) AddDir(fi os.FileInfo) error {
+	if err := m.checkSealed("addnode", fi); err != nil {
+		return err
+	}
 	return m.addNode(fi, nil)
 }
 
 // AddFile method is to add file node into VFS from mounted source directory.
+//
+// Data is copied into the mount data arena, i.e. small files of the mount
+// share few large allocations instead of one allocation per file, which
+// reduces the GC work for asset heavy applications.
 func (m *Mount) AddFile(fi os.FileInfo, data []byte) error {
-	return m.addNode(fi, data)
+	if err := m.checkSealed("addnode", fi); err != nil {
+		return err
+	}
+	return m.addNode(fi, m.arena.copy(data))
+}
+
+// AddDirAll method is same as `Mount.AddDir` but it also creates the missing
+// parent directories, like `os.MkdirAll`. Created directories have the
+// modification time of given directory.
+func (m *Mount) AddDirAll(fi os.FileInfo) error {
+	if err := m.checkSealed("addnode", fi); err != nil {
+		return err
+	}
+	if err := m.mkdirAll(path.Dir(fi.(*NodeInfo).Path), fi.ModTime()); err != nil {
+		return err
+	}
+	return m.AddDir(fi)
+}
+
+// AddFileAll method is same as `Mount.AddFile` but it also creates the
+// missing parent directories, like `os.MkdirAll`. Created directories have
+// the modification time of given file.
+func (m *Mount) AddFileAll(fi os.FileInfo, data []byte) error {
+	if err := m.checkSealed("addnode", fi); err != nil {
+		return err
+	}
+	if err := m.mkdirAll(path.Dir(fi.(*NodeInfo).Path), fi.ModTime()); err != nil {
+		return err
+	}
+	return m.AddFile(fi, data)
+}
+
+// AddFileFromReader method adds the file node of virtual path mountPath with
+// the data read from r until EOF, for e.g.: runtime generated config. Data
+// is read directly into the mount data arena, gzip compressed if
+// `vfs.CompressAdded` option is set, and its SHA-256 checksum is computed
+// along. Size of fi is the expected data size, modification time and
+// permission bits are taken from fi; current time and read-only if fi is
+// nil.
+func (m *Mount) AddFileFromReader(mountPath string, fi os.FileInfo, r io.Reader) error {
+	name, err := cleanPath("addfile", mountPath)
+	if err != nil {
+		return err
+	}
+	if !isPathWithin(name, m.Vroot) || name == m.Vroot {
+		return &os.PathError{Op: "addfile", Path: name, Err: os.ErrInvalid}
+	}
+
+	ni := &NodeInfo{Path: name, Time: time.Now().UTC()}
+	var size int64
+	if fi != nil {
+		if fi.IsDir() {
+			return &os.PathError{Op: "addfile", Path: mountPath, Err: errors.New("is a directory")}
+		}
+		ni.Time = fi.ModTime()
+		ni.Perm = fi.Mode().Perm()
+		size = fi.Size()
+	}
+	if err := m.checkSealed("addfile", ni); err != nil {
+		return err
+	}
+
+	h := sha256.New()
+	var data []byte
+	if m.addGzip {
+		data, ni.DataSize, err = m.readGzipped(io.TeeReader(r, h))
+	} else {
+		data, err = m.arena.readFrom(io.TeeReader(r, h), size)
+		ni.DataSize = int64(len(data))
+	}
+	if err != nil {
+		return &os.PathError{Op: "addfile", Path: mountPath, Err: err}
+	}
+	ni.SHA256 = hex.EncodeToString(h.Sum(nil))
+	ni.Gzip, ni.Raw = m.addGzip, !m.addGzip
+	return m.addNode(ni, data)
+}
+
+// AddFileString method is same as `Mount.AddFileFromReader` with data of
+// given string.
+func (m *Mount) AddFileString(mountPath string, fi os.FileInfo, data string) error {
+	return m.AddFileFromReader(mountPath, fi, strings.NewReader(data))
+}
+
+// AddEncoded method adds the pre-compressed data of given content encoding,
+// for e.g. `br`, to the virtual file name. Data is served as-is by
+// `vfs.CompressedFileServer` to the clients accepting the encoding, its not
+// decoded on read. Variant of same encoding is replaced.
+func (m *Mount) AddEncoded(name, encoding string, data []byte) error {
+	m.treeMu.Lock()
+	defer m.treeMu.Unlock()
+	if m.sealed {
+		return &os.PathError{Op: "addencoded", Path: name, Err: ErrReadOnly}
+	}
+	if encoding == "" {
+		return &os.PathError{Op: "addencoded", Path: name, Err: errors.New("empty encoding")}
+	}
+
+	n, found := m.tree.lookup(strings.TrimPrefix(path.Clean(name), m.Vroot))
+	if !found || n.IsDir() {
+		return &os.PathError{Op: "addencoded", Path: name, Err: os.ErrNotExist}
+	}
+
+	// copy-on-write, opened files keep their snapshot
+	encoded := make(map[string][]byte, len(n.encoded)+1)
+	for k, v := range n.encoded {
+		encoded[k] = v
+	}
+	encoded[encoding] = m.arena.copy(data)
+	n.encoded = encoded
+	return nil
+}
+
+// Link method creates newname as hard link of the virtual file oldname, both
+// paths refer the same data i.e. content and memory is shared, however Stat
+// of each path reports its own name. Parent directory of newname must exist.
+func (m *Mount) Link(oldname, newname string) error {
+	lerr := func(err error) error {
+		if pe, ok := err.(*os.PathError); ok {
+			err = pe.Err
+		}
+		return &os.LinkError{Op: "link", Old: oldname, New: newname, Err: err}
+	}
+
+	if !isPathWithin(oldname, m.Vroot) || !isPathWithin(newname, m.Vroot) || newname == m.Vroot {
+		return lerr(os.ErrInvalid)
+	}
+
+	m.treeMu.Lock()
+	defer m.treeMu.Unlock()
+	if m.sealed {
+		return lerr(ErrReadOnly)
+	}
+	f, err := m.openNode(oldname)
+	if err != nil {
+		return lerr(err)
+	}
+	if f.IsDir() {
+		return lerr(errors.New("is a directory"))
+	}
+	if _, err = m.openNode(newname); err == nil {
+		return lerr(os.ErrExist)
+	}
+
+	if err = f.node.loadData(); err != nil {
+		return lerr(err)
+	}
+	fi := *f.node.NodeInfo
+	fi.Path = newname
+	if err = m.insertNode(&fi, f.node.data); err != nil {
+		return lerr(err)
+	}
+	return nil
+}
+
+// AddLink method adds the file node of given info whose data is shared with
+// the virtual file oldname, i.e. same as `Mount.Link` but the node has its
+// own info, for e.g. modification time. Pre-compressed variants are shared
+// too. `vfs.Binary` generated code uses it for the duplicate file contents.
+func (m *Mount) AddLink(fi os.FileInfo, oldname string) error {
+	ni := fi.(*NodeInfo)
+	lerr := func(err error) error {
+		if pe, ok := err.(*os.PathError); ok {
+			err = pe.Err
+		}
+		return &os.LinkError{Op: "link", Old: oldname, New: ni.Path, Err: err}
+	}
+
+	m.treeMu.Lock()
+	defer m.treeMu.Unlock()
+	if m.sealed {
+		return lerr(ErrReadOnly)
+	}
+	f, err := m.openNode(oldname)
+	if err != nil {
+		return lerr(err)
+	}
+	if f.IsDir() || ni.IsDir() {
+		return lerr(errors.New("is a directory"))
+	}
+	if err = f.node.loadData(); err != nil {
+		return lerr(err)
+	}
+
+	if err = m.insertNode(ni, f.node.data); err != nil {
+		return err
+	}
+	if n, found := m.tree.lookup(strings.TrimPrefix(ni.Path, m.Vroot)); found {
+		n.Gzip, n.Raw = f.node.Gzip, f.node.Raw
+		n.encoded = f.node.encoded
+	}
+	return nil
+}
+
+// RemoveFile method removes the virtual file or symbolic link name from the
+// mount, for e.g.: unloading the asset pack of plugin. Physical filesystem
+// is not touched, so the physical file of same name becomes visible.
+// Opened files keep their data.
+func (m *Mount) RemoveFile(name string) error {
+	return m.remove("remove", name, false)
+}
+
+// RemoveDir method removes the virtual directory name and all of its
+// descendants from the mount, see `Mount.RemoveFile`. Mount root cannot be
+// removed.
+func (m *Mount) RemoveDir(name string) error {
+	return m.remove("removedir", name, true)
+}
+
+// Rename method moves the virtual file or directory oldpath to newpath, its
+// behaviour is same as `os.Rename`. Existing file at newpath is replaced,
+// existing directory at newpath returns an error. Parent directory of
+// newpath must exist in the virtual tree.
+func (m *Mount) Rename(oldpath, newpath string) error {
+	lerr := func(err error) error {
+		if pe, ok := err.(*os.PathError); ok {
+			err = pe.Err
+		}
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: err}
+	}
+
+	op, err := cleanPath("rename", oldpath)
+	if err != nil {
+		return lerr(err)
+	}
+	np, err := cleanPath("rename", newpath)
+	if err != nil {
+		return lerr(err)
+	}
+	if !isPathWithin(op, m.Vroot) || !isPathWithin(np, m.Vroot) ||
+		op == m.Vroot || np == m.Vroot || (np != op && isPathWithin(np, op)) {
+		return lerr(os.ErrInvalid)
+	}
+
+	m.treeMu.Lock()
+	defer m.treeMu.Unlock()
+	if m.sealed {
+		return lerr(ErrReadOnly)
+	}
+	n, found := m.lookupNode(op)
+	if !found {
+		return lerr(os.ErrNotExist)
+	}
+	if op == np {
+		return nil
+	}
+
+	parent, found := m.lookupNode(path.Dir(np))
+	if !found || !parent.IsDir() {
+		return lerr(os.ErrNotExist)
+	}
+	if t, found := parent.childs[path.Base(np)]; found {
+		if t.IsDir() {
+			return lerr(os.ErrExist)
+		}
+		parent.removeChild(t.Name())
+		m.forgetNode(t)
+		if links := countSymlinks(t); links > 0 {
+			atomic.AddInt32(&m.symlinks, -links)
+		}
+	}
+
+	oldParent, _ := m.lookupNode(path.Dir(n.Path))
+	oldParent.removeChild(n.Name())
+	m.forgetNode(n)
+
+	parent.addChild(n.clone(np))
+	debugValidate(m, parent, false)
+	return nil
+}
+
+// OpenFiles method returns the count of currently opened files of the mount,
+// virtual files and physical files (which holds OS file descriptor).
+func (m *Mount) OpenFiles() (virtual, physical int) {
+	return int(atomic.LoadInt32(&m.virtualFiles)), int(atomic.LoadInt32(&m.physicalFiles))
+}
+
+// Seal method makes the populated virtual tree of the mount read-only,
+// `Mount.AddDir`, `Mount.AddFile`, `Mount.Link`, `Mount.AddLink`,
+// `Mount.AddEncoded`, `Mount.Symlink`, `Mount.RemoveFile`, `Mount.RemoveDir`
+// and `Mount.Rename` return `ErrReadOnly` afterwards. Physical filesystem is
+// not affected.
+func (m *Mount) Seal() {
+	m.treeMu.Lock()
+	m.sealed = true
+	m.treeMu.Unlock()
+}
+
+// SetMaxOpenFiles method sets the ceiling for concurrently opened physical
+// files of the mount, beyond it Open returns `ErrTooManyOpenFiles`. Value
+// 0 means no limit. It protects the application from file descriptor leaks.
+func (m *Mount) SetMaxOpenFiles(n int) {
+	atomic.StoreInt32(&m.maxOpenFiles, int32(n))
+}
+
+// SetEmbeddedOnly method enables or disables the physical filesystem fallback
+// of the mount at runtime, for e.g.: application switches to embedded only
+// after startup in production. Physical files opened already are not
+// affected.
+func (m *Mount) SetEmbeddedOnly(b bool) {
+	var v int32
+	if b {
+		v = 1
+	}
+	atomic.StoreInt32(&m.embeddedOnly, v)
+}
+
+// IsEmbeddedOnly method returns true if physical filesystem fallback of the
+// mount is disabled.
+func (m *Mount) IsEmbeddedOnly() bool {
+	return atomic.LoadInt32(&m.embeddedOnly) == 1
+}
+
+// AddCloser method registers the resource held by mount backend (archive
+// handle, watcher, network client, etc.), it gets closed on `Mount.Close`.
+func (m *Mount) AddCloser(c io.Closer) {
+	m.closeMu.Lock()
+	defer m.closeMu.Unlock()
+	m.closers = append(m.closers, c)
+}
+
+// Close method releases the resources registered via `Mount.AddCloser` in
+// reverse order of registration and returns the first error, after the
+// background prefetch of `vfs.TrackAccess` is done. Calling it more than
+// once is no-op.
+func (m *Mount) Close() error {
+	m.prefetchWg.Wait()
+	m.closeMu.Lock()
+	closers := m.closers
+	m.closers = nil
+	m.closeMu.Unlock()
+
+	var err error
+	for i := len(closers) - 1; i >= 0; i-- {
+		if cerr := closers[i].Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
 }
 
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
 // Mount unexported methods
 //______________________________________________________________________________
 
-func (m Mount) cleanDir(p string) string {
+// newMount method creates the mount, source path is used as-is.
+func newMount(vroot, source string, opts ...MountOption) (*Mount, error) {
+	var pp string
+	if source != "" {
+		if !filepath.IsAbs(source) {
+			return nil, ErrNotAbsolutPath
+		}
+		pp = filepath.Clean(source)
+	}
+
+	mp := path.Clean("/" + filepath.ToSlash(vroot))
+	m := &Mount{
+		Vroot: mp,
+		Proot: pp,
+		tree:  newNode(mp, &NodeInfo{Dir: true, Time: time.Now().UTC()}),
+		cache: newDataCache(),
+		arena: newDataArena(defaultArenaChunkSize),
+		strs:  newStringPool(),
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m, nil
+}
+
+func (m *Mount) cleanDir(p string) string {
 	dp := strings.TrimPrefix(p, m.Vroot)
 	return path.Dir(dp)
 }
 
-func (m Mount) open(name string) (*file, error) {
+// openFile method opens the name from virtual tree or physical filesystem,
+// physical is true for the latter.
+func (m *Mount) openFile(name string) (File, bool, error) {
+	name, err := cleanPath("open", name)
+	if err != nil {
+		return nil, false, err
+	}
+	if name, err = m.followLinks("open", name, true); err != nil {
+		return nil, false, err
+	}
+	if m.preferPhysical {
+		if pf, err := m.openPhysical(name); !os.IsNotExist(err) {
+			if err == nil && m.access != nil {
+				m.access.hit(name)
+			}
+			return pf, true, err
+		}
+	}
+	f, err := m.open(name)
+	if os.IsNotExist(err) || (err == nil && f.Physical) {
+		pf, err := m.openPhysical(name)
+		if err == nil && m.access != nil {
+			m.access.hit(name)
+		}
+		return pf, true, err
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	release, err := m.acquireData("open", name)
+	if err != nil {
+		return nil, false, err
+	}
+	if f.Encrypted {
+		if f, err = m.openDecrypted(f); err != nil {
+			release()
+			return nil, false, err
+		}
+	}
+	if m.access != nil {
+		m.access.hit(f.node.Path)
+	}
+	if data, found := m.cache.get(f.node.Path); found {
+		f.rs = bytes.NewReader(data.([]byte))
+	}
+
+	atomic.AddInt32(&m.virtualFiles, 1)
+	f.onClose = func() {
+		atomic.AddInt32(&m.virtualFiles, -1)
+		release()
+	}
+	return f, false, nil
+}
+
+// acquireData method holds the memory-mapped data of blob mount until the
+// returned func is called, so the mapping is not released while it is
+// read. It returns `os.ErrClosed` if the mount is closed.
+func (m *Mount) acquireData(op, name string) (func(), error) {
+	if m.mapping == nil {
+		return func() {}, nil
+	}
+	release, ok := m.mapping.acquire()
+	if !ok {
+		return nil, &os.PathError{Op: op, Path: name, Err: os.ErrClosed}
+	}
+	return release, nil
+}
+
+func (m *Mount) open(name string) (*file, error) {
+	m.treeMu.RLock()
+	defer m.treeMu.RUnlock()
+	return m.openNode(name)
+}
+
+// openNode method is same as `Mount.open`, caller holds the tree lock.
+func (m *Mount) openNode(name string) (*file, error) {
 	if m.isTreeEmpty() {
 		return nil, os.ErrNotExist
 	}
@@ -175,25 +862,229 @@ func (m Mount) open(name string) (*file, error) {
 		return newFile(m.tree), nil
 	}
 
+	if m.caseFold {
+		n, found := m.tree.lookupFold(strings.TrimPrefix(name, m.Vroot))
+		if !found {
+			return nil, os.ErrNotExist
+		}
+		return newFile(n), nil
+	}
+
 	return m.tree.find(strings.TrimPrefix(name, m.Vroot))
 }
 
-func (m Mount) openPhysical(name string) (File, error) {
-	pname := m.toPhysicalPath(name)
-	if _, err := os.Lstat(pname); os.IsNotExist(err) {
+// stat method resolves the given name on virtual tree and then on physical
+// filesystem. Symbolic links are followed only if follow is true.
+func (m *Mount) stat(name string, follow bool) (os.FileInfo, error) {
+	name, err := cleanPath("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	if name, err = m.followLinks("stat", name, follow); err != nil {
+		return nil, err
+	}
+	if m.preferPhysical {
+		if fi, err := m.statPhysical(name, follow); !os.IsNotExist(err) {
+			return fi, err
+		}
+	}
+	f, err := m.open(name)
+	if err == nil {
+		return f, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+	return m.statPhysical(name, follow)
+}
+
+// statPhysical method resolves the given clean name on physical filesystem,
+// result is served from stat cache if enabled.
+func (m *Mount) statPhysical(name string, follow bool) (os.FileInfo, error) {
+	if !m.hasPhysical() {
+		return nil, &os.PathError{Op: "stat", Path: name, Err: os.ErrNotExist}
+	}
+	if m.statCache == nil {
+		return m.statPhysicalFS(name, follow)
+	}
+	if e, found := m.statCache.get(name, follow); found {
+		return e.fi, e.err
+	}
+	fi, err := m.statPhysicalFS(name, follow)
+	m.statCache.put(name, follow, fi, err)
+	return fi, err
+}
+
+// statPhysicalFS method is same as `Mount.statPhysical` without stat cache.
+func (m *Mount) statPhysicalFS(name string, follow bool) (os.FileInfo, error) {
+	pname, err := m.physicalPath("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	if m.lazy != nil {
+		fi, err := m.lazy.lstat(pname, m.Proot)
+		if err != nil || !follow || fi.Mode()&os.ModeSymlink == 0 {
+			return fi, err
+		}
+	}
+	if follow {
+		return os.Stat(pname)
+	}
+	return os.Lstat(pname)
+}
+
+// readDirPhysical method reads the given clean directory name from physical
+// filesystem.
+func (m *Mount) readDirPhysical(dirname string) ([]os.FileInfo, error) {
+	if !m.hasPhysical() {
+		return nil, &os.PathError{Op: "open", Path: dirname, Err: os.ErrNotExist}
+	}
+	pname, err := m.physicalPath("open", dirname)
+	if err != nil {
+		return nil, err
+	}
+	if m.lazy != nil {
+		return m.lazy.readDir(pname)
+	}
+	return ioutil.ReadDir(pname)
+}
+
+// readDirFuncPhysical method is same as `Mount.readDirPhysical` but calls fn
+// for each entry, see `Mount.ReadDirFunc`.
+func (m *Mount) readDirFuncPhysical(dirname string, fn func(os.FileInfo) error) error {
+	if !m.hasPhysical() {
+		return &os.PathError{Op: "open", Path: dirname, Err: os.ErrNotExist}
+	}
+	pname, err := m.physicalPath("open", dirname)
+	if err != nil {
+		return err
+	}
+	if m.lazy != nil {
+		infos, err := m.lazy.readDir(pname)
+		if err != nil {
+			return err
+		}
+		return ignoreStop(eachFileInfo(infos, fn))
+	}
+	return ignoreStop(readDirFunc(pname, fn))
+}
+
+func (m *Mount) openPhysical(name string) (File, error) {
+	if !m.hasPhysical() {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+
+	pname, err := m.physicalPath("open", name)
+	if err != nil {
 		return nil, err
 	}
-	return os.Open(pname)
+	if m.lazy != nil {
+		if _, err := m.lazy.lstat(pname, m.Proot); err != nil {
+			return nil, err
+		}
+	} else if _, err := os.Lstat(pname); os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if m.gzCache != nil {
+		f, ok, err := m.openCompressed(name, pname)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			atomic.AddInt32(&m.virtualFiles, 1)
+			f.onClose = func() { atomic.AddInt32(&m.virtualFiles, -1) }
+			return f, nil
+		}
+	}
+
+	cnt := atomic.AddInt32(&m.physicalFiles, 1)
+	release := func() { atomic.AddInt32(&m.physicalFiles, -1) }
+	if max := atomic.LoadInt32(&m.maxOpenFiles); max > 0 && cnt > max {
+		release()
+		return nil, &os.PathError{Op: "open", Path: name, Err: ErrTooManyOpenFiles}
+	}
+
+	f, err := os.Open(pname)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return &physicalFile{File: f, onClose: release}, nil
 }
 
-func (m Mount) toPhysicalPath(name string) string {
-	if strings.HasPrefix(name, m.Proot) {
+func (m *Mount) toPhysicalPath(name string) string {
+	if isPhysicalWithin(name, m.Proot) {
 		return name
 	}
 	return filepath.Clean(filepath.FromSlash(
 		filepath.Join(m.Proot, strings.TrimPrefix(name, m.Vroot))))
 }
 
+// physicalPath method returns the physical path of given clean virtual name.
+// Path resolving outside of the mount source, lexically or via symbolic
+// links, returns `os.ErrPermission` unless `vfs.AllowExternalLinks` option
+// is set. Non-existent path is not verified further.
+func (m *Mount) physicalPath(op, name string) (string, error) {
+	pname := m.toPhysicalPath(name)
+	denied := &os.PathError{Op: op, Path: name, Err: os.ErrPermission}
+	if !isPhysicalWithin(pname, m.Proot) {
+		return "", denied
+	}
+	if m.externalLinks || pname == m.Proot {
+		return pname, nil
+	}
+	if !m.resolvesWithin(pname) {
+		return "", denied
+	}
+	return pname, nil
+}
+
+// resolvesWithin method returns true if the physical path within Proot does
+// not resolve outside of the mount source via symbolic links. Proot is
+// resolved once (see `Mount.realProot`), so only the elements below it are
+// checked, one lstat per element and the link is evaluated only where
+// found. Non-existent path is within.
+func (m *Mount) resolvesWithin(pname string) bool {
+	rel, err := filepath.Rel(m.Proot, pname)
+	if err != nil {
+		return false
+	}
+	root := m.realProot()
+	cur := root
+	for _, s := range strings.Split(rel, string(filepath.Separator)) {
+		next := filepath.Join(cur, s)
+		fi, err := os.Lstat(next)
+		if err != nil {
+			return true
+		}
+		if fi.Mode()&os.ModeSymlink == 0 {
+			cur = next
+			continue
+		}
+		if next, err = filepath.EvalSymlinks(next); err != nil {
+			return true
+		}
+		if !isPhysicalWithin(next, root) && !isPhysicalWithin(next, m.Proot) {
+			return false
+		}
+		cur = next
+	}
+	return true
+}
+
+// realProot method returns the Proot with symbolic links evaluated, Proot
+// as-is if it cannot be evaluated.
+func (m *Mount) realProot() string {
+	m.realRootOnce.Do(func() {
+		m.realRoot = m.Proot
+		if r, err := filepath.EvalSymlinks(m.Proot); err == nil {
+			m.realRoot = r
+		}
+	})
+	return m.realRoot
+}
+
 func (m *Mount) toVirtualPath(name string) string {
 	if strings.HasPrefix(name, m.Vroot) {
 		return name
@@ -202,7 +1093,62 @@ func (m *Mount) toVirtualPath(name string) string {
 		filepath.Join(m.Vroot, strings.TrimPrefix(name, m.Proot))))
 }
 
+// physicalToVirtual method returns the virtual path of given physical path
+// within physical root. Unlike `Mount.toVirtualPath`, it is correct when
+// physical root lies under virtual root path.
+func (m *Mount) physicalToVirtual(fpath string) string {
+	rel, err := filepath.Rel(m.Proot, fpath)
+	if err != nil {
+		return m.toVirtualPath(fpath)
+	}
+	return path.Join(m.Vroot, filepath.ToSlash(rel))
+}
+
+// checkSealed method returns `ErrReadOnly` if the mount is sealed, see
+// `Mount.Seal`.
+func (m *Mount) checkSealed(op string, fi os.FileInfo) error {
+	m.treeMu.RLock()
+	defer m.treeMu.RUnlock()
+	if m.sealed {
+		return &os.PathError{Op: op, Path: fi.(*NodeInfo).Path, Err: ErrReadOnly}
+	}
+	return nil
+}
+
+// sealReadOnly method seals the populated mount if it has `vfs.ReadOnly`
+// option.
+func (m *Mount) sealReadOnly() {
+	if m.readOnly {
+		m.Seal()
+	}
+}
+
+// readGzipped method returns the gzip compressed data of r, allocated from
+// mount data arena, and the no. of bytes read.
+func (m *Mount) readGzipped(r io.Reader) ([]byte, int64, error) {
+	buf := new(bytes.Buffer)
+	gw, err := gzip.NewWriterLevel(buf, m.addLevel)
+	if err != nil {
+		return nil, 0, err
+	}
+	size, err := io.Copy(gw, r)
+	if err == nil {
+		err = gw.Close()
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	return m.arena.copy(buf.Bytes()), size, nil
+}
+
 func (m *Mount) addNode(fi os.FileInfo, data []byte) error {
+	m.treeMu.Lock()
+	defer m.treeMu.Unlock()
+	return m.insertNode(fi, data)
+}
+
+// insertNode method is same as `Mount.addNode`, caller holds the tree lock.
+func (m *Mount) insertNode(fi os.FileInfo, data []byte) error {
 	mountPath := fi.(*NodeInfo).Path
 	t, err := m.tree.findNode(m.cleanDir(mountPath))
 	switch {
@@ -210,22 +1156,152 @@ func (m *Mount) addNode(fi os.FileInfo, data []byte) error {
 		return err
 	case t == nil:
 		return nil
+	case m.strict && (t.Path != path.Dir(mountPath) || !t.IsDir()):
+		return &os.PathError{Op: "addnode", Path: mountPath, Err: errors.New("parent directory does not exist")}
 	}
 
 	n := newNode(mountPath, fi)
+	n.SHA256 = m.strs.intern(n.SHA256)
+	n.Symlink = m.strs.intern(n.Symlink)
 	if data != nil {
 		n.data = data
 	}
+	if old, found := t.childs[n.Name()]; found {
+		m.forgetNode(old) // replaced, drop its warmed data
+		if links := countSymlinks(old); links > 0 {
+			atomic.AddInt32(&m.symlinks, -links)
+		}
+	}
 	t.addChild(n)
+	if n.Symlink != "" {
+		atomic.AddInt32(&m.symlinks, 1)
+	}
+	debugValidate(m, t, false)
+
+	return nil
+}
+
+// addSourceNode method adds the file node, its data is loaded on first read
+// from given load func.
+func (m *Mount) addSourceNode(fi *NodeInfo, load func() ([]byte, error)) error {
+	m.treeMu.Lock()
+	defer m.treeMu.Unlock()
+	if err := m.insertNode(fi, nil); err != nil {
+		return err
+	}
+	if n, found := m.tree.lookup(strings.TrimPrefix(fi.Path, m.Vroot)); found {
+		n.src = &nodeSource{load: load}
+	}
+	return nil
+}
+
+// mkdirAll method creates the missing directory nodes of given virtual
+// directory path within mount.
+func (m *Mount) mkdirAll(dir string, modTime time.Time) error {
+	if !isPathWithin(dir, m.Vroot) {
+		return &os.PathError{Op: "mkdir", Path: dir, Err: os.ErrInvalid}
+	}
+
+	m.treeMu.Lock()
+	defer m.treeMu.Unlock()
+	n, p := m.tree, m.Vroot
+	for _, s := range splitPath(strings.TrimPrefix(dir, m.Vroot)) {
+		p = path.Join(p, s)
+		c, found := n.childs[s]
+		if !found {
+			if err := m.insertNode(&NodeInfo{Dir: true, Path: p, Time: modTime}, nil); err != nil {
+				return err
+			}
+			c = n.childs[s]
+		}
+		if !c.IsDir() {
+			return &os.PathError{Op: "mkdir", Path: p, Err: errors.New("not a directory")}
+		}
+		n = c
+	}
+	return nil
+}
+
+// remove method removes the virtual node name, dir tells the expected kind.
+func (m *Mount) remove(op, name string, dir bool) error {
+	name, err := cleanPath(op, name)
+	if err != nil {
+		return err
+	}
+	if !isPathWithin(name, m.Vroot) || name == m.Vroot {
+		return &os.PathError{Op: op, Path: name, Err: os.ErrInvalid}
+	}
+
+	m.treeMu.Lock()
+	defer m.treeMu.Unlock()
+	if m.sealed {
+		return &os.PathError{Op: op, Path: name, Err: ErrReadOnly}
+	}
+	n, found := m.lookupNode(name)
+	if !found {
+		return &os.PathError{Op: op, Path: name, Err: os.ErrNotExist}
+	}
+	switch {
+	case dir && !n.IsDir():
+		return &os.PathError{Op: op, Path: name, Err: errors.New("not a directory")}
+	case !dir && n.IsDir():
+		return &os.PathError{Op: op, Path: name, Err: errors.New("is a directory")}
+	}
 
+	parent, _ := m.lookupNode(path.Dir(n.Path))
+	parent.removeChild(n.Name())
+	m.forgetNode(n)
+	if links := countSymlinks(n); links > 0 {
+		atomic.AddInt32(&m.symlinks, -links)
+	}
+	debugValidate(m, parent, false)
 	return nil
+}
+
+// lookupNode method returns the virtual node of given clean name, caller
+// holds the tree lock.
+func (m *Mount) lookupNode(name string) (*node, bool) {
+	rel := strings.TrimPrefix(name, m.Vroot)
+	if m.caseFold {
+		return m.tree.lookupFold(rel)
+	}
+	return m.tree.lookup(rel)
+}
+
+// forgetNode method drops the cached data of node and its descendants.
+func (m *Mount) forgetNode(n *node) {
+	m.cache.remove(n.Path)
+	m.fpMu.Lock()
+	delete(m.fingerprints, n.Path)
+	m.fpMu.Unlock()
+	for _, c := range n.childs {
+		m.forgetNode(c)
+	}
+}
 
+// countSymlinks method returns the no. of symbolic links in node and its
+// descendants.
+func countSymlinks(n *node) int32 {
+	var cnt int32
+	if n.Symlink != "" {
+		cnt++
+	}
+	for _, c := range n.childs {
+		cnt += countSymlinks(c)
+	}
+	return cnt
 }
 
 func (m *Mount) match(name string) bool {
 	return m.Vroot == name ||
 		strings.HasPrefix(name, m.tree.Path+"/") ||
-		strings.HasPrefix(name, m.Proot)
+		(m.hasPhysical() && strings.HasPrefix(name, m.Proot))
+}
+
+// hasPhysical method returns true if mount has physical filesystem fallback
+// and it is not disabled.
+func (m *Mount) hasPhysical() bool {
+	return m.Proot != "" && !m.IsEmbeddedOnly()
 }
 
 func (m *Mount) isTreeEmpty() bool {
@@ -0,0 +1,219 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package ninep
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/fnv"
+	"io"
+	"os"
+)
+
+// 9P2000 message types
+const (
+	tversion = 100 + iota
+	rversion
+	tauth
+	rauth
+	tattach
+	rattach
+	_ // terror, not used
+	rerror
+	tflush
+	rflush
+	twalk
+	rwalk
+	topen
+	ropen
+	tcreate
+	rcreate
+	tread
+	rread
+	twrite
+	rwrite
+	tclunk
+	rclunk
+	tremove
+	rremove
+	tstat
+	rstat
+	twstat
+	rwstat
+)
+
+// 9P2000 constants
+const (
+	version   = "9P2000"
+	maxWalk   = 16
+	qtdir     = 0x80
+	dmdir     = 0x80000000
+	oread     = 0
+	oexec     = 3
+	omodeMask = 3
+	otrunc    = 0x10
+	orclose   = 0x40
+
+	// headerSize is size[4] type[1] tag[2]
+	headerSize = 7
+
+	// ioHeaderSize is the header size of Rread, size[4] type[1] tag[2] count[4]
+	ioHeaderSize = headerSize + 4
+)
+
+var errShortMessage = errors.New("ninep: short message")
+
+// qid represents the server's unique identification of a file.
+type qid struct {
+	typ  uint8
+	vers uint32
+	path uint64
+}
+
+func qidOf(name string, fi os.FileInfo) qid {
+	h := fnv.New64a()
+	_, _ = io.WriteString(h, name)
+	q := qid{vers: uint32(fi.ModTime().Unix()), path: h.Sum64()}
+	if fi.IsDir() {
+		q.typ = qtdir
+	}
+	return q
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Message encoding
+//______________________________________________________________________________
+
+// encoder builds the 9P message, size is filled in by bytes method.
+type encoder struct {
+	buf []byte
+}
+
+func newMessage(typ uint8, tag uint16) *encoder {
+	e := &encoder{buf: make([]byte, 4, 64)}
+	e.u8(typ)
+	e.u16(tag)
+	return e
+}
+
+func (e *encoder) u8(v uint8) {
+	e.buf = append(e.buf, v)
+}
+
+func (e *encoder) u16(v uint16) {
+	e.buf = append(e.buf, byte(v), byte(v>>8))
+}
+
+func (e *encoder) u32(v uint32) {
+	e.buf = append(e.buf, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+}
+
+func (e *encoder) u64(v uint64) {
+	e.u32(uint32(v))
+	e.u32(uint32(v >> 32))
+}
+
+func (e *encoder) str(s string) {
+	e.u16(uint16(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+func (e *encoder) qid(q qid) {
+	e.u8(q.typ)
+	e.u32(q.vers)
+	e.u64(q.path)
+}
+
+func (e *encoder) bytes() []byte {
+	binary.LittleEndian.PutUint32(e.buf, uint32(len(e.buf)))
+	return e.buf
+}
+
+// stat method encodes the 9P stat structure of given file info.
+func stat(name string, fi os.FileInfo) []byte {
+	e := &encoder{buf: make([]byte, 2, 64)}
+	mode := uint32(fi.Mode().Perm())
+	if fi.IsDir() {
+		mode |= dmdir
+	}
+	var length uint64
+	if !fi.IsDir() {
+		length = uint64(fi.Size())
+	}
+	mtime := uint32(fi.ModTime().Unix())
+
+	e.u16(0) // type
+	e.u32(0) // dev
+	e.qid(qidOf(name, fi))
+	e.u32(mode)
+	e.u32(mtime) // atime
+	e.u32(mtime)
+	e.u64(length)
+	e.str(fi.Name())
+	e.str(owner) // uid
+	e.str(owner) // gid
+	e.str(owner) // muid
+	binary.LittleEndian.PutUint16(e.buf, uint16(len(e.buf)-2))
+	return e.buf
+}
+
+// decoder reads the fields of 9P message, first error is sticky.
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) next(n int) []byte {
+	if d.err != nil {
+		return make([]byte, n)
+	}
+	if len(d.buf) < n {
+		d.err = errShortMessage
+		return make([]byte, n)
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
+
+func (d *decoder) u8() uint8 {
+	return d.next(1)[0]
+}
+
+func (d *decoder) u16() uint16 {
+	return binary.LittleEndian.Uint16(d.next(2))
+}
+
+func (d *decoder) u32() uint32 {
+	return binary.LittleEndian.Uint32(d.next(4))
+}
+
+func (d *decoder) u64() uint64 {
+	return binary.LittleEndian.Uint64(d.next(8))
+}
+
+func (d *decoder) str() string {
+	return string(d.next(int(d.u16())))
+}
+
+// readMessage method reads the 9P message of maximum size max, it returns
+// the message type, tag and body.
+func readMessage(r io.Reader, max uint32) (uint8, uint16, *decoder, error) {
+	var hdr [headerSize]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return 0, 0, nil, err
+	}
+
+	size := binary.LittleEndian.Uint32(hdr[:4])
+	if size < headerSize || size > max {
+		return 0, 0, nil, errors.New("ninep: invalid message size")
+	}
+
+	body := make([]byte, size-headerSize)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, 0, nil, err
+	}
+	return hdr[4], binary.LittleEndian.Uint16(hdr[5:7]), &decoder{buf: body}, nil
+}
@@ -0,0 +1,447 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package ninep serves any `vfs.FileSystem` as read-only 9P2000 file server,
+// so remote debugging sessions and standard tooling (for e.g. Linux v9fs,
+// plan9port `9p`) can browse the embedded assets of a running service.
+//
+//	go ninep.ListenAndServe("127.0.0.1:5640", aah.AppVFS())
+//
+// Authentication is not supported, bind the server to trusted interface.
+package ninep
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net"
+	"os"
+	"path"
+	"strings"
+
+	"aahframework.org/vfs.v0"
+)
+
+// owner is reported as uid, gid and muid of the files.
+const owner = "aah"
+
+// DefaultMaxMessageSize is the maximum 9P message size offered by server.
+const DefaultMaxMessageSize = 64 * 1024
+
+// Errors reported to 9P clients
+var (
+	ErrReadOnly      = errors.New("read-only file system")
+	ErrNoAuth        = errors.New("authentication not required")
+	ErrUnknownFid    = errors.New("unknown fid")
+	ErrFidInUse      = errors.New("fid already in use")
+	ErrNotOpen       = errors.New("fid is not open")
+	ErrAlreadyOpen   = errors.New("fid is already open")
+	ErrBadOffset     = errors.New("bad directory read offset")
+	ErrNotDir        = errors.New("not a directory")
+	ErrBadMessage    = errors.New("bad message")
+	ErrNotNegotiated = errors.New("version is not negotiated")
+)
+
+// Server struct serves the FileSystem over 9P2000 protocol, all the
+// modifying operations returns `ErrReadOnly`.
+type Server struct {
+	// FS is the served FileSystem.
+	FS vfs.FileSystem
+
+	// Root is the virtual path served as root of the file tree, default is
+	// `/`.
+	Root string
+
+	// MaxMessageSize is the maximum 9P message size, default is
+	// `DefaultMaxMessageSize`.
+	MaxMessageSize uint32
+
+	// ErrorLog logs the connection errors, default is logger of package
+	// `log`.
+	ErrorLog *log.Logger
+}
+
+// ListenAndServe method listens on the TCP network address addr and serves
+// the FileSystem read-only over 9P2000.
+func ListenAndServe(addr string, fs vfs.FileSystem) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return (&Server{FS: fs}).Serve(l)
+}
+
+// Serve method accepts the connections on listener and serves each of them
+// on new goroutine. It returns when the listener returns an error.
+func (s *Server) Serve(l net.Listener) error {
+	defer func() { _ = l.Close() }()
+	for {
+		c, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go func() {
+			if err := s.ServeConn(c); err != nil && err != io.EOF {
+				s.logf("ninep: %s: %v", c.RemoteAddr(), err)
+			}
+		}()
+	}
+}
+
+// ServeConn method serves the 9P2000 requests of single connection until
+// the connection is closed or protocol error occurs. Connection is closed
+// on return.
+func (s *Server) ServeConn(c io.ReadWriteCloser) error {
+	sc := &conn{s: s, rw: c, msize: s.maxMessageSize(), fids: make(map[uint32]*fid)}
+	defer func() {
+		sc.releaseFids()
+		_ = c.Close()
+	}()
+	return sc.serve()
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Server unexported methods
+//______________________________________________________________________________
+
+func (s *Server) maxMessageSize() uint32 {
+	if s.MaxMessageSize > ioHeaderSize {
+		return s.MaxMessageSize
+	}
+	return DefaultMaxMessageSize
+}
+
+func (s *Server) root() string {
+	if s.Root == "" {
+		return "/"
+	}
+	return path.Clean("/" + s.Root)
+}
+
+func (s *Server) logf(format string, v ...interface{}) {
+	if s.ErrorLog != nil {
+		s.ErrorLog.Printf(format, v...)
+	} else {
+		log.Printf(format, v...)
+	}
+}
+
+// fid represents the file reference of a client.
+type fid struct {
+	name string
+	info os.FileInfo
+	file vfs.File
+
+	// encoded directory entries and their offsets, once directory is open
+	open    bool
+	dirents [][]byte
+	diroffs []uint64
+}
+
+// conn serves single client connection, requests are handled in order.
+type conn struct {
+	s          *Server
+	rw         io.ReadWriteCloser
+	msize      uint32
+	negotiated bool
+	fids       map[uint32]*fid
+}
+
+func (c *conn) serve() error {
+	for {
+		typ, tag, d, err := readMessage(c.rw, c.msize)
+		if err != nil {
+			return err
+		}
+
+		resp := c.handle(typ, tag, d)
+		if _, err = c.rw.Write(resp); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *conn) handle(typ uint8, tag uint16, d *decoder) []byte {
+	if typ != tversion && !c.negotiated {
+		return rerrorMessage(tag, ErrNotNegotiated)
+	}
+
+	var resp *encoder
+	var err error
+	switch typ {
+	case tversion:
+		resp, err = c.version(tag, d)
+	case tauth:
+		err = ErrNoAuth
+	case tattach:
+		resp, err = c.attach(tag, d)
+	case tflush:
+		_ = d.u16()
+		resp = newMessage(rflush, tag)
+	case twalk:
+		resp, err = c.walk(tag, d)
+	case topen:
+		resp, err = c.openFid(tag, d)
+	case tread:
+		resp, err = c.read(tag, d)
+	case tclunk:
+		err = c.clunk(d.u32())
+		resp = newMessage(rclunk, tag)
+	case tremove:
+		// remove clunks the fid even if the remove fails
+		_ = c.clunk(d.u32())
+		err = ErrReadOnly
+	case tstat:
+		resp, err = c.stat(tag, d)
+	case tcreate, twrite, twstat:
+		err = ErrReadOnly
+	default:
+		err = ErrBadMessage
+	}
+
+	if err == nil && d.err != nil {
+		err = ErrBadMessage
+	}
+	if err != nil {
+		return rerrorMessage(tag, err)
+	}
+	return resp.bytes()
+}
+
+func (c *conn) version(tag uint16, d *decoder) (*encoder, error) {
+	msize, ver := d.u32(), d.str()
+	if msize < c.msize {
+		c.msize = msize
+	}
+	if c.msize <= ioHeaderSize {
+		return nil, ErrBadMessage
+	}
+
+	// version request aborts all the outstanding I/O
+	c.releaseFids()
+
+	resp := newMessage(rversion, tag)
+	resp.u32(c.msize)
+	if strings.HasPrefix(ver, version) {
+		c.negotiated = true
+		resp.str(version)
+	} else {
+		c.negotiated = false
+		resp.str("unknown")
+	}
+	return resp, nil
+}
+
+func (c *conn) attach(tag uint16, d *decoder) (*encoder, error) {
+	fno, _, _, _ := d.u32(), d.u32(), d.str(), d.str()
+	if _, found := c.fids[fno]; found {
+		return nil, ErrFidInUse
+	}
+
+	root := c.s.root()
+	fi, err := c.s.FS.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return nil, ErrNotDir
+	}
+
+	c.fids[fno] = &fid{name: root, info: fi}
+	resp := newMessage(rattach, tag)
+	resp.qid(qidOf(root, fi))
+	return resp, nil
+}
+
+func (c *conn) walk(tag uint16, d *decoder) (*encoder, error) {
+	fno, newfno, n := d.u32(), d.u32(), int(d.u16())
+	if n > maxWalk {
+		return nil, ErrBadMessage
+	}
+	names := make([]string, n)
+	for i := range names {
+		names[i] = d.str()
+	}
+
+	f, found := c.fids[fno]
+	if !found {
+		return nil, ErrUnknownFid
+	}
+	if f.open {
+		return nil, ErrAlreadyOpen
+	}
+	if _, found := c.fids[newfno]; found && newfno != fno {
+		return nil, ErrFidInUse
+	}
+
+	root := c.s.root()
+	name, info := f.name, f.info
+	var qids []qid
+	for i, elem := range names {
+		if !info.IsDir() || elem == "" || elem == "." || strings.ContainsRune(elem, '/') {
+			if i == 0 {
+				return nil, ErrNotDir
+			}
+			break
+		}
+
+		next := path.Join(name, elem)
+		if elem == ".." && (name == root || !isWithin(next, root)) {
+			next = root
+		}
+		fi, err := c.s.FS.Stat(next)
+		if err != nil {
+			if i == 0 {
+				return nil, err
+			}
+			break
+		}
+		name, info = next, fi
+		qids = append(qids, qidOf(name, info))
+	}
+
+	// newfid is affected only if all the elements are walked
+	if len(qids) == n {
+		c.fids[newfno] = &fid{name: name, info: info}
+	}
+
+	resp := newMessage(rwalk, tag)
+	resp.u16(uint16(len(qids)))
+	for _, q := range qids {
+		resp.qid(q)
+	}
+	return resp, nil
+}
+
+func (c *conn) openFid(tag uint16, d *decoder) (*encoder, error) {
+	fno, mode := d.u32(), d.u8()
+	f, found := c.fids[fno]
+	if !found {
+		return nil, ErrUnknownFid
+	}
+	if f.open {
+		return nil, ErrAlreadyOpen
+	}
+	if m := mode & omodeMask; (m != oread && m != oexec) || mode&(otrunc|orclose) != 0 {
+		return nil, ErrReadOnly
+	}
+
+	if f.info.IsDir() {
+		infos, err := c.s.FS.ReadDir(f.name)
+		if err != nil {
+			return nil, err
+		}
+		var off uint64
+		for _, fi := range infos {
+			ent := stat(path.Join(f.name, fi.Name()), fi)
+			f.dirents = append(f.dirents, ent)
+			f.diroffs = append(f.diroffs, off)
+			off += uint64(len(ent))
+		}
+	} else {
+		file, err := c.s.FS.Open(f.name)
+		if err != nil {
+			return nil, err
+		}
+		f.file = file
+	}
+	f.open = true
+
+	resp := newMessage(ropen, tag)
+	resp.qid(qidOf(f.name, f.info))
+	resp.u32(c.msize - ioHeaderSize)
+	return resp, nil
+}
+
+func (c *conn) read(tag uint16, d *decoder) (*encoder, error) {
+	fno, offset, count := d.u32(), d.u64(), d.u32()
+	f, found := c.fids[fno]
+	if !found {
+		return nil, ErrUnknownFid
+	}
+	if !f.open {
+		return nil, ErrNotOpen
+	}
+	if max := c.msize - ioHeaderSize; count > max {
+		count = max
+	}
+
+	var data []byte
+	if f.info.IsDir() {
+		// directory read offset must be the end of previous read
+		i := 0
+		for i < len(f.diroffs) && f.diroffs[i] < offset {
+			i++
+		}
+		if i < len(f.diroffs) && f.diroffs[i] != offset {
+			return nil, ErrBadOffset
+		}
+		for ; i < len(f.dirents) && len(data)+len(f.dirents[i]) <= int(count); i++ {
+			data = append(data, f.dirents[i]...)
+		}
+	} else {
+		if _, err := f.file.Seek(int64(offset), io.SeekStart); err != nil {
+			return nil, err
+		}
+		data = make([]byte, count)
+		n, err := io.ReadFull(f.file, data)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return nil, err
+		}
+		data = data[:n]
+	}
+
+	resp := newMessage(rread, tag)
+	resp.u32(uint32(len(data)))
+	resp.buf = append(resp.buf, data...)
+	return resp, nil
+}
+
+func (c *conn) stat(tag uint16, d *decoder) (*encoder, error) {
+	f, found := c.fids[d.u32()]
+	if !found {
+		return nil, ErrUnknownFid
+	}
+
+	st := stat(f.name, f.info)
+	resp := newMessage(rstat, tag)
+	resp.u16(uint16(len(st)))
+	resp.buf = append(resp.buf, st...)
+	return resp, nil
+}
+
+func (c *conn) clunk(fno uint32) error {
+	f, found := c.fids[fno]
+	if !found {
+		return ErrUnknownFid
+	}
+	delete(c.fids, fno)
+	if f.file != nil {
+		return f.file.Close()
+	}
+	return nil
+}
+
+// releaseFids method clunks all the fids of connection.
+func (c *conn) releaseFids() {
+	for _, f := range c.fids {
+		if f.file != nil {
+			_ = f.file.Close()
+		}
+	}
+	c.fids = make(map[uint32]*fid)
+}
+
+func isWithin(name, dir string) bool {
+	return name == dir || dir == "/" || strings.HasPrefix(name, dir+"/")
+}
+
+func rerrorMessage(tag uint16, err error) []byte {
+	e := newMessage(rerror, tag)
+	if pe, ok := err.(*os.PathError); ok {
+		err = pe.Err
+	}
+	e.str(err.Error())
+	return e.bytes()
+}
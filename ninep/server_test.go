@@ -0,0 +1,126 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package ninep
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"aahframework.org/test.v0/assert"
+	"aahframework.org/vfs.v0"
+)
+
+func TestNinepServer(t *testing.T) {
+	c := newTestClient(t)
+	defer func() { _ = c.conn.Close() }()
+
+	// version and attach
+	typ, d := c.rpc(tversion, func(e *encoder) { e.u32(8192); e.str("9P2000.u") })
+	assert.Equal(t, uint8(rversion), typ)
+	assert.Equal(t, uint32(8192), d.u32())
+	assert.Equal(t, version, d.str())
+
+	typ, d = c.rpc(tauth, func(e *encoder) { e.u32(0); e.str("user"); e.str("") })
+	assert.Equal(t, uint8(rerror), typ)
+	assert.Equal(t, ErrNoAuth.Error(), d.str())
+
+	typ, d = c.rpc(tattach, func(e *encoder) { e.u32(1); e.u32(0xFFFFFFFF); e.str("user"); e.str("") })
+	assert.Equal(t, uint8(rattach), typ)
+	assert.Equal(t, uint8(qtdir), d.u8())
+
+	// walk to file, partial walk and escape above root
+	typ, d = c.rpc(twalk, func(e *encoder) { e.u32(1); e.u32(2); e.u16(2); e.str("css"); e.str("aah.css") })
+	assert.Equal(t, uint8(rwalk), typ)
+	assert.Equal(t, uint16(2), d.u16())
+
+	typ, d = c.rpc(twalk, func(e *encoder) { e.u32(1); e.u32(3); e.u16(2); e.str("css"); e.str("missing.css") })
+	assert.Equal(t, uint8(rwalk), typ)
+	assert.Equal(t, uint16(1), d.u16())
+
+	typ, d = c.rpc(twalk, func(e *encoder) { e.u32(1); e.u32(3); e.u16(1); e.str("..") })
+	assert.Equal(t, uint8(rwalk), typ)
+	assert.Equal(t, uint16(1), d.u16())
+	q := d.next(13)
+	typ, d = c.rpc(tstat, func(e *encoder) { e.u32(3) })
+	assert.Equal(t, uint8(rstat), typ)
+	_, _ = d.u16(), d.u16()
+	_, _ = d.u16(), d.u32()
+	assert.Equal(t, q, d.next(13)) // still root
+
+	// read file
+	typ, _ = c.rpc(topen, func(e *encoder) { e.u32(2); e.u8(oread) })
+	assert.Equal(t, uint8(ropen), typ)
+	typ, d = c.rpc(tread, func(e *encoder) { e.u32(2); e.u64(5); e.u32(100) })
+	assert.Equal(t, uint8(rread), typ)
+	assert.Equal(t, "{ color: red; }", string(d.next(int(d.u32()))))
+
+	// write is not allowed
+	typ, d = c.rpc(twrite, func(e *encoder) { e.u32(2); e.u64(0); e.u32(1); e.u8('x') })
+	assert.Equal(t, uint8(rerror), typ)
+	assert.Equal(t, ErrReadOnly.Error(), d.str())
+	typ, _ = c.rpc(topen, func(e *encoder) { e.u32(3); e.u8(1) })
+	assert.Equal(t, uint8(rerror), typ)
+
+	// read directory
+	typ, _ = c.rpc(topen, func(e *encoder) { e.u32(3); e.u8(oread) })
+	assert.Equal(t, uint8(ropen), typ)
+	typ, d = c.rpc(tread, func(e *encoder) { e.u32(3); e.u64(0); e.u32(8000) })
+	assert.Equal(t, uint8(rread), typ)
+	var names []string
+	dd := &decoder{buf: d.next(int(d.u32()))}
+	for len(dd.buf) > 0 {
+		sd := &decoder{buf: dd.next(int(dd.u16()))}
+		_, _, _ = sd.u16(), sd.u32(), sd.next(13)
+		mode, _, _, _ := sd.u32(), sd.u32(), sd.u32(), sd.u64()
+		names = append(names, sd.str())
+		if names[len(names)-1] == "css" {
+			assert.True(t, mode&dmdir != 0)
+		}
+	}
+	assert.Equal(t, []string{"css", "robots.txt"}, names)
+
+	typ, d = c.rpc(tread, func(e *encoder) { e.u32(3); e.u64(3); e.u32(8000) })
+	assert.Equal(t, uint8(rerror), typ)
+	assert.Equal(t, ErrBadOffset.Error(), d.str())
+
+	typ, _ = c.rpc(tclunk, func(e *encoder) { e.u32(2) })
+	assert.Equal(t, uint8(rclunk), typ)
+	typ, d = c.rpc(tread, func(e *encoder) { e.u32(2); e.u64(0); e.u32(10) })
+	assert.Equal(t, uint8(rerror), typ)
+	assert.Equal(t, ErrUnknownFid.Error(), d.str())
+}
+
+type testClient struct {
+	t    *testing.T
+	conn net.Conn
+	tag  uint16
+}
+
+func newTestClient(t *testing.T) *testClient {
+	m, err := vfs.NewMount("/static", "")
+	assert.FailNowOnError(t, err, "")
+	mt := time.Date(2018, 6, 17, 0, 0, 0, 0, time.UTC)
+	assert.Nil(t, m.AddDir(&vfs.NodeInfo{Dir: true, Path: "/static/css", Time: mt}))
+	assert.Nil(t, m.AddFile(&vfs.NodeInfo{Path: "/static/css/aah.css", DataSize: 20, Time: mt}, []byte("body { color: red; }")))
+	assert.Nil(t, m.AddFile(&vfs.NodeInfo{Path: "/static/robots.txt", DataSize: 2, Time: mt}, []byte("ok")))
+
+	cc, sc := net.Pipe()
+	go func() { _ = (&Server{FS: m, Root: "/static"}).ServeConn(sc) }()
+	return &testClient{t: t, conn: cc}
+}
+
+func (c *testClient) rpc(typ uint8, fn func(e *encoder)) (uint8, *decoder) {
+	c.tag++
+	e := newMessage(typ, c.tag)
+	fn(e)
+	_, err := c.conn.Write(e.bytes())
+	assert.FailNowOnError(c.t, err, "write")
+
+	rtyp, tag, d, err := readMessage(c.conn, DefaultMaxMessageSize)
+	assert.FailNowOnError(c.t, err, "read")
+	assert.Equal(c.t, c.tag, tag)
+	return rtyp, d
+}
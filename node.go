@@ -7,17 +7,21 @@ package vfs
 import (
 	"bytes"
 	"compress/gzip"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"path"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 )
 
 var _ os.FileInfo = (*NodeInfo)(nil)
 var _ os.FileInfo = (*node)(nil)
+var _ Checksummer = (*NodeInfo)(nil)
 
 // Gzip Member header
 // RFC 1952 section 2.3 and 2.3.1
@@ -29,6 +33,39 @@ type NodeInfo struct {
 	DataSize int64
 	Path     string
 	Time     time.Time
+
+	// SHA256 is the lowercase hex SHA-256 checksum of file content, its
+	// populated by `vfs.Binary` generated code, see `vfs.Checksummer`.
+	SHA256 string
+
+	// Symlink is the target path of symbolic link, empty for directory and
+	// file. See `Mount.Symlink`.
+	Symlink string
+
+	// Physical is true for the file whose data is not embedded, it is
+	// opened from physical filesystem of the mount. See
+	// `BinaryOptions.MaxEmbedSize`.
+	Physical bool
+
+	// Encrypted is true for the file whose data is encrypted with AES-GCM,
+	// its decrypted on open with the key of `Mount.SetDecryptionKey`. See
+	// `BinaryOptions.EncryptionKey`.
+	Encrypted bool
+
+	// Gzip is true for the file whose data is gzip compressed by `vfs.Binary`,
+	// `vfs.Pack` or `vfs.CompressAdded`, its decompressed on read. If both
+	// Gzip and Raw are not set, data starting with gzip member header is
+	// treated as gzip compressed, i.e. code generated by earlier versions.
+	Gzip bool
+
+	// Raw is true for the file whose data is served as-is even if it starts
+	// with gzip member header, for e.g. `.tar.gz` file and archive entries.
+	Raw bool
+
+	// Perm is the permission bits of file or directory, zero means the
+	// default, i.e. read-only file and directory 0755. See
+	// `Mount.AddFileFromReader`.
+	Perm os.FileMode
 }
 
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
@@ -51,10 +88,20 @@ func (n NodeInfo) Size() int64 {
 
 // Mode method returns file mode bits.
 func (n NodeInfo) Mode() os.FileMode {
+	if n.Symlink != "" {
+		return 0777 | os.ModeSymlink // lrwxrwxrwx
+	}
+	perm := n.Perm.Perm()
 	if n.IsDir() {
-		return 0755 | os.ModeDir // drwxr-xr-x
+		if perm == 0 {
+			perm = 0755 // drwxr-xr-x
+		}
+		return perm | os.ModeDir
+	}
+	if perm == 0 {
+		perm = 0444 // -r--r--r--
 	}
-	return 0444 // -r--r--r--
+	return perm
 }
 
 // ModTime method returns modification time.
@@ -72,16 +119,24 @@ func (n NodeInfo) Sys() interface{} {
 	return nil
 }
 
+// Checksum method returns the SHA-256 checksum of file content in lowercase
+// hex, false if its not known. Implements interface `vfs.Checksummer`.
+func (n NodeInfo) Checksum() (string, bool) {
+	return n.SHA256, n.SHA256 != ""
+}
+
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
 // Node and its methods
 //______________________________________________________________________________
 
 // Node represents the virtual Node of file/directory on mounted VFS.
 //
-// Implements interfaces `os.FileInfo` and `vfs.Gziper`.
+// Implements interfaces `os.FileInfo`, `vfs.Gziper` and `vfs.Compressed`.
 type node struct {
 	*NodeInfo
 	data       []byte
+	src        *nodeSource
+	encoded    map[string][]byte
 	childInfos []os.FileInfo
 	childs     map[string]*node
 }
@@ -96,10 +151,10 @@ func (n node) String() string {
 // Gziper interface methods
 //______________________________________________________________________________
 
-// IsGzip method returns true if its statisfies Gzip Member header
-// RFC 1952 section 2.3 and 2.3.1 otherwise false.
+// IsGzip method returns true if the data is gzip compressed, see
+// `NodeInfo.Gzip`. Encrypted data is not gzip until its decrypted.
 func (n node) IsGzip() bool {
-	return bytes.HasPrefix(n.data, gzipMemberHeader)
+	return !n.Encrypted && isGzipped(n.NodeInfo, n.data)
 }
 
 func (n node) RawBytes() []byte {
@@ -107,19 +162,68 @@ func (n node) RawBytes() []byte {
 }
 
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
-// Node unexported methods
+// Compressed interface methods
 //______________________________________________________________________________
 
-func (n *node) find(name string) (*file, error) {
-	tn, err := n.findNode(name)
-	if err != nil {
-		return nil, err
+// Encoding method returns `gzip` if raw bytes is gzipped otherwise empty,
+// see `NodeInfo.Gzip`.
+func (n node) Encoding() string {
+	if n.IsGzip() {
+		return EncodingGzip
 	}
+	return ""
+}
 
-	if tn.match(name) {
-		return newFile(tn), nil
+// EncodedBytes method returns the bytes of given content encoding.
+func (n node) EncodedBytes(encoding string) ([]byte, bool) {
+	return encodedBytes(n.data, n.IsGzip(), n.encoded, encoding)
+}
+
+// encodedBytes method returns the data as-is for gzip encoding if its gzip
+// compressed, see `NodeInfo.Gzip`, otherwise the encoded bytes.
+func encodedBytes(data []byte, gzipped bool, encoded map[string][]byte, encoding string) ([]byte, bool) {
+	if encoding == EncodingGzip && gzipped {
+		return data, true
 	}
+	b, found := encoded[encoding]
+	return b, found
+}
 
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Node unexported methods
+//______________________________________________________________________________
+
+// physicalInfo is implemented by the info of virtual node, see
+// `NodeInfo.Physical`.
+type physicalInfo interface {
+	isPhysical() bool
+}
+
+func (n NodeInfo) isPhysical() bool {
+	return n.Physical
+}
+
+// isGzipped method returns true if `NodeInfo.Gzip` is set, otherwise if data
+// satisfies gzip member header RFC 1952 section 2.3 and 2.3.1 unless
+// `NodeInfo.Raw` is set.
+func isGzipped(ni *NodeInfo, data []byte) bool {
+	return ni.Gzip || (!ni.Raw && bytes.HasPrefix(data, gzipMemberHeader))
+}
+
+// permInfo is implemented by the info of virtual node, see `NodeInfo.Perm`.
+type permInfo interface {
+	permBits() os.FileMode
+}
+
+func (n NodeInfo) permBits() os.FileMode {
+	return n.Perm
+}
+
+// find method opens the node exactly matching given path relative to n.
+func (n *node) find(name string) (*file, error) {
+	if tn, found := n.lookup(name); found {
+		return newFile(tn), nil
+	}
 	return nil, os.ErrNotExist
 }
 
@@ -131,30 +235,262 @@ func (n *node) findNode(name string) (*node, error) {
 		return n, nil
 	}
 
-	search := strings.Split(strings.TrimLeft(name, "/"), "/")
-	if len(search) == 0 {
-		return nil, os.ErrNotExist
+	tn := n
+	for rest := strings.TrimLeft(name, "/"); ; {
+		var s string
+		s, rest = nextSegment(rest)
+		t, found := tn.childs[s]
+		if !found {
+			break
+		}
+		tn = t
+		if rest == "" {
+			break
+		}
+	}
+
+	return tn, nil
+}
+
+// lookup method returns the node exactly matching given slash separated path,
+// relative to n. Unlike `findNode` it does not return the nearest parent.
+func (n *node) lookup(name string) (*node, bool) {
+	tn := n
+	for name != "" {
+		var s string
+		if s, name = nextSegment(name); s == "" {
+			continue
+		}
+		t, found := tn.childs[s]
+		if !found {
+			return nil, false
+		}
+		tn = t
 	}
+	return tn, true
+}
 
+// lookupFold method is same as `lookup` but the name comparison is
+// case-insensitive, exact match is preferred.
+func (n *node) lookupFold(name string) (*node, bool) {
 	tn := n
-	for _, s := range search {
-		if t, found := tn.childs[s]; found {
-			tn = t
-		} else {
+	for name != "" {
+		var s string
+		if s, name = nextSegment(name); s == "" {
+			continue
+		}
+		t, found := tn.childs[s]
+		if !found {
+			for _, c := range tn.childInfos {
+				if strings.EqualFold(c.Name(), s) {
+					t, found = c.(*node), true
+					break
+				}
+			}
+		}
+		if !found {
+			return nil, false
+		}
+		tn = t
+	}
+	return tn, true
+}
+
+// loadData method loads the node data from its source on first call, it is
+// no-op for the node without source.
+func (n *node) loadData() error {
+	if n.src == nil {
+		return nil
+	}
+	n.src.once.Do(func() {
+		var data []byte
+		if data, n.src.err = n.src.load(); n.src.err == nil {
+			n.data = data
+		}
+	})
+	return n.src.err
+}
+
+func (n *node) setData(data []byte) {
+	n.data = data
+	n.DataSize = int64(len(data))
+	n.Time = time.Now().UTC()
+}
+
+func (n *node) truncate(op string, size int64) error {
+	switch {
+	case n.IsDir():
+		return &os.PathError{Op: op, Path: n.Path, Err: errors.New("is a directory")}
+	case size < 0:
+		return &os.PathError{Op: op, Path: n.Path, Err: os.ErrInvalid}
+	case size <= int64(len(n.data)):
+		n.setData(n.data[:size])
+	default:
+		n.setData(append(n.data, make([]byte, size-int64(len(n.data)))...))
+	}
+	return nil
+}
+
+// removeChild method removes the child node, childInfos is copied on write,
+// see `node.addChild`.
+func (n *node) removeChild(name string) {
+	delete(n.childs, name)
+	for i, c := range n.childInfos {
+		if c.Name() == name {
+			infos := make([]os.FileInfo, 0, len(n.childInfos)-1)
+			infos = append(infos, n.childInfos[:i]...)
+			n.childInfos = append(infos, n.childInfos[i+1:]...)
 			break
 		}
 	}
+}
 
-	return tn, nil
+// clone method returns the copy of node and its descendants at given path,
+// data is shared. Node itself is untouched, so the opened files of node
+// keep its info.
+func (n *node) clone(name string) *node {
+	b := &nodeBlock{ni: *n.NodeInfo}
+	b.ni.Path = name
+	c := &b.n
+	c.NodeInfo, c.data, c.encoded = &b.ni, n.data, n.encoded
+	c.childInfos = make([]os.FileInfo, 0, len(n.childInfos))
+	if n.src != nil {
+		c.src = &nodeSource{load: func() ([]byte, error) {
+			if err := n.loadData(); err != nil {
+				return nil, err
+			}
+			return n.data, nil
+		}}
+	}
+	for _, ci := range n.childInfos {
+		cn := ci.(*node)
+		c.addChild(cn.clone(path.Join(name, cn.Name())))
+	}
+	return c
+}
+
+// rebase method updates the path of node and its descendants to given path.
+func (n *node) rebase(name string) {
+	n.Path = name
+	for _, c := range n.childs {
+		c.rebase(path.Join(name, c.Name()))
+	}
+}
+
+// childInfoSnapshot method returns copy of child infos, so that caller is not
+// affected by later node modifications.
+func (n *node) childInfoSnapshot() []os.FileInfo {
+	infos := make([]os.FileInfo, 0, len(n.childInfos))
+	for _, c := range n.childInfos {
+		info := *c.(*node).NodeInfo
+		infos = append(infos, &info)
+	}
+	return infos
 }
 
-func (n *node) match(name string) bool {
-	return strings.EqualFold(n.Name(), path.Base(name))
+// nextSegment method returns the first segment of slash separated path and
+// the rest after the slash, it does not allocate unlike `strings.Split` so
+// the tree lookup costs only the map access per segment.
+func nextSegment(name string) (string, string) {
+	if i := strings.IndexByte(name, '/'); i >= 0 {
+		return name[:i], name[i+1:]
+	}
+	return name, ""
 }
 
+// addChild method adds the child node, childInfos is kept sorted by name so
+// that listing is deterministic regardless of insertion order. Existing child
+// with the same name is replaced.
+//
+// Existing elements of childInfos are never modified in place, so the slice
+// taken by opened directory stays intact while the tree is mutated. Append
+// at the end reuses the spare capacity since it is beyond the taken length.
 func (n *node) addChild(child *node) {
-	n.childInfos = append(n.childInfos, child)
-	n.childs[child.Name()] = child
+	name := child.Name()
+	i := sort.Search(len(n.childInfos), func(i int) bool {
+		return n.childInfos[i].Name() >= name
+	})
+
+	switch {
+	case i == len(n.childInfos):
+		n.childInfos = append(n.childInfos, child)
+	case n.childInfos[i].Name() == name:
+		infos := append([]os.FileInfo{}, n.childInfos...)
+		infos[i] = child
+		n.childInfos = infos
+	default:
+		infos := make([]os.FileInfo, 0, len(n.childInfos)+1)
+		infos = append(infos, n.childInfos[:i]...)
+		infos = append(infos, child)
+		n.childInfos = append(infos, n.childInfos[i:]...)
+	}
+	if n.childs == nil {
+		n.childs = make(map[string]*node)
+	}
+	n.childs[name] = child
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// nodeSource and sourceData type and methods
+//______________________________________________________________________________
+
+var _ io.Reader = (*sourceData)(nil)
+var _ io.Seeker = (*sourceData)(nil)
+var _ io.ReaderAt = (*sourceData)(nil)
+var _ io.Closer = (*sourceData)(nil)
+
+// nodeSource loads the node data on first read, for e.g.: entry of zip
+// archive. Loaded data is kept on node.
+type nodeSource struct {
+	once sync.Once
+	load func() ([]byte, error)
+	err  error
+}
+
+// sourceData reads the node data loaded from its source, loading happens on
+// first Read or Seek. So the Stat of node does not load the data.
+type sourceData struct {
+	n  *node
+	rs io.ReadSeeker
+}
+
+func (s *sourceData) Read(b []byte) (int, error) {
+	if err := s.init(); err != nil {
+		return 0, err
+	}
+	return s.rs.Read(b)
+}
+
+func (s *sourceData) Seek(offset int64, whence int) (int64, error) {
+	if err := s.init(); err != nil {
+		return 0, err
+	}
+	return s.rs.Seek(offset, whence)
+}
+
+func (s *sourceData) ReadAt(b []byte, off int64) (int, error) {
+	if err := s.init(); err != nil {
+		return 0, err
+	}
+	return s.rs.(io.ReaderAt).ReadAt(b, off)
+}
+
+func (s *sourceData) Close() error {
+	if c, ok := s.rs.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+func (s *sourceData) init() error {
+	if s.rs != nil {
+		return nil
+	}
+	if err := s.n.loadData(); err != nil {
+		return &os.PathError{Op: "read", Path: s.n.Path, Err: err}
+	}
+	s.rs = dataReader(s.n)
+	return nil
 }
 
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
@@ -163,25 +499,44 @@ func (n *node) addChild(child *node) {
 
 var _ io.Reader = (*gzipData)(nil)
 var _ io.Seeker = (*gzipData)(nil)
+var _ io.ReaderAt = (*gzipData)(nil)
 var _ io.Closer = (*gzipData)(nil)
 
 // GzipData my goal is to expose transparent behavior for regular and gzip
 // data bytes. So I have designed gzip data handing.
+//
+// Data is decompressed on Read via streaming gzip reader, it is created on
+// first Read, so opening the file for Stat does not allocate it. Seek
+// forward skips the decompressed bytes. Seek backward on streaming read
+// decompresses the full payload once and further reads are served from it,
+// so random access does not decompress from beginning each time, same goes
+// for ReadAt.
 type gzipData struct {
 	n    *node
 	r    *gzip.Reader
 	rpos int64
 	spos int64
+	full *bytes.Reader
 }
 
 // Imitate regular read in gzip reader
 // https://github.com/shurcooL/vfsgen/blob/master/generator.go
 func (g *gzipData) Read(b []byte) (int, error) {
-	if g.rpos > g.spos { // to the beginning
-		if err := g.r.Reset(bytes.NewReader(g.n.data)); err != nil {
+	if g.full == nil && g.rpos > g.spos { // to the beginning
+		if err := g.materialize(); err != nil {
+			return 0, err
+		}
+	}
+	if g.full != nil {
+		return g.full.Read(b)
+	}
+
+	if g.r == nil {
+		r, err := gzip.NewReader(bytes.NewReader(g.n.data))
+		if err != nil {
 			return 0, err
 		}
-		g.rpos = 0
+		g.r = r
 	}
 
 	if g.rpos < g.spos { // move forward
@@ -201,6 +556,10 @@ func (g *gzipData) Read(b []byte) (int, error) {
 // Imitate regular seek in gzip reader
 // https://github.com/shurcooL/vfsgen/blob/master/generator.go
 func (g *gzipData) Seek(offset int64, whence int) (int64, error) {
+	if g.full != nil {
+		return g.full.Seek(offset, whence)
+	}
+
 	switch whence {
 	case io.SeekStart:
 		g.spos = 0 + offset
@@ -214,6 +573,40 @@ func (g *gzipData) Seek(offset int64, whence int) (int64, error) {
 	return g.spos, nil
 }
 
+// ReadAt method decompresses the full payload on first call, since gzip
+// stream does not have random access.
+func (g *gzipData) ReadAt(b []byte, off int64) (int, error) {
+	if g.full == nil {
+		if err := g.materialize(); err != nil {
+			return 0, err
+		}
+	}
+	return g.full.ReadAt(b, off)
+}
+
 func (g *gzipData) Close() error {
+	if g.r == nil {
+		return nil
+	}
 	return g.r.Close()
 }
+
+// materialize method decompresses the full payload and positions it at the
+// seek offset, streaming reader is released.
+func (g *gzipData) materialize() error {
+	r, err := gzip.NewReader(bytes.NewReader(g.n.data))
+	if err != nil {
+		return err
+	}
+	data, err := readAllSize(r, g.n.DataSize)
+	if err != nil {
+		return err
+	}
+	if g.r != nil {
+		_ = g.r.Close()
+		g.r = nil
+	}
+	g.full = bytes.NewReader(data)
+	_, err = g.full.Seek(g.spos, io.SeekStart)
+	return err
+}
@@ -0,0 +1,229 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+)
+
+var _ FileSystem = (*Overlay)(nil)
+
+// Overlay is a union of FileSystem layers on same path namespace, for e.g.:
+// theme assets mount over default assets mount. Overlay is Read-Only and
+// implements `vfs.FileSystem`.
+//
+// Shadowing rules, layers are ordered from top to bottom:
+//
+//   - Path is served by the top most layer having it, so file or directory
+//     of upper layer shadows the same path of lower layers.
+//   - Directory lists the entries of all the layers having it as directory,
+//     entry of upper layer wins on same name. Merge stops at the first lower
+//     layer having the path as non-directory.
+//   - Path under a file of upper layer does not exist, even if lower layer
+//     has it under a directory.
+//   - Glob returns the union of layer matches, sorted.
+type Overlay struct {
+	layers []FileSystem
+}
+
+// NewOverlay method creates the overlay of given layers, first layer is the
+// top most layer.
+func NewOverlay(layers ...FileSystem) *Overlay {
+	return &Overlay{layers: layers}
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Overlay's FileSystem interface
+//______________________________________________________________________________
+
+// Open method behaviour is same as `os.Open`. Directory is opened with
+// merged entries of the layers.
+func (o *Overlay) Open(name string) (File, error) {
+	i, fi, err := o.find(name, false)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return o.layers[i].Open(name)
+	}
+
+	infos, err := o.readDir(i, name)
+	if err != nil {
+		return nil, err
+	}
+	d := newNode(path.Clean(name), fi)
+	d.childInfos = infos
+	return newFile(d), nil
+}
+
+// OpenFile method behaviour is same as `os.OpenFile`. Overlay is Read-Only,
+// so flags other than `os.O_RDONLY` returns `ErrReadOnly`.
+func (o *Overlay) OpenFile(name string, flag int, perm os.FileMode) (File, error) {
+	if isWriteFlag(flag) {
+		return nil, &os.PathError{Op: "open", Path: name, Err: ErrReadOnly}
+	}
+	return o.Open(name)
+}
+
+// Lstat method behaviour is same as `os.Lstat`.
+func (o *Overlay) Lstat(name string) (os.FileInfo, error) {
+	_, fi, err := o.find(name, false)
+	return fi, err
+}
+
+// Stat method behaviour is same as `os.Stat`.
+func (o *Overlay) Stat(name string) (os.FileInfo, error) {
+	_, fi, err := o.find(name, true)
+	return fi, err
+}
+
+// Readlink method behaviour is same as `os.Readlink`, link is read from the
+// top most layer having the name.
+func (o *Overlay) Readlink(name string) (string, error) {
+	i, _, err := o.find(name, false)
+	if err != nil {
+		return "", err
+	}
+	return o.layers[i].Readlink(name)
+}
+
+// ReadFile method behaviour is same as `ioutil.ReadFile`.
+func (o *Overlay) ReadFile(filename string) ([]byte, error) {
+	i, fi, err := o.find(filename, false)
+	if err != nil {
+		return nil, err
+	}
+	if fi.IsDir() {
+		return nil, &os.PathError{Op: "read", Path: filename, Err: errors.New("is a directory")}
+	}
+	return o.layers[i].ReadFile(filename)
+}
+
+// ReadDir method behaviour is same as `ioutil.ReadDir`, entries of the
+// layers are merged.
+func (o *Overlay) ReadDir(dirname string) ([]os.FileInfo, error) {
+	i, fi, err := o.find(dirname, false)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return nil, &os.PathError{Op: "read", Path: dirname, Err: errors.New("is a file")}
+	}
+	return o.readDir(i, dirname)
+}
+
+// Glob method returns the union of matches of the layers, sorted. Shadowed
+// matches are excluded.
+func (o *Overlay) Glob(pattern string) ([]string, error) {
+	seen := make(map[string]bool)
+	var matches []string
+	for _, l := range o.layers {
+		list, err := l.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range list {
+			if !seen[p] && o.IsExists(p) {
+				seen[p] = true
+				matches = append(matches, p)
+			}
+		}
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// IsExists method is helper to find existence.
+func (o *Overlay) IsExists(name string) bool {
+	_, err := o.Lstat(name)
+	return err == nil
+}
+
+// String method Stringer interface.
+func (o *Overlay) String() string {
+	return fmt.Sprintf("overlay%v", o.layers)
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Overlay unexported methods
+//______________________________________________________________________________
+
+// find method returns the index and info of top most layer having the name,
+// error of top layer if none. Search stops at the layer having an ancestor of
+// the name as file.
+func (o *Overlay) find(name string, follow bool) (int, os.FileInfo, error) {
+	var firstErr error
+	for i, l := range o.layers {
+		var fi os.FileInfo
+		var err error
+		if follow {
+			fi, err = l.Stat(name)
+		} else {
+			fi, err = l.Lstat(name)
+		}
+		if err == nil {
+			return i, fi, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+		if hasFileAncestor(l, name) {
+			break
+		}
+	}
+	if firstErr == nil {
+		firstErr = &os.PathError{Op: "stat", Path: name, Err: os.ErrNotExist}
+	}
+	return -1, nil, firstErr
+}
+
+// readDir method merges the entries of dirname from layer i and below,
+// until a layer has it as non-directory. Layers not having it are skipped.
+func (o *Overlay) readDir(i int, dirname string) ([]os.FileInfo, error) {
+	entries := make(map[string]os.FileInfo)
+	for _, l := range o.layers[i:] {
+		fi, err := l.Stat(dirname)
+		if err != nil {
+			continue
+		}
+		if !fi.IsDir() {
+			break
+		}
+
+		list, err := l.ReadDir(dirname)
+		if err != nil {
+			return nil, err
+		}
+		for _, cfi := range list {
+			if _, found := entries[cfi.Name()]; !found {
+				entries[cfi.Name()] = cfi
+			}
+		}
+	}
+
+	infos := make([]os.FileInfo, 0, len(entries))
+	for _, fi := range entries {
+		infos = append(infos, fi)
+	}
+	sort.Sort(byName(infos))
+	return infos, nil
+}
+
+// hasFileAncestor method returns true if the nearest existing ancestor of
+// name in fs is not a directory.
+func hasFileAncestor(fs FileSystem, name string) bool {
+	for dir := path.Dir(path.Clean(name)); ; dir = path.Dir(dir) {
+		if fi, err := fs.Lstat(dir); err == nil {
+			return !fi.IsDir()
+		}
+		if dir == "/" || dir == "." {
+			return false
+		}
+	}
+}
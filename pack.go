@@ -0,0 +1,163 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"context"
+	"crypto/sha256"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+// Pack method writes the asset pack of directories and files of
+// physicalPath for mountPath into w, for e.g. `views.pack` next to the
+// executable. Pack is the asset blob of `vfs.WriteBlob`, so its loaded
+// without Go source generation and compile step, see `vfs.LoadPack`.
+//
+// Excludes and options are same as `vfs.BinaryWithOptions` except
+// `Encoders`, `Literal`, `MaxEmbedSize` and code generation options do not
+// apply. File data is gzipped per compression options, identical file
+// contents are stored once and SHA-256 checksum is recorded for each file.
+// Encrypted pack needs the key via `Mount.SetDecryptionKey` after load and
+// signed pack is verified via `Mount.VerifySignature`.
+func Pack(w io.Writer, mountPath, physicalPath string, excludes []string, opts BinaryOptions) error {
+	g, err := newBinaryGen(context.Background(), mountPath, physicalPath, excludes, opts)
+	if err != nil {
+		return err
+	}
+	g.pack = true
+
+	bw := &blobWriter{w: w, offsets: make(map[[sha256.Size]byte]int64)}
+	bw.index.Mounts = []string{g.mountPath}
+	err = g.encodeEntries(g.entries, func(e binaryEntry, ef *encodedFile) error {
+		return g.packEntry(bw, e, ef)
+	})
+	if err != nil {
+		return err
+	}
+	if bw.index.Signature, err = g.sign(); err != nil {
+		return err
+	}
+	if err = bw.close(); err != nil {
+		return err
+	}
+	return g.finish()
+}
+
+// LoadPack method creates the mount of asset pack located at the end of r,
+// see `vfs.Pack`. Mount is purely virtual and its virtual root is the mount
+// path of pack. File data is read into memory.
+func LoadPack(r io.ReaderAt, size int64, opts ...MountOption) (*Mount, error) {
+	index, payload, err := readBlob(r, size)
+	if err != nil {
+		return nil, err
+	}
+	if len(index.Mounts) != 1 {
+		return nil, ErrBlobCorrupt
+	}
+	mounts, err := newBlobMounts(index, payload, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return mounts[0], nil
+}
+
+// OpenPack method opens the asset pack file and creates its mount, see
+// `vfs.LoadPack`.
+func OpenPack(filename string, opts ...MountOption) (*Mount, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return LoadPack(f, fi.Size(), opts...)
+}
+
+// AttachPack method mounts the asset pack file at virtual path vroot, for
+// e.g. plugin ships its views and static assets as `.pack` files which are
+// attached into the application namespace at startup. Pack content is
+// served under vroot regardless of the mount path it was packed for. vroot
+// must not be mounted already, it can be under an existing mount path.
+//
+// Pack file is memory-mapped same as `VFS.MountBlobFile`, mapping is
+// released when the mount is closed, for e.g. via `VFS.Unmount`.
+func (v *VFS) AttachPack(vroot, filename string) error {
+	vroot, err := cleanPath("attachpack", vroot)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	data, unmap, err := mmapFile(f, fi.Size())
+	if err != nil {
+		return err
+	}
+
+	index, payload, err := parseBlob(data)
+	if err == nil {
+		err = rebasePack(index, vroot)
+	}
+	if err == nil {
+		err = v.mountBlob(index, payload, &blobMapping{unmap: unmap})
+	}
+	if err != nil {
+		_ = unmap()
+	}
+	return err
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Pack unexported methods
+//______________________________________________________________________________
+
+// packEntry method writes the entry with its encoded file into blob,
+// manifest is collected along.
+func (g *binaryGen) packEntry(bw *blobWriter, e binaryEntry, ef *encodedFile) error {
+	be := blobEntry{Path: e.vpath, Dir: e.fi.IsDir(), Time: g.modTimeOf(e.fi).UnixNano()}
+	if be.Dir {
+		return bw.add(be, nil)
+	}
+
+	be.Size, be.SHA256, be.Encrypted = ef.me.Size, ef.me.SHA256, g.gcm != nil
+	be.Gzip = ef.me.Encoding == EncodingGzip
+	if err := bw.add(be, ef.stored); err != nil {
+		return err
+	}
+	g.embedded(ef.me, false)
+	return nil
+}
+
+// rebasePack method moves the entries of pack index from its mount path to
+// given vroot.
+func rebasePack(index *blobIndex, vroot string) error {
+	if len(index.Mounts) != 1 {
+		return ErrBlobCorrupt
+	}
+	root := index.Mounts[0]
+	for i, e := range index.Entries {
+		if !isPathWithin(e.Path, root) {
+			return &os.PathError{Op: "readblob", Path: e.Path, Err: ErrBlobCorrupt}
+		}
+		index.Entries[i].Path = path.Join(vroot, strings.TrimPrefix(e.Path, root))
+	}
+	index.Mounts[0] = vroot
+	return nil
+}
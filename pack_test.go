@@ -0,0 +1,104 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"aahframework.org/test.v0/assert"
+)
+
+func TestVFSPack(t *testing.T) {
+	src := filepath.Join(testdataBaseDir(), "vfstest", "static")
+	mt := time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+	var buf, manifest bytes.Buffer
+	err := Pack(&buf, "/app/static", src, []string{"*.ico"}, BinaryOptions{ModTime: mt, Manifest: &manifest})
+	assert.Nil(t, err)
+
+	m, err := LoadPack(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.Nil(t, err)
+	assert.Equal(t, "/app/static", m.Vroot)
+	assert.Equal(t, 0, len(m.Validate()))
+	assert.True(t, m.Verify().OK())
+	assert.False(t, m.IsExists("/app/static/img/favicon.ico"))
+
+	expected, err := ioutil.ReadFile(filepath.Join(src, "css", "aah.css"))
+	assert.Nil(t, err)
+	data, err := m.ReadFile("/app/static/css/aah.css")
+	assert.Nil(t, err)
+	assert.Equal(t, expected, data)
+	f, err := m.Open("/app/static/css/aah.css")
+	assert.Nil(t, err)
+	assert.True(t, f.(*file).IsGzip())
+	fi, err := f.Stat()
+	assert.Nil(t, err)
+	assert.True(t, mt.Equal(fi.ModTime()))
+	_, found := fi.(Checksummer).Checksum()
+	assert.True(t, found)
+	assert.Nil(t, f.Close())
+
+	var entries []ManifestEntry
+	assert.Nil(t, json.Unmarshal(manifest.Bytes(), &entries))
+	assert.Equal(t, 4, len(entries))
+
+	// pack file
+	dir, err := ioutil.TempDir("", "vfs-pack")
+	assert.FailNowOnError(t, err, "")
+	defer func() { _ = os.RemoveAll(dir) }()
+	fname := filepath.Join(dir, "static.pack")
+	assert.Nil(t, ioutil.WriteFile(fname, buf.Bytes(), 0644))
+	m, err = OpenPack(fname)
+	assert.Nil(t, err)
+	assert.True(t, m.IsExists("/app/static/robots.txt"))
+
+	// pack is the asset blob
+	fs := new(VFS)
+	assert.Nil(t, fs.MountBlob(bytes.NewReader(buf.Bytes()), int64(buf.Len())))
+	assert.True(t, fs.IsExists("/app/static/css/aah.css"))
+
+	_, err = LoadPack(bytes.NewReader([]byte("not a pack")), 10)
+	assert.Equal(t, ErrBlobNotFound, err)
+	_, err = OpenPack(filepath.Join(dir, "not-exists.pack"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestVFSAttachPack(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vfs-attach-pack")
+	assert.FailNowOnError(t, err, "")
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	src := filepath.Join(testdataBaseDir(), "vfstest", "views")
+	fname := filepath.Join(dir, "views.pack")
+	f, err := os.Create(fname)
+	assert.FailNowOnError(t, err, "")
+	assert.Nil(t, Pack(f, "/views", src, nil, BinaryOptions{}))
+	assert.Nil(t, f.Close())
+
+	fs := createVFS(t)
+	assert.Nil(t, fs.AttachPack("/app/plugins/blog/views", fname))
+	expected, err := ioutil.ReadFile(filepath.Join(src, "errors", "404.html"))
+	assert.Nil(t, err)
+	data, err := fs.ReadFile("/app/plugins/blog/views/errors/404.html")
+	assert.Nil(t, err)
+	assert.Equal(t, expected, data)
+	assert.False(t, fs.IsExists("/views/errors/404.html"))
+
+	// application mount is not affected
+	assert.True(t, fs.IsExists("/app/config/aah.conf"))
+
+	// already mounted
+	assert.NotNil(t, fs.AttachPack("/app/plugins/blog/views", fname))
+	assert.Nil(t, fs.Unmount("/app/plugins/blog/views"))
+	assert.False(t, fs.IsExists("/app/plugins/blog/views/errors/404.html"))
+
+	assert.Equal(t, ErrBlobNotFound, fs.AttachPack("/app/plugins/shop", filepath.Join(src, "errors", "404.html")))
+	assert.True(t, os.IsNotExist(fs.AttachPack("/app/plugins/shop", filepath.Join(dir, "not-exists.pack"))))
+}
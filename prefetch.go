@@ -0,0 +1,91 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"sort"
+	"sync"
+)
+
+// TrackAccess option makes the mount to count the successful opens per
+// virtual path, see `Mount.HotPaths`.
+//
+// When the mount gets swapped at runtime (`VFS.SyncMounts`,
+// `VFS.MountArchives`, watchers), the new mount continues the tracking and
+// prefetches the hottest n paths into its cache in background, see
+// `Mount.Warm`; `Mount.Close` waits for it. Value 0 disables prefetch. For
+// restart, persist the `Mount.HotPaths` and warm them on startup.
+func TrackAccess(prefetch int) MountOption {
+	return func(m *Mount) {
+		m.access = &accessStats{prefetch: prefetch, counts: make(map[string]uint64)}
+	}
+}
+
+// HotPaths method returns the n most opened virtual paths, ordered by open
+// count descending. Value n <= 0 returns all. It returns nil if mount does not
+// have `TrackAccess` option.
+func (m *Mount) HotPaths(n int) []string {
+	if m.access == nil {
+		return nil
+	}
+	return m.access.hottest(n)
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Access stats unexported types and methods
+//______________________________________________________________________________
+
+type accessStats struct {
+	prefetch int
+	mu       sync.Mutex
+	counts   map[string]uint64
+}
+
+func (a *accessStats) hit(name string) {
+	a.mu.Lock()
+	a.counts[name]++
+	a.mu.Unlock()
+}
+
+func (a *accessStats) hottest(n int) []string {
+	a.mu.Lock()
+	paths := make([]string, 0, len(a.counts))
+	for p := range a.counts {
+		paths = append(paths, p)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		ci, cj := a.counts[paths[i]], a.counts[paths[j]]
+		if ci != cj {
+			return ci > cj
+		}
+		return paths[i] < paths[j]
+	})
+	a.mu.Unlock()
+
+	if n > 0 && n < len(paths) {
+		paths = paths[:n]
+	}
+	return paths
+}
+
+// inheritAccess method continues the access tracking of the mount being
+// replaced and prefetches its hottest paths in background, tracked by the
+// prefetch wait group of mount.
+func (m *Mount) inheritAccess(old *Mount) {
+	if old == nil || old.access == nil {
+		return
+	}
+	if m.access == nil {
+		m.access = old.access
+	}
+	if n := m.access.prefetch; n > 0 {
+		paths := old.access.hottest(n)
+		m.prefetchWg.Add(1)
+		go func() {
+			defer m.prefetchWg.Done()
+			_ = m.Warm(paths...)
+		}()
+	}
+}
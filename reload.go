@@ -0,0 +1,117 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// PreferPhysical option makes the mount to serve the physical filesystem
+// first and fallback to virtual tree, inverse of the default lookup. It is
+// meant for development mode, so that template and asset edits are visible
+// without regenerating the single binary. Use `Mount.Watch` to drop the
+// cached data on physical change.
+func PreferPhysical() MountOption {
+	return func(m *Mount) {
+		m.preferPhysical = true
+	}
+}
+
+// Watch method periodically scans the physical filesystem of the mount and on
+// change (added, modified or removed) drops the cached data of the path, i.e.
+// lazy tree entries, decompressed and compressed data and fingerprint. Each
+// changed virtual path is reported to onChange in sorted order, onChange can
+// be nil. First scan happens before it returns, it records the state and
+// does not report.
+//
+// Scan is polling based to stay free of platform specific notification
+// dependency, so pick the interval relative to size of the directory.
+//
+// Call returned stop func to stop watching.
+func (m *Mount) Watch(interval time.Duration, onChange func(name string)) (stop func()) {
+	var last map[string]physicalState
+	return watch(interval, func() {
+		if !m.hasPhysical() {
+			return
+		}
+		current := m.scanPhysical()
+		if last != nil {
+			for _, name := range changedPaths(last, current) {
+				m.dropCached(name)
+				if onChange != nil {
+					onChange(name)
+				}
+			}
+		}
+		last = current
+	})
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Reload unexported types and methods
+//______________________________________________________________________________
+
+type physicalState struct {
+	modTime time.Time
+	size    int64
+	mode    os.FileMode
+}
+
+// scanPhysical method returns the state of physical files by virtual path,
+// unreadable entries are skipped.
+func (m *Mount) scanPhysical() map[string]physicalState {
+	states := make(map[string]physicalState)
+	_ = filepath.Walk(m.Proot, func(fpath string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		states[m.physicalToVirtual(fpath)] = physicalState{
+			modTime: fi.ModTime(),
+			size:    fi.Size(),
+			mode:    fi.Mode(),
+		}
+		return nil
+	})
+	return states
+}
+
+// dropCached method removes the cached data of given virtual path.
+func (m *Mount) dropCached(name string) {
+	m.Invalidate(name)
+	m.cache.remove(name)
+	if m.gzCache != nil {
+		m.gzCache.remove(name)
+	}
+	if m.contentCache != nil && m.hasPhysical() {
+		m.contentCache.data.remove(m.toPhysicalPath(name))
+	}
+
+	m.fpMu.Lock()
+	delete(m.fingerprints, path.Clean(name))
+	m.fpMu.Unlock()
+}
+
+// changedPaths method returns the sorted paths which are added, removed or
+// modified between given states.
+func changedPaths(old, current map[string]physicalState) []string {
+	var names []string
+	for name, s := range current {
+		if o, found := old[name]; !found || o.size != s.size ||
+			o.mode != s.mode || !o.modTime.Equal(s.modTime) {
+			names = append(names, name)
+		}
+	}
+	for name := range old {
+		if _, found := current[name]; !found {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,109 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// DefaultReportTopN is the default no. of largest files in `vfs.Report`.
+const DefaultReportTopN = 10
+
+// Report struct represents the size statistics of embedded files, it is
+// populated by `vfs.Binary` and `vfs.Pack` per `BinaryOptions.Report`.
+// Identical file contents are stored once, so it is counted once in the
+// stored size.
+type Report struct {
+	// Files is no. of embedded files.
+	Files int
+
+	// Size is the total size of files.
+	Size int64
+
+	// StoredSize is the total size of stored data, i.e. after compression
+	// and encryption.
+	StoredSize int64
+
+	// Extensions is the breakdown by lowercase file extension, sorted by
+	// stored size in descending order.
+	Extensions []ExtensionStats
+
+	// Largest is the top-N files by stored size in descending order, see
+	// `BinaryOptions.ReportTopN`.
+	Largest []ManifestEntry
+}
+
+// ExtensionStats struct represents the size statistics of file extension
+// in `vfs.Report`.
+type ExtensionStats struct {
+	// Ext is the lowercase file extension with dot, empty for the files
+	// without extension.
+	Ext        string
+	Files      int
+	Size       int64
+	StoredSize int64
+}
+
+// NewReport method returns the report of manifest entries with n largest
+// files, for e.g. entries of `vfs.BinaryDryRun`.
+func NewReport(entries []ManifestEntry, n int) Report {
+	var r Report
+	stored := make(map[string]bool)
+	exts := make(map[string]*ExtensionStats)
+	for _, e := range entries {
+		ext := strings.ToLower(path.Ext(e.Path))
+		es, found := exts[ext]
+		if !found {
+			es = &ExtensionStats{Ext: ext}
+			exts[ext] = es
+		}
+
+		// identical contents are stored once, see `Mount.AddLink`
+		var storedSize int64
+		if key := e.SHA256 + ":" + e.Encoding; !stored[key] {
+			stored[key] = true
+			storedSize = e.StoredSize
+		}
+		r.Files++
+		r.Size += e.Size
+		r.StoredSize += storedSize
+		es.Files++
+		es.Size += e.Size
+		es.StoredSize += storedSize
+	}
+
+	for _, es := range exts {
+		r.Extensions = append(r.Extensions, *es)
+	}
+	sort.Slice(r.Extensions, func(i, j int) bool {
+		a, b := r.Extensions[i], r.Extensions[j]
+		return a.StoredSize > b.StoredSize || a.StoredSize == b.StoredSize && a.Ext < b.Ext
+	})
+
+	largest := append([]ManifestEntry(nil), entries...)
+	sort.SliceStable(largest, func(i, j int) bool { return largest[i].StoredSize > largest[j].StoredSize })
+	if len(largest) > n {
+		largest = largest[:n]
+	}
+	r.Largest = largest
+	return r
+}
+
+// Ratio method returns the compression ratio, stored size divided by size.
+// It is 1 if there are no files.
+func (r Report) Ratio() float64 {
+	if r.Size == 0 {
+		return 1
+	}
+	return float64(r.StoredSize) / float64(r.Size)
+}
+
+// String method Stringer interface.
+func (r Report) String() string {
+	return fmt.Sprintf("report(files=%d size=%d stored=%d ratio=%.2f)", r.Files, r.Size, r.StoredSize, r.Ratio())
+}
@@ -0,0 +1,64 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"aahframework.org/test.v0/assert"
+)
+
+func TestVFSReport(t *testing.T) {
+	src := filepath.Join(testdataBaseDir(), "vfstest", "static")
+	var report Report
+	_, err := BinaryWithOptions("/app/static", src, nil, BinaryOptions{Report: &report, ReportTopN: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, 5, report.Files)
+	assert.True(t, report.StoredSize < report.Size)
+	assert.True(t, report.Ratio() < 1)
+	assert.Equal(t, 2, len(report.Largest))
+	assert.Equal(t, "/app/static/img/favicon.ico", report.Largest[0].Path)
+	assert.Equal(t, "/app/static/img/aah-framework-logo.png", report.Largest[1].Path)
+	assert.Equal(t, ".ico", report.Extensions[0].Ext)
+
+	var exts []string
+	var files int
+	var stored int64
+	for _, es := range report.Extensions {
+		exts = append(exts, es.Ext)
+		files += es.Files
+		stored += es.StoredSize
+	}
+	assert.Equal(t, []string{".ico", ".png", ".css", ".txt", ".js"}, exts)
+	assert.Equal(t, report.Files, files)
+	assert.Equal(t, report.StoredSize, stored)
+
+	// pack and dry run
+	var packReport, dryReport Report
+	var buf bytes.Buffer
+	assert.Nil(t, Pack(&buf, "/app/static", src, nil, BinaryOptions{Report: &packReport}))
+	assert.Equal(t, report.StoredSize, packReport.StoredSize)
+	_, err = BinaryDryRun("/app/static", src, nil, BinaryOptions{Report: &dryReport})
+	assert.Nil(t, err)
+	assert.Equal(t, report.StoredSize, dryReport.StoredSize)
+	assert.Equal(t, 5, len(dryReport.Largest))
+
+	// identical contents counted once
+	r := NewReport([]ManifestEntry{
+		{Path: "/a/x.TXT", Size: 10, StoredSize: 8, SHA256: "1"},
+		{Path: "/a/y.txt", Size: 10, StoredSize: 8, SHA256: "1"},
+		{Path: "/a/README", Size: 4, StoredSize: 4, SHA256: "2"},
+	}, 1)
+	assert.Equal(t, 3, r.Files)
+	assert.Equal(t, int64(24), r.Size)
+	assert.Equal(t, int64(12), r.StoredSize)
+	assert.Equal(t, ExtensionStats{Ext: ".txt", Files: 2, Size: 20, StoredSize: 8}, r.Extensions[0])
+	assert.Equal(t, ExtensionStats{Ext: "", Files: 1, Size: 4, StoredSize: 4}, r.Extensions[1])
+	assert.Equal(t, []ManifestEntry{{Path: "/a/x.TXT", Size: 10, StoredSize: 8, SHA256: "1"}}, r.Largest)
+	assert.Equal(t, "report(files=3 size=24 stored=12 ratio=0.50)", r.String())
+	assert.Equal(t, 1.0, NewReport(nil, 1).Ratio())
+}
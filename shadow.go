@@ -0,0 +1,228 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync/atomic"
+)
+
+var _ FileSystem = (*Shadow)(nil)
+
+// Divergence kinds reported by `vfs.Shadow`.
+const (
+	DivergenceMissing = "missing"
+	DivergenceType    = "type"
+	DivergenceSize    = "size"
+	DivergenceContent = "content"
+	DivergenceEntries = "entries"
+)
+
+// Divergence represents the difference found between virtual tree and
+// physical source for a path.
+type Divergence struct {
+	Op   string
+	Path string
+	Kind string
+	Err  error
+}
+
+// String method Stringer interface.
+func (d Divergence) String() string {
+	if d.Err != nil {
+		return fmt.Sprintf("divergence(op=%s path=%s kind=%s err=%v)", d.Op, d.Path, d.Kind, d.Err)
+	}
+	return fmt.Sprintf("divergence(op=%s path=%s kind=%s)", d.Op, d.Path, d.Kind)
+}
+
+// Shadow is a shadow-compare wrapper on Mount. It always serves from mount,
+// for a sample of requests served from virtual tree, it also reads physical
+// source and reports any divergence to the given func. It is useful while
+// validating migration to embedded assets.
+//
+// Comparison happens in the request path, so enable it only during
+// validation. Shadow implements `vfs.FileSystem`.
+type Shadow struct {
+	m          *Mount
+	sampleRate uint64
+	counter    uint64
+	onDiverge  func(Divergence)
+}
+
+// NewShadow method creates shadow-compare wrapper for given mount. Every
+// sampleRate-th request (Open, ReadFile, ReadDir) is compared, value <= 1
+// compares every request.
+func NewShadow(m *Mount, sampleRate int, onDiverge func(Divergence)) *Shadow {
+	if sampleRate < 1 {
+		sampleRate = 1
+	}
+	return &Shadow{m: m, sampleRate: uint64(sampleRate), onDiverge: onDiverge}
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Shadow's FileSystem interface
+//______________________________________________________________________________
+
+// Open method behaviour is same as `os.Open`.
+func (s *Shadow) Open(name string) (File, error) {
+	f, err := s.m.Open(name)
+	if err == nil && s.sample() {
+		s.compareStat("open", name)
+	}
+	return f, err
+}
+
+// OpenFile method behaviour is same as `os.OpenFile`.
+func (s *Shadow) OpenFile(name string, flag int, perm os.FileMode) (File, error) {
+	if isWriteFlag(flag) {
+		return s.m.OpenFile(name, flag, perm)
+	}
+	return s.Open(name)
+}
+
+// Lstat method behaviour is same as `os.Lstat`.
+func (s *Shadow) Lstat(name string) (os.FileInfo, error) {
+	return s.m.Lstat(name)
+}
+
+// Stat method behaviour is same as `os.Stat`.
+func (s *Shadow) Stat(name string) (os.FileInfo, error) {
+	return s.m.Stat(name)
+}
+
+// Readlink method behaviour is same as `os.Readlink`.
+func (s *Shadow) Readlink(name string) (string, error) {
+	return s.m.Readlink(name)
+}
+
+// ReadFile method behaviour is same as `ioutil.ReadFile`.
+func (s *Shadow) ReadFile(filename string) ([]byte, error) {
+	data, err := s.m.ReadFile(filename)
+	if err == nil && s.sample() {
+		s.compareContent(filename, data)
+	}
+	return data, err
+}
+
+// ReadDir method behaviour is same as `ioutil.ReadDir`.
+func (s *Shadow) ReadDir(dirname string) ([]os.FileInfo, error) {
+	list, err := s.m.ReadDir(dirname)
+	if err == nil && s.sample() {
+		s.compareEntries(dirname, list)
+	}
+	return list, err
+}
+
+// Glob method behaviour is same as `vfs.Mount.Glob`.
+func (s *Shadow) Glob(pattern string) ([]string, error) {
+	return s.m.Glob(pattern)
+}
+
+// IsExists method is helper to find existence.
+func (s *Shadow) IsExists(name string) bool {
+	return s.m.IsExists(name)
+}
+
+// String method Stringer interface.
+func (s *Shadow) String() string {
+	return fmt.Sprintf("shadow(%s => %s)", s.m.Vroot, s.m.Proot)
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Shadow unexported methods
+//______________________________________________________________________________
+
+func (s *Shadow) sample() bool {
+	return s.onDiverge != nil &&
+		(atomic.AddUint64(&s.counter, 1)-1)%s.sampleRate == 0
+}
+
+// compareStat method compares the virtual node info with physical source, it
+// returns the physical path and true if the name is served from virtual tree
+// and both are alike. Name is resolved same as the mount does, i.e. virtual
+// symbolic links are followed, and the name refused by the mount is not
+// compared.
+func (s *Shadow) compareStat(op, name string) (string, bool) {
+	vname, err := cleanPath(op, name)
+	if err != nil {
+		return "", false
+	}
+	if vname, err = s.m.followLinks(op, vname, true); err != nil {
+		return "", false
+	}
+	pname, err := s.m.physicalPath(op, vname)
+	if err != nil {
+		return "", false
+	}
+	vf, err := s.m.open(vname)
+	if err != nil { // served from physical filesystem
+		return "", false
+	}
+
+	pfi, err := os.Stat(pname)
+	switch {
+	case err != nil:
+		s.report(op, name, DivergenceMissing, err)
+		return "", false
+	case vf.IsDir() != pfi.IsDir():
+		s.report(op, name, DivergenceType, nil)
+		return "", false
+	case !vf.IsDir() && vf.Size() != pfi.Size():
+		s.report(op, name, DivergenceSize, nil)
+		return "", false
+	}
+	return pname, true
+}
+
+func (s *Shadow) compareContent(name string, data []byte) {
+	pname, ok := s.compareStat("readfile", name)
+	if !ok {
+		return
+	}
+
+	pdata, err := ioutil.ReadFile(pname)
+	if err != nil {
+		s.report("readfile", name, DivergenceMissing, err)
+		return
+	}
+	if !bytes.Equal(data, pdata) {
+		s.report("readfile", name, DivergenceContent, nil)
+	}
+}
+
+func (s *Shadow) compareEntries(dirname string, list []os.FileInfo) {
+	pname, ok := s.compareStat("readdir", dirname)
+	if !ok {
+		return
+	}
+
+	plist, err := ioutil.ReadDir(pname)
+	if err != nil {
+		s.report("readdir", dirname, DivergenceMissing, err)
+		return
+	}
+
+	if len(list) != len(plist) {
+		s.report("readdir", dirname, DivergenceEntries, nil)
+		return
+	}
+
+	vlist := append([]os.FileInfo{}, list...)
+	sort.Sort(byName(vlist))
+	for i := range vlist {
+		if vlist[i].Name() != plist[i].Name() {
+			s.report("readdir", dirname, DivergenceEntries, nil)
+			return
+		}
+	}
+}
+
+func (s *Shadow) report(op, name, kind string, err error) {
+	s.onDiverge(Divergence{Op: op, Path: name, Kind: kind, Err: err})
+}
@@ -0,0 +1,167 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Signature errors
+var (
+	ErrNoSignature       = errors.New("vfs: mount has no asset signature")
+	ErrSignatureMismatch = errors.New("vfs: asset signature verification failed")
+)
+
+// SetSignature method sets the hex ed25519 signature of the asset manifest
+// of mount, its called by `vfs.Binary` generated code and pack loading. See
+// `BinaryOptions.SigningKey` and `Mount.VerifySignature`.
+func (m *Mount) SetSignature(sig string) error {
+	b, err := hex.DecodeString(sig)
+	if err != nil {
+		return &os.PathError{Op: "setsignature", Path: m.Vroot, Err: err}
+	}
+	m.treeMu.Lock()
+	defer m.treeMu.Unlock()
+	m.signature = b
+	return nil
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Signature unexported methods
+//______________________________________________________________________________
+
+const signedManifestHeader = "aah-vfs-manifest-v1\n"
+
+// Signed manifest value of directory and prefix of symbolic link target,
+// value of file is its checksum.
+const (
+	signedDir     = "dir"
+	signedSymlink = "symlink:"
+)
+
+// signedManifest method returns the message signed for asset manifest, it is
+// the lines of value and path relative to mount root sorted by path. So the
+// signature holds when the mount is attached on other path.
+func signedManifest(root string, sums map[string]string) []byte {
+	names := make([]string, 0, len(sums))
+	for name := range sums {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buf := new(bytes.Buffer)
+	_, _ = buf.WriteString(signedManifestHeader)
+	for _, name := range names {
+		rel := "/" + strings.TrimPrefix(strings.TrimPrefix(name, root), "/")
+		fmt.Fprintf(buf, "%s  %s\n", sums[name], rel)
+	}
+	return buf.Bytes()
+}
+
+// sign method returns the hex signature of collected manifest, empty if
+// signing key is not set.
+func (g *binaryGen) sign() (string, error) {
+	if len(g.opts.SigningKey) == 0 {
+		return "", nil
+	}
+	sums := make(map[string]string, len(g.entries))
+	for _, e := range g.entries {
+		if e.fi.IsDir() {
+			sums[e.vpath] = signedDir
+		}
+	}
+	for _, e := range g.manifest {
+		sums[e.Path] = e.SHA256
+	}
+	sig, err := signMessage(g.opts.SigningKey, signedManifest(g.mountPath, sums))
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(sig), nil
+}
+
+// writeSignature method writes the set signature statement, if signing key
+// is set.
+func (g *binaryGen) writeSignature(bw *bufio.Writer) error {
+	sig, err := g.sign()
+	if err != nil || sig == "" {
+		return err
+	}
+	fmt.Fprintf(bw, "\tadd(m.SetSignature(%q))\n", sig)
+	return nil
+}
+
+// signedSums method returns the manifest values of mount nodes for
+// signature verification. Checksum is computed from the data, physical file
+// is read from physical filesystem, so the tampered physical file fails the
+// verification too.
+func (m *Mount) signedSums() (map[string]string, error) {
+	var nodes []*node
+	m.treeMu.RLock()
+	if m.tree != nil {
+		for _, c := range m.tree.childs {
+			nodes = signedNodes(c, nodes)
+		}
+	}
+	m.treeMu.RUnlock()
+
+	sums := make(map[string]string, len(nodes))
+	for _, n := range nodes {
+		switch {
+		case n.Symlink != "":
+			sums[n.Path] = signedSymlink + strconv.Quote(n.Symlink)
+		case n.IsDir():
+			sums[n.Path] = signedDir
+		case n.Physical:
+			sum, err := m.physicalSum(n.Path)
+			if err != nil {
+				return nil, &os.PathError{Op: "verifysignature", Path: n.Path, Err: err}
+			}
+			sums[n.Path] = sum
+		default:
+			sum, _, issue := m.digestNode(n)
+			if issue != nil {
+				return nil, &os.PathError{Op: "verifysignature", Path: n.Path, Err: errors.New(issue.Detail)}
+			}
+			sums[n.Path] = sum
+		}
+	}
+	return sums, nil
+}
+
+// physicalSum method returns the checksum of physical file content of given
+// virtual name.
+func (m *Mount) physicalSum(name string) (string, error) {
+	f, err := m.openPhysical(name)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// signedNodes method appends the given node and its descendants. Caller
+// holds the tree lock.
+func signedNodes(n *node, nodes []*node) []*node {
+	nodes = append(nodes, n)
+	for _, c := range n.childs {
+		nodes = signedNodes(c, nodes)
+	}
+	return nodes
+}
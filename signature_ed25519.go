@@ -0,0 +1,53 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+//go:build go1.13
+// +build go1.13
+
+package vfs
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"os"
+)
+
+// VerifySignature method verifies the asset signature of mount with given
+// ed25519 public key, for e.g. on application startup to detect tampering
+// with the embedded assets of shipped binary. Checksum of each file is
+// computed from its data, physical file from its content on disk, so the
+// changed data and recorded checksum both are detected. Directories and
+// symbolic link targets are signed too. Encrypted files need the decryption
+// key set prior.
+//
+// It returns `ErrNoSignature` if the mount is not signed and
+// `ErrSignatureMismatch` if verification fails.
+func (m *Mount) VerifySignature(pubKey ed25519.PublicKey) error {
+	m.treeMu.RLock()
+	sig := m.signature
+	m.treeMu.RUnlock()
+	if len(sig) == 0 {
+		return &os.PathError{Op: "verifysignature", Path: m.Vroot, Err: ErrNoSignature}
+	}
+	if len(pubKey) != ed25519.PublicKeySize {
+		return &os.PathError{Op: "verifysignature", Path: m.Vroot, Err: errors.New("invalid public key size")}
+	}
+
+	sums, err := m.signedSums()
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(pubKey, signedManifest(m.Vroot, sums), sig) {
+		return &os.PathError{Op: "verifysignature", Path: m.Vroot, Err: ErrSignatureMismatch}
+	}
+	return nil
+}
+
+// signMessage method signs the message with ed25519 private key.
+func signMessage(key, msg []byte) ([]byte, error) {
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, errors.New("vfs: invalid ed25519 private key size")
+	}
+	return ed25519.Sign(ed25519.PrivateKey(key), msg), nil
+}
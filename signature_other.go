@@ -0,0 +1,15 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+//go:build !go1.13
+// +build !go1.13
+
+package vfs
+
+import "errors"
+
+// signMessage method returns an error, ed25519 is available from go1.13.
+func signMessage(key, msg []byte) ([]byte, error) {
+	return nil, errors.New("vfs: asset signing requires go1.13 or later")
+}
@@ -0,0 +1,144 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+//go:build go1.13
+// +build go1.13
+
+package vfs
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"aahframework.org/test.v0/assert"
+)
+
+func TestVFSPackSignature(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	assert.FailNowOnError(t, err, "")
+	src := filepath.Join(testdataBaseDir(), "vfstest", "static")
+	var buf bytes.Buffer
+	assert.Nil(t, Pack(&buf, "/app/static", src, nil, BinaryOptions{SigningKey: priv, MinCompressSize: 1 << 20}))
+
+	m, err := LoadPack(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.FailNowOnError(t, err, "")
+	assert.Nil(t, m.VerifySignature(pub))
+
+	other, _, err := ed25519.GenerateKey(nil)
+	assert.FailNowOnError(t, err, "")
+	err = m.VerifySignature(other)
+	assert.Equal(t, ErrSignatureMismatch, err.(*os.PathError).Err)
+	err = m.VerifySignature(pub[:10])
+	assert.NotNil(t, err)
+
+	// signature holds on other mount path
+	fs := new(VFS)
+	assert.Nil(t, fs.AddMount("/app", src))
+	dir, err := ioutil.TempDir("", "vfs-signature")
+	assert.FailNowOnError(t, err, "")
+	defer func() { _ = os.RemoveAll(dir) }()
+	packFile := filepath.Join(dir, "static.pack")
+	f, err := os.Create(packFile)
+	assert.FailNowOnError(t, err, "")
+	_, err = f.Write(buf.Bytes())
+	assert.Nil(t, err)
+	assert.Nil(t, f.Close())
+	assert.Nil(t, fs.AttachPack("/app/assets", packFile))
+	am, err := fs.FindMount("/app/assets")
+	assert.FailNowOnError(t, err, "")
+	assert.Nil(t, am.VerifySignature(pub))
+
+	// tampered file data
+	tampered := bytes.Replace(buf.Bytes(), []byte("User-agent"), []byte("User-Agent"), 1)
+	assert.NotEqual(t, buf.Bytes(), tampered)
+	m, err = LoadPack(bytes.NewReader(tampered), int64(len(tampered)))
+	assert.FailNowOnError(t, err, "")
+	err = m.VerifySignature(pub)
+	assert.Equal(t, ErrSignatureMismatch, err.(*os.PathError).Err)
+
+	// unsigned
+	buf.Reset()
+	assert.Nil(t, Pack(&buf, "/app/static", src, nil, BinaryOptions{}))
+	m, err = LoadPack(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.FailNowOnError(t, err, "")
+	err = m.VerifySignature(pub)
+	assert.Equal(t, ErrNoSignature, err.(*os.PathError).Err)
+}
+
+func TestVFSBinarySignature(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(nil)
+	assert.FailNowOnError(t, err, "")
+	src := filepath.Join(testdataBaseDir(), "vfstest")
+	opts := BinaryOptions{SigningKey: priv}
+
+	code, err := BinaryWithOptions("/app", src, nil, opts)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, bytes.Count(code, []byte("\tadd(m.SetSignature(")))
+
+	codes, err := BinarySplit("/app", src, nil, opts, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(codes))
+	assert.False(t, bytes.Contains(codes[0], []byte("SetSignature")))
+	assert.False(t, bytes.Contains(codes[1], []byte("SetSignature")))
+	assert.True(t, bytes.Contains(codes[2], []byte("\tadd(m.SetSignature(")))
+
+	_, err = BinaryWithOptions("/app", src, nil, BinaryOptions{SigningKey: priv[:32]})
+	assert.NotNil(t, err)
+
+	m, err := NewMount("/app", "")
+	assert.FailNowOnError(t, err, "")
+	assert.NotNil(t, m.SetSignature("not hex"))
+}
+
+func TestVFSSignaturePhysicalFile(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	assert.FailNowOnError(t, err, "")
+	dir, err := ioutil.TempDir("", "vfs-signature")
+	assert.FailNowOnError(t, err, "")
+	defer func() { _ = os.RemoveAll(dir) }()
+	content := []byte("User-agent: *\n")
+	fpath := filepath.Join(dir, "robots.txt")
+	assert.Nil(t, ioutil.WriteFile(fpath, content, 0644))
+	sum := sha256.Sum256(content)
+
+	m, err := NewMount("/app", dir)
+	assert.FailNowOnError(t, err, "")
+	assert.Nil(t, m.AddDir(&NodeInfo{Path: "/app/css", Dir: true}))
+	assert.Nil(t, m.AddFile(&NodeInfo{DataSize: int64(len(content)), Path: "/app/robots.txt",
+		SHA256: hex.EncodeToString(sum[:]), Physical: true}, nil))
+	sig, err := signMessage(priv, signedManifest("/app", map[string]string{
+		"/app/css":        signedDir,
+		"/app/robots.txt": hex.EncodeToString(sum[:]),
+	}))
+	assert.FailNowOnError(t, err, "")
+	assert.Nil(t, m.SetSignature(hex.EncodeToString(sig)))
+	assert.Nil(t, m.VerifySignature(pub))
+
+	// tampered physical file, recorded checksum is unchanged
+	assert.Nil(t, ioutil.WriteFile(fpath, []byte("User-agent: evil\n"), 0644))
+	err = m.VerifySignature(pub)
+	assert.Equal(t, ErrSignatureMismatch, err.(*os.PathError).Err)
+	assert.Nil(t, ioutil.WriteFile(fpath, content, 0644))
+	assert.Nil(t, m.VerifySignature(pub))
+
+	// added symbolic link and directory
+	assert.Nil(t, m.Symlink("/app/robots.txt", "/app/css/robots.txt"))
+	err = m.VerifySignature(pub)
+	assert.Equal(t, ErrSignatureMismatch, err.(*os.PathError).Err)
+	assert.Nil(t, m.RemoveFile("/app/css/robots.txt"))
+	assert.Nil(t, m.VerifySignature(pub))
+	assert.Nil(t, m.AddDir(&NodeInfo{Path: "/app/js", Dir: true}))
+	err = m.VerifySignature(pub)
+	assert.Equal(t, ErrSignatureMismatch, err.(*os.PathError).Err)
+
+	// missing physical file
+	assert.Nil(t, os.Remove(fpath))
+	assert.NotNil(t, m.VerifySignature(pub))
+}
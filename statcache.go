@@ -0,0 +1,123 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultStatCacheSize is the default no. of entries of `vfs.StatCache`.
+const DefaultStatCacheSize = 1024
+
+// StatCache option caches the results of Stat and Lstat served by physical
+// filesystem fallback of the mount, including non-existence, for given ttl.
+// Frameworks stat the same missing path (for e.g. favicon.ico, optional
+// config) on every request, cached result saves the system call. Value 0
+// of ttl means never expire. At most maxEntries results are cached, value 0
+// means `DefaultStatCacheSize`; expired entries are dropped first when it is
+// full.
+//
+// Errors other than non-existence are not cached. Use `Mount.Invalidate` to
+// drop the cached results on change, `Mount.Watch` does it for changed paths.
+func StatCache(ttl time.Duration, maxEntries int) MountOption {
+	return func(m *Mount) {
+		if maxEntries <= 0 {
+			maxEntries = DefaultStatCacheSize
+		}
+		m.statCache = &statCache{ttl: ttl, max: maxEntries, entries: make(map[statKey]statEntry)}
+	}
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Stat cache unexported types and methods
+//______________________________________________________________________________
+
+// statCache holds the physical Stat (follow) and Lstat results by virtual
+// path.
+type statCache struct {
+	ttl     time.Duration
+	max     int
+	mu      sync.Mutex
+	entries map[statKey]statEntry
+}
+
+type statKey struct {
+	name   string
+	follow bool
+}
+
+type statEntry struct {
+	fi     os.FileInfo
+	err    error
+	expire time.Time
+}
+
+// get method returns the cached result of given clean name, false if it is
+// not cached or expired.
+func (c *statCache) get(name string, follow bool) (statEntry, bool) {
+	k := statKey{name: name, follow: follow}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, found := c.entries[k]
+	if found && c.ttl > 0 && !time.Now().Before(e.expire) {
+		delete(c.entries, k)
+		return statEntry{}, false
+	}
+	return e, found
+}
+
+// put method caches the result, it is no-op for errors other than
+// non-existence.
+func (c *statCache) put(name string, follow bool, fi os.FileInfo, err error) {
+	if err != nil && !os.IsNotExist(err) {
+		return
+	}
+	e := statEntry{fi: fi, err: err}
+	if c.ttl > 0 {
+		e.expire = time.Now().Add(c.ttl)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= c.max {
+		c.shrink()
+	}
+	c.entries[statKey{name: name, follow: follow}] = e
+}
+
+// shrink method drops the expired entries, if none then an arbitrary one to
+// make room. Caller holds the lock.
+func (c *statCache) shrink() {
+	now := time.Now()
+	for k, e := range c.entries {
+		if c.ttl > 0 && !now.Before(e.expire) {
+			delete(c.entries, k)
+		}
+	}
+	for k := range c.entries {
+		if len(c.entries) < c.max {
+			break
+		}
+		delete(c.entries, k)
+	}
+}
+
+// invalidate method drops the cached results of given virtual path, its
+// descendants and its parent directory.
+func (c *statCache) invalidate(name string) {
+	name = path.Clean(name)
+	parent, prefix := path.Dir(name), strings.TrimSuffix(name, "/")+"/"
+	c.mu.Lock()
+	for k := range c.entries {
+		if k.name == name || k.name == parent || strings.HasPrefix(k.name, prefix) {
+			delete(c.entries, k)
+		}
+	}
+	c.mu.Unlock()
+}
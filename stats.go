@@ -0,0 +1,129 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import "fmt"
+
+// MountStats struct represents the memory footprint of mount virtual tree.
+type MountStats struct {
+	// Dirs, Files and Symlinks are no. of nodes in the virtual tree,
+	// excluding the mount root.
+	Dirs     int
+	Files    int
+	Symlinks int
+
+	// DataSize is the bytes of file data held by the tree, data shared
+	// between files (see `Mount.AddLink`) is counted once.
+	DataSize int64
+
+	// ArenaSize is the bytes allocated by mount data arena for
+	// `Mount.AddFile`, including the unused tail of its chunks.
+	ArenaSize int64
+
+	// PathSize is the bytes of node path strings, name of node shares the
+	// backing array of its path.
+	PathSize int64
+
+	// CacheEntries and CacheSize is the decompressed data cache (see
+	// `Mount.Warm`), compressed physical file cache (see
+	// `vfs.CompressCache`) and physical file data cache (see
+	// `vfs.ContentCache`).
+	CacheEntries int
+	CacheSize    int64
+
+	// Interned is no. of distinct checksum and symbolic link target strings
+	// shared by the nodes.
+	Interned int
+}
+
+// String method Stringer interface.
+func (s MountStats) String() string {
+	return fmt.Sprintf("mountstats(dirs=%d files=%d symlinks=%d data=%d arena=%d paths=%d cache=%d/%d interned=%d)",
+		s.Dirs, s.Files, s.Symlinks, s.DataSize, s.ArenaSize, s.PathSize, s.CacheEntries, s.CacheSize, s.Interned)
+}
+
+// Stats method returns the memory footprint of mount virtual tree and its
+// caches, for e.g. to size the memory budget of `VFS.SetMemoryLimit`. It
+// walks the tree, so call it periodically not per request.
+func (m *Mount) Stats() MountStats {
+	var s MountStats
+	m.treeMu.RLock()
+	seen := make(map[*byte]bool)
+	countData := func(b []byte) {
+		if len(b) > 0 && !seen[&b[0]] {
+			seen[&b[0]] = true
+			s.DataSize += int64(len(b))
+		}
+	}
+	var walk func(n *node)
+	walk = func(n *node) {
+		for _, c := range n.childInfos {
+			cn := c.(*node)
+			s.PathSize += int64(len(cn.Path))
+			switch {
+			case cn.Symlink != "":
+				s.Symlinks++
+			case cn.IsDir():
+				s.Dirs++
+				walk(cn)
+				continue
+			default:
+				s.Files++
+			}
+			countData(cn.data)
+			for _, b := range cn.encoded {
+				countData(b)
+			}
+		}
+	}
+	walk(m.tree)
+	s.Interned = m.strs.len()
+	m.treeMu.RUnlock()
+
+	s.ArenaSize = m.arena.size()
+	s.CacheEntries, s.CacheSize = m.cache.size()
+	if m.gzCache != nil {
+		entries, size := m.gzCache.size()
+		s.CacheEntries += entries
+		s.CacheSize += size
+	}
+	if m.contentCache != nil {
+		entries, size := m.contentCache.data.size()
+		s.CacheEntries += entries
+		s.CacheSize += size
+	}
+	return s
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Stats unexported types and methods
+//______________________________________________________________________________
+
+// stringPool interns the repeated strings of nodes, for e.g. checksum of
+// identical files, so the nodes share one copy. It is guarded by the tree
+// lock of mount.
+type stringPool struct {
+	strs map[string]string
+}
+
+func newStringPool() *stringPool {
+	return &stringPool{strs: make(map[string]string)}
+}
+
+// intern method returns the pooled copy of s, empty string is not pooled.
+func (p *stringPool) intern(s string) string {
+	if s == "" {
+		return s
+	}
+	if ps, found := p.strs[s]; found {
+		return ps
+	}
+	p.strs[s] = s
+	return s
+}
+
+func (p *stringPool) len() int {
+	return len(p.strs)
+}
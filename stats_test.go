@@ -0,0 +1,68 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"strings"
+	"testing"
+	"time"
+
+	"aahframework.org/test.v0/assert"
+)
+
+func TestMountStats(t *testing.T) {
+	m, err := NewMount("/app", "")
+	assert.Nil(t, err)
+	assert.Equal(t, MountStats{}, m.Stats())
+
+	now := time.Now()
+	sum := strings.Repeat("ab", 32)
+	assert.Nil(t, m.AddDir(&NodeInfo{Dir: true, Path: "/app/static", Time: now}))
+	assert.Nil(t, m.AddFile(&NodeInfo{Path: "/app/static/a.css", DataSize: 5, SHA256: sum, Time: now}, []byte("body{")))
+	assert.Nil(t, m.AddLink(&NodeInfo{Path: "/app/static/b.css", DataSize: 5, SHA256: sum, Time: now}, "/app/static/a.css"))
+	assert.Nil(t, m.AddFile(&NodeInfo{Path: "/app/robots.txt", DataSize: 3, Time: now}, []byte("ok\n")))
+	assert.Nil(t, m.Symlink("/app/robots.txt", "/app/robots"))
+
+	s := m.Stats()
+	assert.Equal(t, 1, s.Dirs)
+	assert.Equal(t, 3, s.Files)
+	assert.Equal(t, 1, s.Symlinks)
+	assert.Equal(t, int64(8), s.DataSize) // a.css and b.css share the data
+	assert.Equal(t, int64(defaultArenaChunkSize), s.ArenaSize)
+	assert.Equal(t, int64(len("/app/static/app/static/a.css/app/static/b.css/app/robots.txt/app/robots")), s.PathSize)
+	assert.Equal(t, 2, s.Interned) // checksum and symbolic link target
+	assert.Equal(t, 0, s.CacheEntries)
+	assert.True(t, strings.HasPrefix(s.String(), "mountstats(dirs=1 files=3 symlinks=1 data=8"))
+
+	a, _ := m.tree.lookup("/static/a.css")
+	b, _ := m.tree.lookup("/static/b.css")
+	assert.Equal(t, sum, a.SHA256)
+	assert.Equal(t, &a.data[0], &b.data[0])
+}
+
+func TestMountStatsWarm(t *testing.T) {
+	m, err := NewMount("/app", "")
+	assert.Nil(t, err)
+
+	data := bytes.Repeat([]byte("aah framework "), 1000)
+	buf := new(bytes.Buffer)
+	gw := gzip.NewWriter(buf)
+	_, err = gw.Write(data)
+	assert.Nil(t, err)
+	assert.Nil(t, gw.Close())
+	assert.Nil(t, m.AddFile(&NodeInfo{Path: "/app/index.html", DataSize: int64(len(data)), Time: time.Now()}, buf.Bytes()))
+
+	assert.Nil(t, m.Warm("/app/index.html"))
+	s := m.Stats()
+	assert.Equal(t, 1, s.CacheEntries)
+	assert.Equal(t, int64(len(data)), s.CacheSize)
+	assert.Equal(t, int64(buf.Len()), s.DataSize)
+
+	rdata, err := m.ReadFile("/app/index.html")
+	assert.Nil(t, err)
+	assert.True(t, bytes.Equal(data, rdata))
+}
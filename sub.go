@@ -0,0 +1,173 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+)
+
+var _ FileSystem = (*subFS)(nil)
+
+// Sub method returns the FileSystem view rooted at directory dir of given
+// fs, like `io/fs.Sub`. Name `/` of the view is the dir, for e.g.:
+// `/index.html` of view `/app/views` opens `/app/views/index.html` of fs.
+// Names outside of dir are not reachable, so a component can be handed only
+// the directory it needs without seeing the sibling paths. However symbolic
+// links under dir are followed by fs, so a link added via `Mount.Symlink`
+// can lead to the target outside of dir.
+//
+// Path of `*os.PathError` returned by the view is the name given to view.
+func Sub(fs FileSystem, dir string) (FileSystem, error) {
+	dir, err := cleanPath("sub", dir)
+	if err != nil {
+		return nil, err
+	}
+	if dir == "/" {
+		return fs, nil
+	}
+
+	fi, err := fs.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return nil, &os.PathError{Op: "sub", Path: dir, Err: errors.New("not a directory")}
+	}
+
+	if s, ok := fs.(*subFS); ok {
+		return &subFS{fs: s.fs, dir: path.Join(s.dir, dir)}, nil
+	}
+	return &subFS{fs: fs, dir: dir}, nil
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Sub unexported types and methods
+//______________________________________________________________________________
+
+type subFS struct {
+	fs  FileSystem
+	dir string
+}
+
+func (s *subFS) Open(name string) (File, error) {
+	fname, err := s.fullName("open", name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := s.fs.Open(fname)
+	return f, s.fixErr(err, name)
+}
+
+func (s *subFS) OpenFile(name string, flag int, perm os.FileMode) (File, error) {
+	fname, err := s.fullName("open", name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := s.fs.OpenFile(fname, flag, perm)
+	return f, s.fixErr(err, name)
+}
+
+func (s *subFS) Lstat(name string) (os.FileInfo, error) {
+	fname, err := s.fullName("lstat", name)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := s.fs.Lstat(fname)
+	return fi, s.fixErr(err, name)
+}
+
+func (s *subFS) Stat(name string) (os.FileInfo, error) {
+	fname, err := s.fullName("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := s.fs.Stat(fname)
+	return fi, s.fixErr(err, name)
+}
+
+func (s *subFS) Readlink(name string) (string, error) {
+	fname, err := s.fullName("readlink", name)
+	if err != nil {
+		return "", err
+	}
+	target, err := s.fs.Readlink(fname)
+	return target, s.fixErr(err, name)
+}
+
+func (s *subFS) ReadFile(filename string) ([]byte, error) {
+	fname, err := s.fullName("read", filename)
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.fs.ReadFile(fname)
+	return data, s.fixErr(err, filename)
+}
+
+func (s *subFS) ReadDir(dirname string) ([]os.FileInfo, error) {
+	fname, err := s.fullName("open", dirname)
+	if err != nil {
+		return nil, err
+	}
+	list, err := s.fs.ReadDir(fname)
+	return list, s.fixErr(err, dirname)
+}
+
+func (s *subFS) Glob(pattern string) ([]string, error) {
+	fpattern, err := s.fullName("glob", pattern)
+	if err != nil {
+		return nil, err
+	}
+	list, err := s.fs.Glob(fpattern)
+	if err != nil {
+		return nil, s.fixErr(err, pattern)
+	}
+
+	matches := make([]string, 0, len(list))
+	for _, m := range list {
+		if isPathWithin(m, s.dir) {
+			matches = append(matches, s.shorten(m))
+		}
+	}
+	return matches, nil
+}
+
+func (s *subFS) IsExists(name string) bool {
+	_, err := s.Lstat(name)
+	return err == nil
+}
+
+func (s *subFS) String() string {
+	return fmt.Sprintf("sub(%s %v)", s.dir, s.fs)
+}
+
+// fullName method returns the name of underlying fs for given view name.
+func (s *subFS) fullName(op, name string) (string, error) {
+	name, err := cleanPath(op, name)
+	if err != nil {
+		return "", err
+	}
+	return path.Join(s.dir, name), nil
+}
+
+// shorten method returns the view name of given name of underlying fs.
+func (s *subFS) shorten(name string) string {
+	if name == s.dir {
+		return "/"
+	}
+	return strings.TrimPrefix(name, s.dir)
+}
+
+// fixErr method rewrites the path of `*os.PathError` to view name, so that
+// error does not reveal the paths outside of view, i.e. virtual or physical.
+func (s *subFS) fixErr(err error, name string) error {
+	if pe, ok := err.(*os.PathError); ok {
+		return &os.PathError{Op: pe.Op, Path: name, Err: pe.Err}
+	}
+	return err
+}
@@ -0,0 +1,135 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"errors"
+	"os"
+	"path"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ErrTooManyLinks is returned when symbolic link resolution exceeds
+// `maxSymlinkHops`, for e.g.: link loop.
+var ErrTooManyLinks = errors.New("vfs: too many levels of symbolic links")
+
+// maxSymlinkHops is the no. of symbolic links followed on path resolution,
+// same as Linux.
+const maxSymlinkHops = 40
+
+// Symlink method creates newname as symbolic link to oldname in virtual
+// tree, like `os.Symlink`. Target oldname is virtual path, relative target
+// is resolved against the directory of newname. Target does not need to
+// exist, it has to be within the mount on resolution. Parent directory of
+// newname must exist.
+//
+// Open, Stat and ReadDir follow the links with loop detection, Lstat
+// returns the link itself with mode `os.ModeSymlink`.
+func (m *Mount) Symlink(oldname, newname string) error {
+	lerr := func(err error) error {
+		if pe, ok := err.(*os.PathError); ok {
+			err = pe.Err
+		}
+		return &os.LinkError{Op: "symlink", Old: oldname, New: newname, Err: err}
+	}
+
+	newname, err := cleanPath("symlink", newname)
+	if err != nil {
+		return lerr(err)
+	}
+	if oldname == "" || !isPathWithin(newname, m.Vroot) || newname == m.Vroot {
+		return lerr(os.ErrInvalid)
+	}
+
+	m.treeMu.Lock()
+	defer m.treeMu.Unlock()
+	if m.sealed {
+		return lerr(ErrReadOnly)
+	}
+	if _, err = m.openNode(newname); err == nil {
+		return lerr(os.ErrExist)
+	}
+	if d, err := m.openNode(path.Dir(newname)); err != nil || !d.IsDir() {
+		return lerr(os.ErrNotExist)
+	}
+
+	fi := &NodeInfo{Path: newname, Symlink: oldname, Time: time.Now().UTC()}
+	if err = m.insertNode(fi, nil); err != nil {
+		return lerr(err)
+	}
+	return nil
+}
+
+// Readlink method behaviour is same as `os.Readlink`, it returns the target
+// of virtual symbolic link, otherwise physical symbolic link.
+func (m *Mount) Readlink(name string) (string, error) {
+	name, err := cleanPath("readlink", name)
+	if err != nil {
+		return "", err
+	}
+	fi, err := m.Lstat(name)
+	if err != nil {
+		return "", err
+	}
+	if fi.Mode()&os.ModeSymlink == 0 {
+		return "", &os.PathError{Op: "readlink", Path: name, Err: os.ErrInvalid}
+	}
+	if ni, ok := fi.(symlinkInfo); ok {
+		return ni.linkTarget(), nil
+	}
+	return os.Readlink(m.toPhysicalPath(name))
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Symlink unexported methods
+//______________________________________________________________________________
+
+// symlinkInfo is implemented by the info of virtual symbolic link.
+type symlinkInfo interface {
+	linkTarget() string
+}
+
+func (n NodeInfo) linkTarget() string {
+	return n.Symlink
+}
+
+// followLinks method returns the name with virtual symbolic links resolved,
+// last element is resolved only if follow is true. Name is returned as-is if
+// mount has no symbolic links.
+func (m *Mount) followLinks(op, name string, follow bool) (string, error) {
+	if atomic.LoadInt32(&m.symlinks) == 0 || !isPathWithin(name, m.Vroot) {
+		return name, nil
+	}
+
+	m.treeMu.RLock()
+	defer m.treeMu.RUnlock()
+	hops := 0
+	rest := splitPath(strings.TrimPrefix(name, m.Vroot))
+	cur := m.Vroot
+	for len(rest) > 0 {
+		next := path.Join(cur, rest[0])
+		f, err := m.openNode(next)
+		if err != nil || f.Symlink == "" || (!follow && len(rest) == 1) {
+			cur, rest = next, rest[1:]
+			continue
+		}
+
+		if hops++; hops > maxSymlinkHops {
+			return "", &os.PathError{Op: op, Path: name, Err: ErrTooManyLinks}
+		}
+		target := f.Symlink
+		if !path.IsAbs(target) {
+			target = path.Join(cur, target)
+		}
+		target = path.Clean(target)
+		if !isPathWithin(target, m.Vroot) {
+			return "", &os.PathError{Op: op, Path: name, Err: os.ErrNotExist}
+		}
+		cur, rest = m.Vroot, append(splitPath(strings.TrimPrefix(target, m.Vroot)), rest[1:]...)
+	}
+	return cur, nil
+}
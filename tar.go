@@ -0,0 +1,199 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+)
+
+// NewTarMount method creates the mount of tar stream content at given
+// virtual root, gzip compressed stream (tar.gz) is detected and decompressed
+// transparently. Tar is a stream, so the file data is read into memory.
+// Entry data is mounted as-is, gzip file entry within archive is not
+// decompressed on read.
+//
+// Directory, regular file, symbolic link and hard link entries are mounted
+// with entry modification time, other entry types are skipped. Link is
+// mounted as copy of its target within archive, it shares the target data.
+// Link with absolute target, target outside of archive or dangling target
+// is skipped, so does the directory link to its own ancestor.
+func NewTarMount(vroot string, r io.Reader, opts ...MountOption) (*Mount, error) {
+	br := bufio.NewReader(r)
+	if hdr, _ := br.Peek(len(gzipMemberHeader)); bytes.Equal(hdr, gzipMemberHeader) {
+		gr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = gr.Close() }()
+		r = gr
+	} else {
+		r = br
+	}
+
+	m, err := newMount(vroot, "", opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var links []tarLink
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		link, err := m.addTarEntry(tr, hdr)
+		if err != nil {
+			return nil, err
+		}
+		if link != nil {
+			links = append(links, *link)
+		}
+	}
+
+	if err = m.resolveTarLinks(links); err != nil {
+		return nil, err
+	}
+	m.sealReadOnly()
+	return m, nil
+}
+
+// OpenTar method opens the tar or tar.gz archive file and mounts it at given
+// virtual root. It can be registered for `VFS.MountArchives`, for e.g.:
+//
+//	vfs.RegisterArchive(".tar", vfs.OpenTar)
+//	vfs.RegisterArchive(".tar.gz", vfs.OpenTar)
+//	vfs.RegisterArchive(".tgz", vfs.OpenTar)
+func OpenTar(vroot, filename string) (*Mount, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	return NewTarMount(vroot, f)
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Tar mount unexported types and methods
+//______________________________________________________________________________
+
+// tarLink is the link entry of tar, its resolved after all the entries are
+// mounted. Paths are relative to archive root.
+type tarLink struct {
+	name   string
+	target string
+	info   NodeInfo
+}
+
+// addTarEntry method adds the tar entry to mount tree, it returns the link
+// entry to be resolved later.
+func (m *Mount) addTarEntry(tr *tar.Reader, hdr *tar.Header) (*tarLink, error) {
+	name, err := cleanPath("tar", hdr.Name)
+	if err != nil {
+		return nil, err
+	}
+	if name == "/" {
+		return nil, nil
+	}
+
+	vpath := path.Join(m.Vroot, name)
+	fi := NodeInfo{Path: vpath, Time: hdr.ModTime.UTC(), Raw: true}
+	switch hdr.Typeflag {
+	case tar.TypeDir, tar.TypeReg, tar.TypeRegA, tar.TypeSymlink, tar.TypeLink:
+	default:
+		return nil, nil
+	}
+	if err = m.mkdirAll(path.Dir(vpath), fi.Time); err != nil {
+		return nil, err
+	}
+
+	switch hdr.Typeflag {
+	case tar.TypeDir:
+		if n, found := m.tree.lookup(name); found && n.IsDir() {
+			n.Time = fi.Time // explicit entry after implicit parent
+			return nil, nil
+		}
+		fi.Dir = true
+		return nil, m.AddDir(&fi)
+	case tar.TypeSymlink:
+		if strings.HasPrefix(hdr.Linkname, "/") {
+			return nil, nil
+		}
+		target := path.Join(strings.TrimPrefix(path.Dir(name), "/"), hdr.Linkname)
+		if target == ".." || strings.HasPrefix(target, "../") {
+			return nil, nil
+		}
+		return &tarLink{name: name, target: path.Join("/", target), info: fi}, nil
+	case tar.TypeLink:
+		target, err := cleanPath("tar", hdr.Linkname)
+		if err != nil {
+			return nil, nil
+		}
+		return &tarLink{name: name, target: target, info: fi}, nil
+	}
+
+	data, err := ioutil.ReadAll(tr)
+	if err != nil {
+		return nil, err
+	}
+	fi.DataSize = int64(len(data))
+	return nil, m.addNode(&fi, data)
+}
+
+// resolveTarLinks method mounts the links as copy of its target, link to
+// another link gets resolved on later pass. Unresolvable links are skipped.
+func (m *Mount) resolveTarLinks(links []tarLink) error {
+	for len(links) > 0 {
+		var pending []tarLink
+		for _, l := range links {
+			t, found := m.tree.lookup(l.target)
+			if !found || (t.IsDir() && isPathWithin(l.name, l.target)) {
+				pending = append(pending, l)
+				continue
+			}
+			if err := m.copyNode(t, l.info); err != nil {
+				return err
+			}
+		}
+		if len(pending) == len(links) {
+			break // no progress, rest are dangling
+		}
+		links = pending
+	}
+	return nil
+}
+
+// copyNode method adds the copy of node n and its descendants at the path
+// of fi, file data is shared.
+func (m *Mount) copyNode(n *node, fi NodeInfo) error {
+	fi.Dir, fi.DataSize = n.IsDir(), n.DataSize
+	if !fi.Dir {
+		return m.addNode(&fi, n.data)
+	}
+	if err := m.AddDir(&fi); err != nil {
+		return err
+	}
+	for _, c := range n.childInfos {
+		cn := c.(*node)
+		ci := *cn.NodeInfo
+		ci.Path = path.Join(fi.Path, cn.Name())
+		if err := m.copyNode(cn, ci); err != nil {
+			return err
+		}
+	}
+	return nil
+}
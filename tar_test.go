@@ -0,0 +1,94 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"aahframework.org/test.v0/assert"
+)
+
+func TestVFSTarMount(t *testing.T) {
+	mtime := time.Date(2018, 3, 12, 10, 20, 30, 0, time.UTC)
+	buf := new(bytes.Buffer)
+	tw := tar.NewWriter(buf)
+	addEntry := func(hdr *tar.Header, content string) {
+		hdr.ModTime = mtime
+		hdr.Size = int64(len(content))
+		hdr.Mode = 0644
+		assert.Nil(t, tw.WriteHeader(hdr))
+		_, err := tw.Write([]byte(content))
+		assert.Nil(t, err)
+	}
+	addEntry(&tar.Header{Name: "static/", Typeflag: tar.TypeDir}, "")
+	addEntry(&tar.Header{Name: "static/css/aah.css", Typeflag: tar.TypeReg}, "body { color: #333; }")
+	addEntry(&tar.Header{Name: "static/app.css", Typeflag: tar.TypeSymlink, Linkname: "css/aah.css"}, "")
+	addEntry(&tar.Header{Name: "assets", Typeflag: tar.TypeSymlink, Linkname: "static"}, "")
+	addEntry(&tar.Header{Name: "main.css", Typeflag: tar.TypeLink, Linkname: "static/css/aah.css"}, "")
+	addEntry(&tar.Header{Name: "static/up", Typeflag: tar.TypeSymlink, Linkname: ".."}, "")
+	addEntry(&tar.Header{Name: "passwd", Typeflag: tar.TypeSymlink, Linkname: "../../etc/passwd"}, "")
+	addEntry(&tar.Header{Name: "dangling", Typeflag: tar.TypeSymlink, Linkname: "not-exists"}, "")
+	addEntry(&tar.Header{Name: "dev/null", Typeflag: tar.TypeChar}, "")
+	gz := gzipString(t, "aah framework")
+	addEntry(&tar.Header{Name: "dl/app.tar.gz", Typeflag: tar.TypeReg}, gz)
+	assert.Nil(t, tw.Close())
+
+	m, err := NewTarMount("/app", bytes.NewReader(buf.Bytes()))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(m.Validate()))
+
+	fi, err := m.Stat("/app/static")
+	assert.Nil(t, err)
+	assert.True(t, fi.IsDir())
+	assert.True(t, fi.ModTime().Equal(mtime))
+
+	for _, name := range []string{"/app/static/css/aah.css", "/app/static/app.css",
+		"/app/assets/css/aah.css", "/app/assets/app.css", "/app/main.css"} {
+		data, err := m.ReadFile(name)
+		assert.FailNowOnError(t, err, name)
+		assert.Equal(t, "body { color: #333; }", string(data))
+	}
+
+	for _, name := range []string{"/app/static/up", "/app/passwd", "/app/dangling", "/app/dev/null"} {
+		assert.False(t, m.IsExists(name))
+	}
+
+	// gzip entry as-is
+	fi, err = m.Stat("/app/dl/app.tar.gz")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(len(gz)), fi.Size())
+	data, err := m.ReadFile("/app/dl/app.tar.gz")
+	assert.Nil(t, err)
+	assert.Equal(t, gz, string(data))
+
+	// tar.gz
+	gzbuf := new(bytes.Buffer)
+	gw := gzip.NewWriter(gzbuf)
+	_, err = gw.Write(buf.Bytes())
+	assert.Nil(t, err)
+	assert.Nil(t, gw.Close())
+
+	dir, err := ioutil.TempDir("", "vfs-tar")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	fname := filepath.Join(dir, "bundle.tar.gz")
+	assert.Nil(t, ioutil.WriteFile(fname, gzbuf.Bytes(), 0644))
+
+	m, err = OpenTar("/bundle", fname)
+	assert.Nil(t, err)
+	data, err = m.ReadFile("/bundle/assets/css/aah.css")
+	assert.Nil(t, err)
+	assert.Equal(t, "body { color: #333; }", string(data))
+
+	_, err = NewTarMount("/app", bytes.NewReader(gzbuf.Bytes()[:20]))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,181 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"fmt"
+	"os"
+)
+
+var _ FileSystem = (*Traced)(nil)
+
+// Span attribute keys set by `vfs.Traced`.
+const (
+	TraceAttrSize    = "vfs.size"    // int64, ReadFile, Stat and Lstat
+	TraceAttrEntries = "vfs.entries" // int, ReadDir
+	TraceAttrMatches = "vfs.matches" // int, Glob
+	TraceAttrExists  = "vfs.exists"  // bool, IsExists
+)
+
+// Tracer interface is implemented by the tracing backend to record the span
+// of FileSystem operations, see `vfs.Traced`. Op is the lowercase operation
+// name, i.e. open, stat, lstat, readlink, readfile, readdir, glob and
+// isexists. For e.g. OpenTelemetry adapter bound to request context
+//
+//	type otelTracer struct {
+//		ctx    context.Context
+//		tracer trace.Tracer
+//	}
+//
+//	func (o otelTracer) StartSpan(op, name string) vfs.Span {
+//		_, span := o.tracer.Start(o.ctx, "vfs."+op,
+//			trace.WithAttributes(attribute.String("vfs.path", name)))
+//		return otelSpan{span}
+//	}
+type Tracer interface {
+	StartSpan(op, name string) Span
+}
+
+// Span interface represents the traced FileSystem operation, End is called
+// once with the error of operation after the attributes are set.
+type Span interface {
+	SetAttribute(key string, value interface{})
+	End(err error)
+}
+
+// Traced is the wrapper on FileSystem which records the span of each
+// operation via given Tracer, so slow template and asset loads can be
+// attributed in distributed traces. Span of Open covers the open, not the
+// reads of opened file; use ReadFile to trace the full load.
+//
+// It is cheap to create, so wrap the application FileSystem per request
+// with the Tracer bound to request context. Traced implements
+// `vfs.FileSystem`.
+type Traced struct {
+	fs     FileSystem
+	tracer Tracer
+}
+
+// NewTraced method creates tracing wrapper for given fs, nil tracer records
+// nothing.
+func NewTraced(fs FileSystem, tracer Tracer) *Traced {
+	return &Traced{fs: fs, tracer: tracer}
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Traced's FileSystem interface
+//______________________________________________________________________________
+
+// Open method behaviour is same as `os.Open`.
+func (t *Traced) Open(name string) (File, error) {
+	span := t.start("open", name)
+	f, err := t.fs.Open(name)
+	span.End(err)
+	return f, err
+}
+
+// OpenFile method behaviour is same as `os.OpenFile`.
+func (t *Traced) OpenFile(name string, flag int, perm os.FileMode) (File, error) {
+	span := t.start("open", name)
+	f, err := t.fs.OpenFile(name, flag, perm)
+	span.End(err)
+	return f, err
+}
+
+// Lstat method behaviour is same as `os.Lstat`.
+func (t *Traced) Lstat(name string) (os.FileInfo, error) {
+	span := t.start("lstat", name)
+	fi, err := t.fs.Lstat(name)
+	if err == nil {
+		span.SetAttribute(TraceAttrSize, fi.Size())
+	}
+	span.End(err)
+	return fi, err
+}
+
+// Stat method behaviour is same as `os.Stat`.
+func (t *Traced) Stat(name string) (os.FileInfo, error) {
+	span := t.start("stat", name)
+	fi, err := t.fs.Stat(name)
+	if err == nil {
+		span.SetAttribute(TraceAttrSize, fi.Size())
+	}
+	span.End(err)
+	return fi, err
+}
+
+// Readlink method behaviour is same as `os.Readlink`.
+func (t *Traced) Readlink(name string) (string, error) {
+	span := t.start("readlink", name)
+	target, err := t.fs.Readlink(name)
+	span.End(err)
+	return target, err
+}
+
+// ReadFile method behaviour is same as `ioutil.ReadFile`.
+func (t *Traced) ReadFile(filename string) ([]byte, error) {
+	span := t.start("readfile", filename)
+	data, err := t.fs.ReadFile(filename)
+	if err == nil {
+		span.SetAttribute(TraceAttrSize, int64(len(data)))
+	}
+	span.End(err)
+	return data, err
+}
+
+// ReadDir method behaviour is same as `ioutil.ReadDir`.
+func (t *Traced) ReadDir(dirname string) ([]os.FileInfo, error) {
+	span := t.start("readdir", dirname)
+	list, err := t.fs.ReadDir(dirname)
+	if err == nil {
+		span.SetAttribute(TraceAttrEntries, len(list))
+	}
+	span.End(err)
+	return list, err
+}
+
+// Glob method behaviour is same as `filepath.Glob`.
+func (t *Traced) Glob(pattern string) ([]string, error) {
+	span := t.start("glob", pattern)
+	matches, err := t.fs.Glob(pattern)
+	if err == nil {
+		span.SetAttribute(TraceAttrMatches, len(matches))
+	}
+	span.End(err)
+	return matches, err
+}
+
+// IsExists method is helper to find existence.
+func (t *Traced) IsExists(name string) bool {
+	span := t.start("isexists", name)
+	exists := t.fs.IsExists(name)
+	span.SetAttribute(TraceAttrExists, exists)
+	span.End(nil)
+	return exists
+}
+
+// String method Stringer interface.
+func (t *Traced) String() string {
+	return fmt.Sprintf("traced(%v)", t.fs)
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Traced unexported types and methods
+//______________________________________________________________________________
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(key string, value interface{}) {}
+func (noopSpan) End(err error)                              {}
+
+func (t *Traced) start(op, name string) Span {
+	if t.tracer == nil {
+		return noopSpan{}
+	}
+	if span := t.tracer.StartSpan(op, name); span != nil {
+		return span
+	}
+	return noopSpan{}
+}
@@ -0,0 +1,81 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	"aahframework.org/test.v0/assert"
+)
+
+type testTracer struct {
+	spans []string
+}
+
+type testSpan struct {
+	t     *testTracer
+	name  string
+	attrs []string
+}
+
+func (t *testTracer) StartSpan(op, name string) Span {
+	return &testSpan{t: t, name: op + " " + name}
+}
+
+func (s *testSpan) SetAttribute(key string, value interface{}) {
+	s.attrs = append(s.attrs, fmt.Sprintf("%s=%v", key, value))
+}
+
+func (s *testSpan) End(err error) {
+	sort.Strings(s.attrs)
+	span := strings.Join(append([]string{s.name}, s.attrs...), " ")
+	if err != nil {
+		span += " err=" + err.Error()
+	}
+	s.t.spans = append(s.t.spans, span)
+}
+
+func TestTraced(t *testing.T) {
+	fs := createVFS(t)
+	tracer := &testTracer{}
+	tfs := NewTraced(fs, tracer)
+	assert.True(t, strings.HasPrefix(tfs.String(), "traced("))
+
+	f, err := tfs.Open("/app/static/robots.txt")
+	assert.Nil(t, err)
+	assert.Nil(t, f.Close())
+	_, err = tfs.OpenFile("/app/static/robots.txt", os.O_RDONLY, 0)
+	assert.Nil(t, err)
+	_, err = tfs.ReadFile("/app/static/robots.txt")
+	assert.Nil(t, err)
+	_, err = tfs.Stat("/app/static/css/aah.css")
+	assert.Nil(t, err)
+	_, err = tfs.Lstat("/app/static/not-exists.css")
+	assert.True(t, os.IsNotExist(err))
+	_, err = tfs.ReadDir("/app/static")
+	assert.Nil(t, err)
+	_, err = tfs.Glob("/app/static/*.txt")
+	assert.Nil(t, err)
+	assert.True(t, tfs.IsExists("/app/static/js/aah.js"))
+
+	assert.Equal(t, 8, len(tracer.spans))
+	assert.Equal(t, "open /app/static/robots.txt", tracer.spans[0])
+	assert.Equal(t, "open /app/static/robots.txt", tracer.spans[1])
+	assert.Equal(t, "readfile /app/static/robots.txt vfs.size=68", tracer.spans[2])
+	assert.Equal(t, "stat /app/static/css/aah.css vfs.size=700", tracer.spans[3])
+	assert.True(t, strings.HasPrefix(tracer.spans[4], "lstat /app/static/not-exists.css err="))
+	assert.Equal(t, "readdir /app/static vfs.entries=4", tracer.spans[5])
+	assert.Equal(t, "glob /app/static/*.txt vfs.matches=1", tracer.spans[6])
+	assert.Equal(t, "isexists /app/static/js/aah.js vfs.exists=true", tracer.spans[7])
+
+	// nil tracer records nothing
+	data, err := NewTraced(fs, nil).ReadFile("/app/static/robots.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, 68, len(data))
+}
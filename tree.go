@@ -0,0 +1,213 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// mountTree presents all the mounts of VFS as one tree, it is used by
+// `VFS.Walk`. Path is resolved by the mount of longest matching mount path,
+// same as `VFS.FindMount`. Ancestor directories of mount paths which are not
+// part of any mount are synthesized.
+type mountTree struct {
+	mounts []*Mount // sorted by mount path
+}
+
+func (v *VFS) newMountTree() *mountTree {
+	return &mountTree{mounts: v.sortedMounts()}
+}
+
+// vfsTree presents the mounts of VFS as one tree, mounts are taken on each
+// call so that later mount changes are visible.
+type vfsTree struct {
+	v *VFS
+}
+
+func (t vfsTree) Open(name string) (File, error) {
+	return t.v.newMountTree().Open(name)
+}
+
+func (t vfsTree) Stat(name string) (os.FileInfo, error) {
+	return t.v.newMountTree().Stat(name)
+}
+
+// Open method returns the directory with merged entries of owner mount and
+// child mounts, otherwise opens it from owner mount.
+func (t *mountTree) Open(name string) (File, error) {
+	children := t.childMounts(name)
+	owner := t.owner(name)
+	if len(children) == 0 {
+		if owner == nil {
+			return nil, t.notExists(name)
+		}
+		return owner.Open(name)
+	}
+
+	entries := make(map[string]os.FileInfo)
+	if owner != nil {
+		list, err := owner.ReadDir(name)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		for _, fi := range list {
+			entries[fi.Name()] = fi
+		}
+	}
+	for n, fi := range children {
+		entries[n] = fi
+	}
+
+	d := newNode(name, &NodeInfo{Dir: true, Path: name})
+	for _, fi := range entries {
+		d.childInfos = append(d.childInfos, fi)
+	}
+	sort.Sort(byName(d.childInfos))
+	return newFile(d), nil
+}
+
+// Lstat method returns the file info from owner mount, synthesized
+// directory info if name is an ancestor of mount path.
+func (t *mountTree) Lstat(name string) (os.FileInfo, error) {
+	return t.stat(name, false)
+}
+
+// Stat method is same as `mountTree.Lstat` but it follows the symbolic link.
+func (t *mountTree) Stat(name string) (os.FileInfo, error) {
+	return t.stat(name, true)
+}
+
+func (t *mountTree) stat(name string, follow bool) (os.FileInfo, error) {
+	var err error
+	if owner := t.owner(name); owner != nil {
+		var fi os.FileInfo
+		if fi, err = owner.stat(name, follow); err == nil {
+			return fi, nil
+		}
+	}
+
+	if len(t.childMounts(name)) > 0 {
+		return &NodeInfo{Dir: true, Path: name}, nil
+	}
+	if err == nil {
+		err = t.notExists(name)
+	}
+	return nil, err
+}
+
+// owner method returns the mount of longest mount path matching the name,
+// nil if none.
+func (t *mountTree) owner(name string) *Mount {
+	var owner *Mount
+	for _, m := range t.mounts {
+		if isPathWithin(name, m.Vroot) {
+			owner = m
+		}
+	}
+	return owner
+}
+
+// childMounts method returns the immediate entries of dirname contributed by
+// the mounts under it. Entry is the mount root if it is mounted directly
+// under dirname, otherwise synthesized directory.
+func (t *mountTree) childMounts(dirname string) map[string]os.FileInfo {
+	children := make(map[string]os.FileInfo)
+	for _, m := range t.mounts {
+		if m.Vroot == dirname || !isPathWithin(m.Vroot, dirname) {
+			continue
+		}
+
+		rest := strings.TrimPrefix(strings.TrimPrefix(m.Vroot, dirname), "/")
+		name := strings.SplitN(rest, "/", 2)[0]
+		ni := &NodeInfo{Dir: true, Path: path.Join(dirname, name)}
+		if name == rest {
+			// physical root info carries physical name, so its synthesized
+			if fi, err := m.Lstat(m.Vroot); err == nil {
+				ni.Time = fi.ModTime()
+			}
+			children[name] = ni
+		} else if _, found := children[name]; !found {
+			children[name] = ni
+		}
+	}
+	return children
+}
+
+func (t *mountTree) notExists(name string) error {
+	return &os.PathError{Op: "read", Path: name, Err: ErrMountNotExists}
+}
+
+// isPathWithin method returns true if name is the dir or its descendant.
+func isPathWithin(name, dir string) bool {
+	return name == dir || dir == "/" || strings.HasPrefix(name, dir+"/")
+}
+
+// isPhysicalWithin method returns true if physical name is the dir or its
+// descendant.
+func isPhysicalWithin(name, dir string) bool {
+	if name == dir {
+		return true
+	}
+	if !strings.HasSuffix(dir, string(filepath.Separator)) {
+		dir += string(filepath.Separator)
+	}
+	return strings.HasPrefix(name, dir)
+}
+
+// mountWalker presents the virtual tree and physical filesystem fallback of
+// mount as one tree, it is used by `Mount.Walk`.
+type mountWalker struct {
+	m *Mount
+}
+
+// Open method returns the virtual directory with physical entries merged,
+// otherwise opens it from mount.
+func (t mountWalker) Open(name string) (File, error) {
+	f, err := t.m.open(name)
+	if err != nil || f.Physical || f.Encrypted {
+		return t.m.Open(name)
+	}
+	if !f.IsDir() || !t.m.hasPhysical() {
+		return f, nil
+	}
+
+	pname, err := t.m.physicalPath("open", name)
+	if err != nil {
+		return f, nil
+	}
+	var pinfos []os.FileInfo
+	if t.m.lazy != nil {
+		pinfos, err = t.m.lazy.readDir(pname)
+	} else {
+		pinfos, err = ioutil.ReadDir(pname)
+	}
+	if err != nil || len(pinfos) == 0 {
+		return f, nil
+	}
+
+	infos := append([]os.FileInfo{}, f.childInfos...)
+	virtual := make(map[string]bool, len(infos))
+	for _, fi := range infos {
+		virtual[fi.Name()] = true
+	}
+	for _, fi := range pinfos {
+		if !virtual[fi.Name()] {
+			infos = append(infos, fi)
+		}
+	}
+	sort.Sort(byName(infos))
+	d := newNode(name, f)
+	d.childInfos = infos
+	return newFile(d), nil
+}
+
+func (t mountWalker) Lstat(name string) (os.FileInfo, error) {
+	return t.m.Lstat(name)
+}
@@ -6,12 +6,18 @@ package vfs
 
 import (
 	"bytes"
-	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
+	"os/user"
 	"path"
 	"path/filepath"
+	"runtime"
 	"sort"
+	"strings"
+	"time"
 )
 
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
@@ -29,6 +35,17 @@ func Open(fs *VFS, name string) (File, error) {
 	return fs.Open(name)
 }
 
+// OpenFile method calls `os.OpenFile` if fs == nil otherwise VFS.
+//
+// NOTE: Use VFS instance directly `aah.AppVFS().*`.  This is created to prevent
+// repetition code in consumimg libraries of aah.
+func OpenFile(fs *VFS, name string, flag int, perm os.FileMode) (File, error) {
+	if fs == nil {
+		return os.OpenFile(name, flag, perm)
+	}
+	return fs.OpenFile(name, flag, perm)
+}
+
 // Lstat method calls `os.Lstat` if fs == nil otherwise VFS.
 //
 // NOTE: Use VFS instance directly `aah.AppVFS().*`.  This is created to prevent
@@ -40,6 +57,17 @@ func Lstat(fs *VFS, name string) (os.FileInfo, error) {
 	return fs.Lstat(name)
 }
 
+// Readlink method calls `os.Readlink` if fs == nil otherwise VFS.
+//
+// NOTE: Use VFS instance directly `aah.AppVFS().*`.  This is created to prevent
+// repetition code in consumimg libraries of aah.
+func Readlink(fs *VFS, name string) (string, error) {
+	if fs == nil {
+		return os.Readlink(name)
+	}
+	return fs.Readlink(name)
+}
+
 // Stat method calls `os.Stat` if fs == nil otherwise VFS.
 //
 // NOTE: Use VFS instance directly `aah.AppVFS().*`.  This is created to prevent
@@ -73,6 +101,18 @@ func ReadDir(fs *VFS, dirname string) ([]os.FileInfo, error) {
 	return fs.ReadDir(dirname)
 }
 
+// ReadDirFunc method calls fn for each entry of physical directory if
+// fs == nil otherwise VFS, see `Mount.ReadDirFunc`.
+//
+// NOTE: Use VFS instance directly `aah.AppVFS().*`.  This is created to prevent
+// repetition code in consumimg libraries of aah.
+func ReadDirFunc(fs *VFS, dirname string, fn func(os.FileInfo) error) error {
+	if fs == nil {
+		return ignoreStop(readDirFunc(dirname, fn))
+	}
+	return fs.ReadDirFunc(dirname, fn)
+}
+
 // Glob method calls `filepath.Glob` if fs == nil otherwise VFS.
 //
 // NOTE: Use VFS instance directly `aah.AppVFS().*`.  This is created to prevent
@@ -98,56 +138,299 @@ func IsExists(fs *VFS, name string) bool {
 	return err == nil
 }
 
-// Walk method calls `filepath.Walk` if fs == nil otherwise VFS.
+// Walk method calls `filepath.Walk` if fs == nil otherwise walks the given
+// FileSystem, behaviour is same as `filepath.Walk`. It uses the Walk method of
+// `*vfs.VFS` and `*vfs.Mount`, i.e. mount walks its virtual tree and physical
+// fallback in single pass.
 //
 // NOTE: Use VFS instance directly `aah.AppVFS().*`.  This is created to prevent
 // repetition code in consumimg libraries of aah.
-func Walk(fs *VFS, root string, walkFn filepath.WalkFunc) error {
-	if fs == nil {
+func Walk(fs FileSystem, root string, walkFn filepath.WalkFunc) error {
+	if v, ok := fs.(*VFS); fs == nil || (ok && v == nil) {
 		return filepath.Walk(root, walkFn)
 	}
-	return fs.Walk(root, walkFn)
+	if w, ok := fs.(interface {
+		Walk(string, filepath.WalkFunc) error
+	}); ok {
+		return w.Walk(root, walkFn)
+	}
+
+	root, err := cleanPath("walk", root)
+	if err != nil {
+		return err
+	}
+	info, err := fs.Lstat(root)
+	if err == nil {
+		err = walk(fs, root, info, walkFn)
+	} else {
+		err = walkFn(root, nil, err)
+	}
+	if err == filepath.SkipDir {
+		return nil
+	}
+	return err
+}
+
+// WriteFileAtomic method writes data into temporary file on same directory
+// and then renames it to given name. So readers sees either old content or
+// new content, never the partial write.
+//
+// It operates on physical filesystem if fs == nil otherwise given
+// WritableFileSystem.
+func WriteFileAtomic(fs WritableFileSystem, name string, data []byte, perm os.FileMode) error {
+	if fs == nil {
+		return writeFileAtomicPhysical(name, data, perm)
+	}
+
+	tmpName := path.Join(path.Dir(name), fmt.Sprintf(".%s.%d.tmp", path.Base(name), time.Now().UnixNano()))
+	f, err := fs.OpenFile(tmpName, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+
+	w, ok := f.(io.Writer)
+	if !ok {
+		_ = f.Close()
+		_ = fs.Remove(tmpName)
+		return &os.PathError{Op: "write", Path: name, Err: ErrReadOnly}
+	}
+
+	if _, err = w.Write(data); err == nil {
+		err = f.Close()
+	} else {
+		_ = f.Close()
+	}
+	if err == nil {
+		err = fs.Rename(tmpName, name)
+	}
+	if err != nil {
+		_ = fs.Remove(tmpName)
+	}
+	return err
 }
 
 //‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
 // Package unexported methods
 //______________________________________________________________________________
 
+// nodeBlock allocates the node and its NodeInfo together, one heap object
+// per node instead of two.
+type nodeBlock struct {
+	n  node
+	ni NodeInfo
+}
+
+// newNode method creates the node of given path and file info, the children
+// map of directory is created on its first child, see `node.addChild`.
 func newNode(name string, fi os.FileInfo) *node {
-	return &node{
-		NodeInfo:   newNodeInfo(name, fi),
-		childInfos: make([]os.FileInfo, 0),
-		childs:     make(map[string]*node),
-	}
+	b := &nodeBlock{}
+	fillNodeInfo(&b.ni, name, fi)
+	b.n.NodeInfo = &b.ni
+	b.n.childInfos = make([]os.FileInfo, 0)
+	return &b.n
 }
 
 func newNodeInfo(name string, fi os.FileInfo) *NodeInfo {
-	return &NodeInfo{
-		Path:     name,
-		Dir:      fi.IsDir(),
-		DataSize: fi.Size(),
-		Time:     fi.ModTime(),
+	ni := &NodeInfo{}
+	fillNodeInfo(ni, name, fi)
+	return ni
+}
+
+func fillNodeInfo(ni *NodeInfo, name string, fi os.FileInfo) {
+	ni.Path = name
+	ni.Dir = fi.IsDir()
+	ni.DataSize = fi.Size()
+	ni.Time = fi.ModTime()
+	if c, ok := fi.(Checksummer); ok {
+		ni.SHA256, _ = c.Checksum()
+	}
+	if l, ok := fi.(symlinkInfo); ok {
+		ni.Symlink = l.linkTarget()
+	}
+	if p, ok := fi.(physicalInfo); ok {
+		ni.Physical = p.isPhysical()
+	}
+	if e, ok := fi.(encryptedInfo); ok {
+		ni.Encrypted = e.isEncrypted()
+	}
+	if g, ok := fi.(gzipInfo); ok {
+		ni.Gzip = g.isGzipData()
+	}
+	if r, ok := fi.(rawInfo); ok {
+		ni.Raw = r.isRawData()
+	}
+	if p, ok := fi.(permInfo); ok {
+		ni.Perm = p.permBits()
 	}
 }
 
 func newFile(n *node) *file {
-	f := &file{node: n}
+	f := &file{node: n, childInfos: n.childInfos, encoded: n.encoded}
 
 	if !f.IsDir() {
-		// transparent reading for caller regardless of data bytes.
-		f.rs = bytes.NewReader(f.node.data)
-		if f.IsGzip() {
-			r, _ := gzip.NewReader(f.rs)
-			f.rs = &gzipData{n: n, r: r}
+		if n.src != nil {
+			f.rs = &sourceData{n: n}
+		} else {
+			f.rs = dataReader(n)
 		}
 	}
 
 	return f
 }
 
+// dataReader method returns the reader of node data, transparent reading for
+// caller regardless of data bytes.
+func dataReader(n *node) io.ReadSeeker {
+	if n.IsGzip() {
+		return &gzipData{n: n}
+	}
+	return bytes.NewReader(n.data)
+}
+
+// readDirBatch is no. of entries read at a time from physical directory.
+const readDirBatch = 256
+
+// errStopIteration is used internally to stop `readDirFunc` batches.
+var errStopIteration = errors.New("vfs: stop iteration")
+
+func ignoreStop(err error) error {
+	if err == errStopIteration {
+		return nil
+	}
+	return err
+}
+
+// readAllSize method reads r until EOF into the buffer of expected size, so
+// the decompressed data is one allocation instead of the doubling copies of
+// `ioutil.ReadAll`. It still reads the data beyond the size.
+func readAllSize(r io.Reader, size int64) ([]byte, error) {
+	if size <= 0 || int64(int(size)) != size {
+		return ioutil.ReadAll(r)
+	}
+	buf := bytes.NewBuffer(make([]byte, 0, size+bytes.MinRead))
+	_, err := buf.ReadFrom(r)
+	return buf.Bytes(), err
+}
+
+// readDirFunc method reads the physical directory in batches and calls fn
+// for each entry.
+func readDirFunc(dirname string, fn func(os.FileInfo) error) error {
+	f, err := os.Open(dirname)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	for {
+		infos, err := f.Readdir(readDirBatch)
+		if ferr := eachFileInfo(infos, fn); ferr != nil || err == io.EOF {
+			return ferr
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// eachFileInfo method calls fn for each info, `filepath.SkipDir` returned
+// by fn stops the iteration without error.
+func eachFileInfo(infos []os.FileInfo, fn func(os.FileInfo) error) error {
+	for _, fi := range infos {
+		if err := fn(fi); err != nil {
+			if err == filepath.SkipDir {
+				return errStopIteration
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func writeFileAtomicPhysical(name string, data []byte, perm os.FileMode) error {
+	f, err := ioutil.TempFile(filepath.Dir(name), "."+filepath.Base(name)+".")
+	if err != nil {
+		return err
+	}
+	tmpName := f.Name()
+
+	if _, err = f.Write(data); err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmpName, perm)
+	}
+	if err == nil {
+		err = os.Rename(tmpName, name)
+	}
+	if err != nil {
+		_ = os.Remove(tmpName)
+	}
+	return err
+}
+
+// checkPhysicalDir method verifies the given physical path exists and it is
+// a directory.
+func checkPhysicalDir(pp string) error {
+	fi, err := os.Lstat(pp)
+	if err != nil {
+		return err
+	}
+
+	if !fi.IsDir() {
+		return &os.PathError{Op: "addmount", Path: pp, Err: errors.New("is a file")}
+	}
+	return nil
+}
+
+// ExpandPath method expands the leading `~` into user home directory and
+// environment variables `$VAR`, `${VAR}` in the given physical path, so the
+// config declared mounts work across machines and containers. Mount paths
+// are not expanded implicitly, for e.g.:
+//
+//	err := fs.AddMount("/app/views", vfs.ExpandPath("${APP_HOME}/views"))
+func ExpandPath(p string) string {
+	if p == "~" || strings.HasPrefix(p, "~/") || strings.HasPrefix(p, "~"+string(filepath.Separator)) {
+		if home := homeDir(); home != "" {
+			p = home + p[1:]
+		}
+	}
+	return os.ExpandEnv(p)
+}
+
+// homeDir method returns the current user home directory, empty string if
+// unable to determine.
+func homeDir() string {
+	env := "HOME"
+	if runtime.GOOS == "windows" {
+		env = "USERPROFILE"
+	}
+	if home := os.Getenv(env); home != "" {
+		return home
+	}
+	if u, err := user.Current(); err == nil {
+		return u.HomeDir
+	}
+	return ""
+}
+
+// isWriteFlag method returns true if given `os.OpenFile` flag requests
+// write access otherwise false.
+func isWriteFlag(flag int) bool {
+	return flag&(os.O_WRONLY|os.O_RDWR|os.O_APPEND|os.O_CREATE|os.O_TRUNC) != 0
+}
+
+// walkFileSystem is the subset of `vfs.FileSystem` used by walk.
+type walkFileSystem interface {
+	Open(name string) (File, error)
+	Lstat(name string) (os.FileInfo, error)
+}
+
 // readDirNames reads the directory named by dirname and returns
 // a sorted list of directory entries.
-func readDirNames(fs FileSystem, dirname string) ([]string, error) {
+func readDirNames(fs walkFileSystem, dirname string) ([]string, error) {
 	f, err := fs.Open(dirname)
 	if err != nil {
 		return nil, err
@@ -164,7 +447,7 @@ func readDirNames(fs FileSystem, dirname string) ([]string, error) {
 }
 
 // walk recursively descends path.
-func walk(fs FileSystem, fpath string, info os.FileInfo, walkFn filepath.WalkFunc) error {
+func walk(fs walkFileSystem, fpath string, info os.FileInfo, walkFn filepath.WalkFunc) error {
 	err := walkFn(fpath, info, nil)
 	if err != nil {
 		if info.IsDir() && err == filepath.SkipDir {
@@ -0,0 +1,254 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+)
+
+// Tree issue kinds reported by `Mount.Validate`.
+const (
+	IssueOrphan        = "orphan"
+	IssuePathMismatch  = "path-mismatch"
+	IssueDirWithData   = "dir-with-data"
+	IssueDuplicateName = "duplicate-name"
+
+	// Data issue kinds reported by `Mount.Verify`.
+	IssueLoadData         = "load-data"
+	IssueCorruptGzip      = "corrupt-gzip"
+	IssueSizeMismatch     = "size-mismatch"
+	IssueChecksumMismatch = "checksum-mismatch"
+)
+
+// TreeIssue struct represents the inconsistency found in the virtual tree of
+// a mount.
+type TreeIssue struct {
+	// Path is the virtual path of the node.
+	Path string
+
+	// Kind is the issue kind, for e.g.: `IssueOrphan`.
+	Kind string
+
+	// Detail describes the issue.
+	Detail string
+}
+
+// String method Stringer interface.
+func (i TreeIssue) String() string {
+	return fmt.Sprintf("issue(path=%s kind=%s detail=%s)", i.Path, i.Kind, i.Detail)
+}
+
+// Validate method checks the consistency of mount virtual tree and returns
+// the issues found, nil means the tree is consistent. It reports
+//
+//   - orphan nodes, i.e. child not listed in both lookup and listing of parent
+//   - child whose path does not match the parent path prefix
+//   - directory having file data
+//   - duplicate child names in the listing
+//
+// Issues are sorted by path. With build tag `vfsdebug` mounts are validated
+// automatically on attach and on add of each node, it panics on issues.
+func (m *Mount) Validate() []TreeIssue {
+	m.treeMu.RLock()
+	defer m.treeMu.RUnlock()
+	var issues []TreeIssue
+	if m.tree != nil {
+		issues = validateTree(m.tree, true)
+	}
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Path < issues[j].Path })
+	return issues
+}
+
+// VerifyReport struct is the result of `Mount.Verify`.
+type VerifyReport struct {
+	// Files is no. of file nodes verified.
+	Files int
+
+	// Bytes is the total decompressed size of verified files.
+	Bytes int64
+
+	// Issues is the data issues found, sorted by path.
+	Issues []TreeIssue
+}
+
+// OK method returns true if no issues found.
+func (r VerifyReport) OK() bool {
+	return len(r.Issues) == 0
+}
+
+// Verify method checks the integrity of file nodes data in the virtual tree
+// of mount, for e.g. in CI to catch the corrupted single binary generation.
+// It reports
+//
+//   - node data which fails to load from its source
+//   - gzip data which fails to decompress
+//   - `NodeInfo.DataSize` not matching the decompressed length
+//   - SHA-256 checksum not matching the decompressed content, if node has it
+//
+// Physical filesystem is not verified, see `vfs.Diff`.
+func (m *Mount) Verify() VerifyReport {
+	var nodes []*node
+	m.treeMu.RLock()
+	if m.tree != nil {
+		nodes = fileNodes(m.tree, nodes)
+	}
+	m.treeMu.RUnlock()
+
+	var r VerifyReport
+	for _, n := range nodes {
+		size, issue := m.verifyNode(n)
+		if issue != nil {
+			r.Issues = append(r.Issues, *issue)
+		}
+		r.Files++
+		r.Bytes += size
+	}
+	sort.SliceStable(r.Issues, func(i, j int) bool { return r.Issues[i].Path < r.Issues[j].Path })
+	return r
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Validate unexported methods
+//______________________________________________________________________________
+
+// validateTree method validates the node and its descendants if recursive.
+func validateTree(n *node, recursive bool) []TreeIssue {
+	var issues []TreeIssue
+	add := func(p, kind, format string, a ...interface{}) {
+		issues = append(issues, TreeIssue{Path: p, Kind: kind, Detail: fmt.Sprintf(format, a...)})
+	}
+
+	if n.IsDir() && len(n.data) > 0 {
+		add(n.Path, IssueDirWithData, "directory has %d bytes of data", len(n.data))
+	}
+
+	listed := make(map[string]bool, len(n.childInfos))
+	for _, ci := range n.childInfos {
+		c, ok := ci.(*node)
+		if !ok {
+			add(path.Join(n.Path, ci.Name()), IssueOrphan, "listed child is not a node")
+			continue
+		}
+
+		name := c.Name()
+		if listed[name] {
+			add(c.Path, IssueDuplicateName, "name %q is listed more than once in %s", name, n.Path)
+			continue
+		}
+		listed[name] = true
+
+		if n.childs[name] != c {
+			add(c.Path, IssueOrphan, "listed child is not in lookup of %s", n.Path)
+		}
+	}
+
+	keys := make([]string, 0, len(n.childs))
+	for k := range n.childs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		c := n.childs[k]
+		if !listed[k] {
+			add(path.Join(n.Path, k), IssueOrphan, "child is not listed in %s", n.Path)
+		}
+		if expected := path.Join(n.Path, k); c.Path != expected {
+			add(c.Path, IssuePathMismatch, "expected path %s", expected)
+		}
+		if recursive {
+			issues = append(issues, validateTree(c, true)...)
+		}
+	}
+
+	return issues
+}
+
+// fileNodes method appends the file nodes of given node and its descendants,
+// symbolic links and physical files are skipped. Caller holds the tree lock.
+func fileNodes(n *node, nodes []*node) []*node {
+	if !n.IsDir() {
+		if n.Symlink == "" && !n.Physical {
+			nodes = append(nodes, n)
+		}
+		return nodes
+	}
+	for _, c := range n.childs {
+		nodes = fileNodes(c, nodes)
+	}
+	return nodes
+}
+
+// verifyNode method returns the decompressed size of node data and the first
+// issue found, nil if none.
+func (m *Mount) verifyNode(n *node) (int64, *TreeIssue) {
+	sum, size, issue := m.digestNode(n)
+	if issue != nil {
+		return size, issue
+	}
+	if size != n.DataSize {
+		return size, &TreeIssue{Path: n.Path, Kind: IssueSizeMismatch,
+			Detail: fmt.Sprintf("data size is %d, expected %d", size, n.DataSize)}
+	}
+	if n.SHA256 != "" && sum != n.SHA256 {
+		return size, &TreeIssue{Path: n.Path, Kind: IssueChecksumMismatch,
+			Detail: fmt.Sprintf("checksum is %s, expected %s", sum, n.SHA256)}
+	}
+	return size, nil
+}
+
+// digestNode method returns the hex SHA-256 checksum and size of node data
+// computed after decryption and decompression.
+func (m *Mount) digestNode(n *node) (string, int64, *TreeIssue) {
+	issue := func(kind, format string, a ...interface{}) *TreeIssue {
+		return &TreeIssue{Path: n.Path, Kind: kind, Detail: fmt.Sprintf(format, a...)}
+	}
+
+	if err := n.loadData(); err != nil {
+		return "", 0, issue(IssueLoadData, "%v", err)
+	}
+
+	data := n.data
+	if n.Encrypted {
+		var err error
+		if data, err = m.decrypt(n); err != nil {
+			return "", 0, issue(IssueLoadData, "%v", err)
+		}
+	}
+
+	var r io.Reader = bytes.NewReader(data)
+	if isGzipped(n.NodeInfo, data) {
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			return "", 0, issue(IssueCorruptGzip, "%v", err)
+		}
+		r = gr
+	}
+
+	h := sha256.New()
+	size, err := io.Copy(h, r)
+	if err != nil {
+		return "", size, issue(IssueCorruptGzip, "%v", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
+
+// debugValidate method panics if the node has issues, it is no-op unless
+// build tag `vfsdebug` is used.
+func debugValidate(m *Mount, n *node, recursive bool) {
+	if !debugMode || n == nil {
+		return
+	}
+	if issues := validateTree(n, recursive); len(issues) > 0 {
+		panic(&os.PathError{Op: "validate", Path: m.Vroot, Err: fmt.Errorf("vfs: inconsistent tree %v", issues)})
+	}
+}
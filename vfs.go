@@ -5,10 +5,18 @@
 // Package vfs provides Virtual FileSystem (VFS) capability. Typically it reflects
 // OS FileSystem behavior in-memory.
 //
-// aah vfs is Read-Only, even though vfs design nature could support Write
-// operations. I have limited it.
+// aah vfs is Read-Only, the methods should behave the same as those on an
+// *os.File for Read-Only.
 //
-// The methods should behave the same as those on an *os.File for Read-Only.
+// Write operations are opt-in via `vfs.MemFS`, it implements
+// `vfs.WritableFileSystem` on the same node tree. So tests and tools can build
+// virtual tree programmatically without generating Go code.
+//
+// Listing operations ReadDir, Glob, Walk, Dirs and Files returns results in
+// lexicographical order of names, regardless of insertion order of nodes or
+// whether the results comes from virtual tree or physical filesystem. So the
+// behavior built on top of it is reproducible. File.Readdir on virtual node
+// follows the same order; on physical file it is same as `os.File.Readdir`.
 package vfs
 
 import (
@@ -21,8 +29,10 @@ import (
 // regardless of host operating system convention.
 type FileSystem interface {
 	Open(name string) (File, error)
+	OpenFile(name string, flag int, perm os.FileMode) (File, error)
 	Lstat(name string) (os.FileInfo, error)
 	Stat(name string) (os.FileInfo, error)
+	Readlink(name string) (string, error)
 	ReadFile(filename string) ([]byte, error)
 	ReadDir(dirname string) ([]os.FileInfo, error)
 	Glob(pattern string) ([]string, error)
@@ -30,6 +40,13 @@ type FileSystem interface {
 }
 
 // File interface returned by a vfs.FileSystem's Open method.
+//
+// Every Open returns a File with its own read offset, Read and Seek on one
+// File does not affect the other Files of the same name.
+//
+// Files of VFS, Mount and MemFS also implements `io.ReaderAt` for random
+// access, for e.g.: `http.ServeContent` range requests or `zip.NewReader`.
+// Use type assertion, since File of other FileSystem may not support it.
 type File interface {
 	http.File
 	Readdirnames(n int) ([]string, error)
@@ -48,3 +65,32 @@ type Gziper interface {
 	RawBytes
 	IsGzip() bool
 }
+
+// Checksummer interface is to retrieve the SHA-256 checksum of file content
+// known ahead, for e.g. `vfs.Binary` generated code, so that HTTP handlers
+// can produce strong ETag and integrity check without hashing at runtime.
+// Checksum is lowercase hex, false if its not known.
+type Checksummer interface {
+	Checksum() (string, bool)
+}
+
+// Content encodings of pre-compressed file data, see `vfs.Compressed`.
+const (
+	EncodingGzip   = "gzip"
+	EncodingBrotli = "br"
+	EncodingZstd   = "zstd"
+)
+
+// Compressed interface is to retrieve the pre-compressed bytes of the file
+// per HTTP content encoding, so that web handlers can serve them as-is to
+// the clients accepting the encoding, see `vfs.CompressedFileServer`.
+//
+// Encoding method returns the encoding of raw bytes, empty if its not
+// compressed. EncodedBytes method returns the bytes of given encoding, raw
+// bytes in case of its encoding, otherwise the pre-compressed variant added
+// via `Mount.AddEncoded`.
+type Compressed interface {
+	RawBytes
+	Encoding() string
+	EncodedBytes(encoding string) ([]byte, bool)
+}
@@ -7,13 +7,21 @@ package vfs
 import (
 	"bytes"
 	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
+	"path"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
 	"testing"
+	"testing/iotest"
+	"time"
 
 	"aahframework.org/essentials.v0"
 	"aahframework.org/test.v0/assert"
@@ -191,6 +199,60 @@ func TestVFSReadDir(t *testing.T) {
 	assert.True(t, strings.HasPrefix(fmt.Sprintf("%s", infos[3]), "node(name=security.conf dir=false gzip=true size=9352, modtime="))
 }
 
+func TestVFSReadDirFunc(t *testing.T) {
+	fs := createVFS(t)
+
+	var names []string
+	err := fs.ReadDirFunc("/app/config", func(fi os.FileInfo) error {
+		names = append(names, fi.Name())
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"aah.conf", "env", "routes.conf", "security.conf"}, names)
+
+	names = nil
+	err = fs.ReadDirFunc("/app/config", func(fi os.FileInfo) error {
+		names = append(names, fi.Name())
+		if fi.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"aah.conf", "env"}, names)
+
+	err = fs.ReadDirFunc("/app/config/routes.conf", func(os.FileInfo) error { return nil })
+	assert.NotNil(t, err)
+
+	// physical directory is read in batches
+	dir, err := ioutil.TempDir("", "vfs-readdirfunc")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	for i := 0; i < readDirBatch+10; i++ {
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, fmt.Sprintf("f%03d.txt", i)), nil, 0644))
+	}
+	assert.Nil(t, fs.AddMount("/many", dir))
+
+	cnt := 0
+	err = fs.ReadDirFunc("/many", func(os.FileInfo) error {
+		cnt++
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, readDirBatch+10, cnt)
+
+	cnt = 0
+	errStop := errors.New("stop")
+	err = ReadDirFunc(nil, dir, func(os.FileInfo) error {
+		if cnt++; cnt == readDirBatch+1 {
+			return errStop
+		}
+		return nil
+	})
+	assert.Equal(t, errStop, err)
+	assert.Equal(t, readDirBatch+1, cnt)
+}
+
 func TestVFSGlobAndIsExists(t *testing.T) {
 	fs := createVFS(t)
 
@@ -230,6 +292,2138 @@ func TestVFSDirsAndFiles(t *testing.T) {
 	}
 }
 
+func TestVFSPhysicalSymlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlink creation requires privilege on windows")
+	}
+
+	dir, err := ioutil.TempDir("", "vfs-symlink")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "assets"), 0755))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "assets", "app.css"), []byte("body { margin: 0; }"), 0644))
+	assert.Nil(t, os.Symlink(filepath.Join(dir, "assets", "app.css"), filepath.Join(dir, "link.css")))
+	assert.Nil(t, os.Symlink(filepath.Join(dir, "assets"), filepath.Join(dir, "static")))
+	assert.Nil(t, os.Symlink(filepath.Join(dir, "not-exists.css"), filepath.Join(dir, "dangling.css")))
+
+	fs := new(VFS)
+	assert.Nil(t, fs.AddMount("/app", dir))
+
+	// file link
+	lfi, err := fs.Lstat("/app/link.css")
+	assert.Nil(t, err)
+	assert.True(t, lfi.Mode()&os.ModeSymlink != 0)
+
+	sfi, err := fs.Stat("/app/link.css")
+	assert.Nil(t, err)
+	assert.True(t, sfi.Mode()&os.ModeSymlink == 0)
+	assert.Equal(t, int64(19), sfi.Size())
+
+	data, err := fs.ReadFile("/app/link.css")
+	assert.Nil(t, err)
+	assert.Equal(t, "body { margin: 0; }", string(data))
+
+	// directory link
+	lfi, err = fs.Lstat("/app/static")
+	assert.Nil(t, err)
+	assert.False(t, lfi.IsDir())
+
+	sfi, err = fs.Stat("/app/static")
+	assert.Nil(t, err)
+	assert.True(t, sfi.IsDir())
+
+	// dangling link
+	assert.True(t, fs.IsExists("/app/dangling.css"))
+	_, err = fs.Stat("/app/dangling.css")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestVFSOpenFile(t *testing.T) {
+	fs := createVFS(t)
+
+	f, err := OpenFile(fs, "/app/static/robots.txt", os.O_RDONLY, 0)
+	assert.Nil(t, err)
+	data, err := ioutil.ReadAll(f)
+	assert.Nil(t, err)
+	assert.True(t, strings.Contains(string(data), "User-agent: *"))
+	assert.Nil(t, f.Close())
+
+	for _, flag := range []int{
+		os.O_WRONLY,
+		os.O_RDWR,
+		os.O_RDONLY | os.O_APPEND,
+		os.O_WRONLY | os.O_CREATE | os.O_TRUNC,
+	} {
+		f, err = fs.OpenFile("/app/static/robots.txt", flag, 0644)
+		assert.Nil(t, f)
+		assert.Equal(t, &os.PathError{Op: "open", Path: "/app/static/robots.txt", Err: ErrReadOnly}, err)
+	}
+}
+
+func TestVFSShadowCompare(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vfs-shadow")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "same.txt"), []byte("same"), 0644))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "changed.txt"), []byte("physical"), 0644))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "resized.txt"), []byte("physical"), 0644))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "physical-only.txt"), []byte("physical"), 0644))
+
+	fs := new(VFS)
+	assert.Nil(t, fs.AddMount("/app", dir))
+	m, err := fs.FindMount("/app")
+	assert.Nil(t, err)
+	for name, data := range map[string]string{
+		"same.txt":         "same",
+		"changed.txt":      "embedded",
+		"resized.txt":      "embedded-resized",
+		"virtual-only.txt": "virtual",
+	} {
+		assert.Nil(t, m.AddFile(&NodeInfo{Path: "/app/" + name, DataSize: int64(len(data))}, []byte(data)))
+	}
+
+	var divergences []Divergence
+	s := NewShadow(m, 1, func(d Divergence) { divergences = append(divergences, d) })
+
+	for _, name := range []string{"same.txt", "changed.txt", "resized.txt", "virtual-only.txt", "physical-only.txt"} {
+		_, err = s.ReadFile("/app/" + name)
+		assert.Nil(t, err)
+	}
+	_, err = s.ReadDir("/app")
+	assert.Nil(t, err)
+
+	assert.Equal(t, 4, len(divergences))
+	assert.Equal(t, Divergence{Op: "readfile", Path: "/app/changed.txt", Kind: DivergenceContent}, divergences[0])
+	assert.Equal(t, Divergence{Op: "readfile", Path: "/app/resized.txt", Kind: DivergenceSize}, divergences[1])
+	assert.Equal(t, DivergenceMissing, divergences[2].Kind)
+	assert.Equal(t, "/app/virtual-only.txt", divergences[2].Path)
+	assert.Equal(t, Divergence{Op: "readdir", Path: "/app", Kind: DivergenceEntries}, divergences[3])
+
+	// sampling
+	divergences = nil
+	s = NewShadow(m, 3, func(d Divergence) { divergences = append(divergences, d) })
+	for i := 0; i < 6; i++ {
+		_, err = s.ReadFile("/app/changed.txt")
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, 2, len(divergences))
+
+	// virtual symlink is compared with physical file of its target
+	divergences = nil
+	s = NewShadow(m, 1, func(d Divergence) { divergences = append(divergences, d) })
+	assert.Nil(t, m.Symlink("/app/same.txt", "/app/alias.txt"))
+	data, err := s.ReadFile("/app/alias.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, "same", string(data))
+	assert.Equal(t, 0, len(divergences))
+}
+
+func TestVFSDeterministicOrder(t *testing.T) {
+	fs := new(VFS)
+	fs.SetEmbeddedMode()
+	assert.Nil(t, fs.AddMount("/app", filepath.Join(testdataBaseDir(), "not-exists")))
+	m, err := fs.FindMount("/app")
+	assert.Nil(t, err)
+
+	names := []string{"z.html", "b.html", "m.html", "a.html", "k.html"}
+	assert.Nil(t, m.AddDir(&NodeInfo{Dir: true, Path: "/app/views"}))
+	for _, name := range names {
+		assert.Nil(t, m.AddFile(&NodeInfo{Path: "/app/views/" + name, DataSize: 1}, []byte("x")))
+	}
+	// re-adding a node replaces it instead of duplicating
+	assert.Nil(t, m.AddFile(&NodeInfo{Path: "/app/views/m.html", DataSize: 2}, []byte("xx")))
+
+	expected := []string{"a.html", "b.html", "k.html", "m.html", "z.html"}
+
+	infos, err := fs.ReadDir("/app/views")
+	assert.Nil(t, err)
+	var got []string
+	for _, fi := range infos {
+		got = append(got, fi.Name())
+	}
+	assert.Equal(t, expected, got)
+	assert.Equal(t, int64(2), infos[3].Size())
+
+	f, err := fs.Open("/app/views")
+	assert.Nil(t, err)
+	got, err = f.Readdirnames(-1)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, got)
+
+	matches, err := fs.Glob("/app/views/*.html")
+	assert.Nil(t, err)
+	got = nil
+	for _, p := range matches {
+		got = append(got, filepath.Base(p))
+	}
+	assert.Equal(t, expected, got)
+
+	files, err := fs.Files("/app")
+	assert.Nil(t, err)
+	got = nil
+	for _, p := range files {
+		got = append(got, filepath.Base(p))
+	}
+	assert.Equal(t, expected, got)
+
+	mfs := NewMemFS()
+	for _, name := range names {
+		assert.Nil(t, mfs.Mkdir(name, 0755))
+	}
+	matches, err = mfs.Glob("/*")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/a.html", "/b.html", "/k.html", "/m.html", "/z.html"}, matches)
+}
+
+func TestVFSGlobWithOptions(t *testing.T) {
+	fs := new(VFS)
+	fs.SetEmbeddedMode()
+	assert.Nil(t, fs.AddMount("/app", filepath.Join(testdataBaseDir(), "not-exists")))
+	m, err := fs.FindMount("/app")
+	assert.Nil(t, err)
+
+	now := time.Now()
+	assert.Nil(t, m.AddDir(&NodeInfo{Dir: true, Path: "/app/migrations"}))
+	for name, age := range map[string]time.Duration{
+		"001_init.sql":  3 * time.Hour,
+		"002_users.sql": time.Minute,
+		"003_posts.sql": 2 * time.Hour,
+		"readme.md":     0,
+	} {
+		assert.Nil(t, m.AddFile(&NodeInfo{Path: "/app/migrations/" + name, Time: now.Add(-age)}, []byte("--")))
+	}
+
+	matches, err := GlobWithOptions(fs, "/app/migrations/*.sql", GlobOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/app/migrations/001_init.sql", "/app/migrations/002_users.sql", "/app/migrations/003_posts.sql"}, matches)
+
+	matches, err = GlobWithOptions(fs, "/app/migrations/*.sql", GlobOptions{SortBy: SortByModTime, Reverse: true, Limit: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/app/migrations/002_users.sql"}, matches)
+
+	matches, err = GlobWithOptions(fs, "/app/migrations/*.sql", GlobOptions{SortBy: SortByModTime, Limit: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/app/migrations/001_init.sql", "/app/migrations/003_posts.sql"}, matches)
+
+	matches, err = GlobWithOptions(nil, filepath.Join(testdataBaseDir(), "vfstest", "config", "*.conf"), GlobOptions{Reverse: true, Limit: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(matches))
+	assert.Equal(t, "security.conf", filepath.Base(matches[0]))
+
+	_, err = GlobWithOptions(fs, "/app/migrations/[", GlobOptions{})
+	assert.NotNil(t, err)
+}
+
+func TestVFSOpenFilesAccounting(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vfs-openfiles")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0644))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "b.txt"), []byte("b"), 0644))
+
+	fs := new(VFS)
+	assert.Nil(t, fs.AddMount("/app", dir))
+	m, err := fs.FindMount("/app")
+	assert.Nil(t, err)
+	assert.Nil(t, m.AddFile(&NodeInfo{Path: "/app/v.txt", DataSize: 1}, []byte("v")))
+
+	vf, err := fs.Open("/app/v.txt")
+	assert.Nil(t, err)
+	pf, err := fs.Open("/app/a.txt")
+	assert.Nil(t, err)
+	virtual, physical := m.OpenFiles()
+	assert.Equal(t, 1, virtual)
+	assert.Equal(t, 1, physical)
+
+	m.SetMaxOpenFiles(1)
+	f, err := fs.Open("/app/b.txt")
+	assert.Nil(t, f)
+	assert.Equal(t, &os.PathError{Op: "open", Path: "/app/b.txt", Err: ErrTooManyOpenFiles}, err)
+
+	assert.Nil(t, vf.Close())
+	assert.Nil(t, pf.Close())
+	assert.NotNil(t, pf.Close())
+	virtual, physical = m.OpenFiles()
+	assert.Equal(t, 0, virtual)
+	assert.Equal(t, 0, physical)
+
+	// ReadFile releases the file
+	_, err = fs.ReadFile("/app/b.txt")
+	assert.Nil(t, err)
+	_, err = fs.ReadFile("/app/v.txt")
+	assert.Nil(t, err)
+	virtual, physical = m.OpenFiles()
+	assert.Equal(t, 0, virtual)
+	assert.Equal(t, 0, physical)
+}
+
+func TestVFSClose(t *testing.T) {
+	fs := createVFS(t)
+	assert.Nil(t, fs.AddMount("/config", filepath.Join(testdataBaseDir(), "vfstest", "config")))
+
+	var closed []string
+	app, _ := fs.FindMount("/app")
+	app.AddCloser(testCloser(func() error { closed = append(closed, "app-1"); return nil }))
+	app.AddCloser(testCloser(func() error { closed = append(closed, "app-2"); return errors.New("app-2 failed") }))
+	config, _ := fs.FindMount("/config")
+	config.AddCloser(testCloser(func() error { closed = append(closed, "config"); return errors.New("config failed") }))
+
+	err := fs.Close()
+	assert.Equal(t, "app-2 failed", err.Error())
+	assert.Equal(t, []string{"app-2", "app-1", "config"}, closed)
+
+	// already closed
+	assert.Nil(t, fs.Close())
+	assert.Equal(t, 3, len(closed))
+}
+
+type testCloser func() error
+
+func (c testCloser) Close() error { return c() }
+
+func TestVFSNewMount(t *testing.T) {
+	_, err := NewMount("/app", "relative/path")
+	assert.Equal(t, ErrNotAbsolutPath, err)
+
+	// purely virtual mount, standalone
+	m, err := NewMount("app", "", Strict(), CaseFold())
+	assert.Nil(t, err)
+	assert.Equal(t, "/app", m.Name())
+	assert.Nil(t, m.AddDir(&NodeInfo{Dir: true, Path: "/app/Views"}))
+	assert.Nil(t, m.AddFile(&NodeInfo{Path: "/app/Views/Index.html", DataSize: 5}, []byte("hello")))
+
+	err = m.AddFile(&NodeInfo{Path: "/app/layouts/master.html", DataSize: 5}, []byte("hello"))
+	assert.Equal(t, "addnode /app/layouts/master.html: parent directory does not exist", err.Error())
+	err = m.AddFile(&NodeInfo{Path: "/app/Views/Index.html/x.html", DataSize: 5}, []byte("hello"))
+	assert.NotNil(t, err)
+
+	data, err := m.ReadFile("/app/views/index.HTML")
+	assert.Nil(t, err)
+	assert.Equal(t, "hello", string(data))
+
+	_, err = m.Stat("/app/views/not-exists.html")
+	assert.True(t, os.IsNotExist(err))
+	_, err = m.Open("/app/views/not-exists.html")
+	assert.True(t, os.IsNotExist(err))
+	_, err = m.ReadDir("/app/layouts")
+	assert.True(t, os.IsNotExist(err))
+	matches, err := m.Glob("/app/layouts/*.html")
+	assert.Nil(t, err)
+	assert.Nil(t, matches)
+	assert.False(t, m.match(filepath.Join(testdataBaseDir(), "vfstest")))
+
+	// read only, populated then sealed
+	m, err = NewMount("/app", filepath.Join(testdataBaseDir(), "vfstest"), ReadOnly())
+	assert.Nil(t, err)
+	assert.Nil(t, m.AddDir(&NodeInfo{Dir: true, Path: "/app/views"}))
+	assert.Nil(t, m.AddFile(&NodeInfo{Path: "/app/views/index.html", DataSize: 5}, []byte("hello")))
+	m.Seal()
+	err = m.AddDir(&NodeInfo{Dir: true, Path: "/app/config"})
+	assert.Equal(t, &os.PathError{Op: "addnode", Path: "/app/config", Err: ErrReadOnly}, err)
+	err = m.AddFileAll(&NodeInfo{Path: "/app/public/css/app.css", DataSize: 1}, []byte("a"))
+	assert.Equal(t, ErrReadOnly, err.(*os.PathError).Err)
+	assert.False(t, m.IsExists("/app/public"))
+	assert.True(t, m.IsExists("/app/views/errors/404.html"))
+	data, err = m.ReadFile("/app/views/index.html")
+	assert.Nil(t, err)
+	assert.Equal(t, "hello", string(data))
+}
+
+func TestVFSMountPathExpansion(t *testing.T) {
+	home := os.Getenv("HOME")
+	defer func() {
+		_ = os.Setenv("HOME", home)
+		_ = os.Unsetenv("VFS_TEST_BASE")
+	}()
+	assert.Nil(t, os.Setenv("HOME", testdataBaseDir()))
+	assert.Nil(t, os.Setenv("VFS_TEST_BASE", testdataBaseDir()))
+
+	fs := VFS{}
+	assert.Nil(t, fs.AddMount("/app", ExpandPath("${VFS_TEST_BASE}/vfstest")))
+	assert.Nil(t, fs.AddMount("/home", ExpandPath("~/vfstest")))
+	assert.True(t, fs.IsExists("/app/views/errors/404.html"))
+	assert.True(t, fs.IsExists("/home/views/errors/404.html"))
+
+	m, err := NewMount("/app", ExpandPath("$VFS_TEST_BASE/vfstest"))
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(testdataBaseDir(), "vfstest"), m.Proot)
+
+	_, err = NewMount("/app", ExpandPath("${VFS_TEST_UNSET}vfstest"))
+	assert.Equal(t, ErrNotAbsolutPath, err)
+
+	// not expanded implicitly
+	m, err = NewMount("/app", "/srv/$VFS_TEST_BASE")
+	assert.Nil(t, err)
+	assert.Equal(t, "/srv/$VFS_TEST_BASE", filepath.ToSlash(m.Proot))
+	dir, err := ioutil.TempDir("", "vfs-$app")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	assert.Nil(t, fs.AddMount("/dollar", dir))
+	m, err = fs.FindMount("/dollar")
+	assert.Nil(t, err)
+	assert.Equal(t, dir, m.Proot)
+}
+
+func TestVFSBaseDir(t *testing.T) {
+	fs := VFS{}
+	assert.Equal(t, ErrNotAbsolutPath, fs.AddMount("/app", "vfstest"))
+	assert.Equal(t, ErrNotAbsolutPath, fs.SetBaseDir("testdata"))
+	assert.Equal(t, "", fs.BaseDir())
+
+	assert.Nil(t, fs.SetBaseDir(testdataBaseDir()))
+	assert.Equal(t, testdataBaseDir(), fs.BaseDir())
+	assert.Nil(t, fs.AddMount("/app", "vfstest"))
+	assert.True(t, fs.IsExists("/app/views/errors/404.html"))
+
+	m, err := fs.FindMount("/app")
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(testdataBaseDir(), "vfstest"), m.Proot)
+
+	events, err := fs.SyncMounts(map[string]string{"/config": "vfstest/config"})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(events))
+	assert.Equal(t, MountAdded, events[0].Type)
+	assert.True(t, fs.IsExists("/config/env/dev.conf"))
+}
+
+func TestVFSGlobAcrossMounts(t *testing.T) {
+	fs := createVFS(t)
+	assert.Nil(t, fs.AddMount("/static", filepath.Join(testdataBaseDir(), "vfstest", "static")))
+
+	matches, err := fs.Glob("/*")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/app", "/static"}, matches)
+
+	matches, err = fs.Glob("/*/config/env/*.conf")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/app/config/env/dev.conf", "/app/config/env/prod.conf"}, matches)
+
+	matches, err = fs.Glob("/*/*/robots.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/app/static/robots.txt"}, matches)
+
+	matches, err = fs.Glob("/*/robots.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/static/robots.txt"}, matches)
+
+	matches, err = fs.Glob("/not-exists/*")
+	assert.Nil(t, err)
+	assert.Nil(t, matches)
+
+	// physical path pattern
+	matches, err = fs.Glob(filepath.Join(testdataBaseDir(), "vfstest", "static", "css", "*.css"))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/static/css/aah.css"}, matches)
+
+	_, err = fs.Glob("/app/[")
+	assert.Equal(t, path.ErrBadPattern, err)
+}
+
+func TestVFSWalkAcrossMounts(t *testing.T) {
+	fs := VFS{}
+	assert.Nil(t, fs.AddMount("/app", filepath.Join(testdataBaseDir(), "vfstest", "config")))
+	assert.Nil(t, fs.AddMount("/app/env", filepath.Join(testdataBaseDir(), "vfstest", "static")))
+	assert.Nil(t, fs.AddMount("/assets/static/css", filepath.Join(testdataBaseDir(), "vfstest", "static", "css")))
+
+	var got []string
+	err := fs.Walk("/", func(fpath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			fpath += "/"
+		}
+		got = append(got, fpath)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"//",
+		"/app/",
+		"/app/aah.conf",
+		"/app/env/",
+		"/app/env/css/",
+		"/app/env/css/aah.css",
+		"/app/env/img/",
+		"/app/env/img/aah-framework-logo.png",
+		"/app/env/img/favicon.ico",
+		"/app/env/js/",
+		"/app/env/js/aah.js",
+		"/app/env/robots.txt",
+		"/app/routes.conf",
+		"/app/security.conf",
+		"/assets/",
+		"/assets/static/",
+		"/assets/static/css/",
+		"/assets/static/css/aah.css",
+	}, got)
+
+	// physical root is walked as its virtual path
+	got = nil
+	err = fs.Walk(filepath.Join(testdataBaseDir(), "vfstest", "static", "img"), func(fpath string, info os.FileInfo, err error) error {
+		got = append(got, fpath)
+		return err
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/app/env/img", "/app/env/img/aah-framework-logo.png", "/app/env/img/favicon.ico"}, got)
+
+	dirs, err := fs.Dirs("/assets")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/assets", "/assets/static", "/assets/static/css"}, dirs)
+
+	m, err := fs.FindMount("/app/env/robots.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, "/app/env", m.Vroot)
+
+	_, err = fs.Files("/not-exists")
+	assert.Equal(t, &os.PathError{Op: "read", Path: "/not-exists", Err: ErrMountNotExists}, err)
+}
+
+func TestVFSConflicts(t *testing.T) {
+	fs := createVFS(t)
+	assert.Nil(t, fs.AddMount("/app/static", filepath.Join(testdataBaseDir(), "vfstest", "config")))
+	assert.Nil(t, fs.AddMount("/app/static/env", filepath.Join(testdataBaseDir(), "vfstest", "config", "env")))
+	assert.Nil(t, fs.AddMount("/other", filepath.Join(testdataBaseDir(), "vfstest", "config")))
+
+	conflicts, err := fs.Conflicts()
+	assert.Nil(t, err)
+
+	var got []string
+	for _, c := range conflicts {
+		got = append(got, c.String())
+	}
+	assert.Equal(t, []string{
+		"conflict(path=/app/static winner=/app/static:physical shadowed=/app:virtual)",
+		"conflict(path=/app/static/css hidden-by=/app/static shadowed=/app:virtual)",
+		"conflict(path=/app/static/css/aah.css hidden-by=/app/static shadowed=/app:virtual)",
+		"conflict(path=/app/static/img hidden-by=/app/static shadowed=/app:virtual)",
+		"conflict(path=/app/static/img/aah-framework-logo.png hidden-by=/app/static shadowed=/app:virtual)",
+		"conflict(path=/app/static/img/favicon.ico hidden-by=/app/static shadowed=/app:virtual)",
+		"conflict(path=/app/static/js hidden-by=/app/static shadowed=/app:virtual)",
+		"conflict(path=/app/static/js/aah.js hidden-by=/app/static shadowed=/app:virtual)",
+		"conflict(path=/app/static/robots.txt hidden-by=/app/static shadowed=/app:virtual)",
+		"conflict(path=/app/static/env winner=/app/static/env:physical shadowed=/app/static:physical)",
+		"conflict(path=/app/static/env/dev.conf winner=/app/static/env:physical shadowed=/app/static:physical)",
+		"conflict(path=/app/static/env/prod.conf winner=/app/static/env:physical shadowed=/app/static:physical)",
+	}, got)
+}
+
+func TestVFSLazyMount(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vfs-lazy")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	assert.Nil(t, os.Mkdir(filepath.Join(dir, "views"), 0755))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "views", "index.html"), []byte("index"), 0644))
+
+	m, err := NewMount("/app", dir, Lazy(0))
+	assert.Nil(t, err)
+	data, err := m.ReadFile("/app/views/index.html")
+	assert.Nil(t, err)
+	assert.Equal(t, "index", string(data))
+	assert.False(t, m.IsExists("/app/views/about.html"))
+
+	// served from cache until invalidated
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "views", "about.html"), []byte("about"), 0644))
+	assert.False(t, m.IsExists("/app/views/about.html"))
+	_, err = m.Open("/app/views/about.html")
+	assert.True(t, os.IsNotExist(err))
+	infos, err := m.ReadDir("/app/views")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(infos))
+
+	m.Invalidate("/app/views")
+	assert.True(t, m.IsExists("/app/views/about.html"))
+	matches, err := m.Glob("/app/views/*.html")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/app/views/about.html", "/app/views/index.html"}, matches)
+
+	_, err = m.Stat("/app/not-exists/index.html")
+	assert.True(t, os.IsNotExist(err))
+
+	// expires after ttl
+	m, err = NewMount("/app", dir, Lazy(10*time.Millisecond))
+	assert.Nil(t, err)
+	assert.False(t, m.IsExists("/app/views/contact.html"))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "views", "contact.html"), []byte("contact"), 0644))
+	assert.False(t, m.IsExists("/app/views/contact.html"))
+	time.Sleep(20 * time.Millisecond)
+	assert.True(t, m.IsExists("/app/views/contact.html"))
+}
+
+func TestVFSStatCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vfs-statcache")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	assert.Nil(t, os.Mkdir(filepath.Join(dir, "static"), 0755))
+
+	m, err := NewMount("/app", dir, StatCache(0, 2))
+	assert.Nil(t, err)
+	_, err = m.Stat("/app/static/favicon.ico")
+	assert.True(t, os.IsNotExist(err))
+	fi, err := m.Lstat("/app/static")
+	assert.Nil(t, err)
+	assert.True(t, fi.IsDir())
+
+	// served from cache until invalidated
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "static", "favicon.ico"), []byte("icon"), 0644))
+	_, err = m.Stat("/app/static/favicon.ico")
+	assert.True(t, os.IsNotExist(err))
+	assert.True(t, m.IsExists("/app/static/favicon.ico")) // lstat is cached separately
+
+	m.Invalidate("/app/static/favicon.ico")
+	assert.Equal(t, 0, len(m.statCache.entries))
+	fi, err = m.Stat("/app/static/favicon.ico")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(4), fi.Size())
+
+	// bounded by max entries
+	_, _ = m.Stat("/app/robots.txt")
+	_, _ = m.Stat("/app/humans.txt")
+	assert.Equal(t, 2, len(m.statCache.entries))
+
+	// expires after ttl
+	m, err = NewMount("/app", dir, StatCache(10*time.Millisecond, 0))
+	assert.Nil(t, err)
+	assert.False(t, m.IsExists("/app/static/robots.txt"))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "static", "robots.txt"), []byte("User-agent: *"), 0644))
+	assert.False(t, m.IsExists("/app/static/robots.txt"))
+	time.Sleep(20 * time.Millisecond)
+	assert.True(t, m.IsExists("/app/static/robots.txt"))
+}
+
+func TestVFSContentCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vfs-contentcache")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(name[:1]+"1234"), 0644))
+	}
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "large.txt"), []byte("12345678901"), 0644))
+
+	m, err := NewMount("/app", dir, ContentCache(10))
+	assert.Nil(t, err)
+	cached := func(name string) bool {
+		_, found := m.contentCache.data.entries[filepath.Join(dir, name)]
+		return found
+	}
+
+	data, err := m.ReadFile("/app/a.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, "a1234", string(data))
+	assert.True(t, cached("a.txt"))
+
+	// caller owns the returned data
+	data[0] = 'x'
+	data, err = m.ReadFile("/app/a.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, "a1234", string(data))
+
+	// changed file is read again
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "a.txt"), []byte("A1234"), 0644))
+	mtime := time.Now().Add(time.Minute)
+	assert.Nil(t, os.Chtimes(filepath.Join(dir, "a.txt"), mtime, mtime))
+	data, err = m.ReadFile("/app/a.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, "A1234", string(data))
+
+	// least recently used is evicted beyond max bytes
+	_, err = m.ReadFile("/app/b.txt")
+	assert.Nil(t, err)
+	_, err = m.ReadFile("/app/c.txt")
+	assert.Nil(t, err)
+	assert.False(t, cached("a.txt"))
+	assert.True(t, cached("b.txt"))
+	assert.True(t, cached("c.txt"))
+	assert.Equal(t, int64(10), m.Stats().CacheSize)
+
+	data, err = m.ReadFile("/app/large.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, "12345678901", string(data))
+	assert.False(t, cached("large.txt"))
+
+	m.FlushContentCache()
+	assert.Equal(t, 0, len(m.contentCache.data.entries))
+	assert.Equal(t, int64(0), m.contentCache.budget.stats().Used)
+}
+
+func TestVFSMountWarm(t *testing.T) {
+	fs := createVFS(t)
+	m, err := fs.FindMount("/app")
+	assert.Nil(t, err)
+
+	assert.Nil(t, m.Warm("/app/config"))
+	assert.Equal(t, 2, len(m.cache.entries))
+	assert.Nil(t, m.WarmGlob("/app/views/*.html", "/app/static/*.txt"))
+	assert.Equal(t, 2, len(m.cache.entries))
+
+	expected, err := ioutil.ReadFile(filepath.Join(testdataBaseDir(), "vfstest", "config", "security.conf"))
+	assert.Nil(t, err)
+	f, err := m.Open("/app/config/security.conf")
+	assert.Nil(t, err)
+	_, ok := f.(*file).rs.(*bytes.Reader)
+	assert.True(t, ok)
+	assert.True(t, f.(Gziper).IsGzip())
+	data, err := ioutil.ReadAll(f)
+	assert.Nil(t, err)
+	assert.Equal(t, string(expected), string(data))
+	raw, found := f.(Compressed).EncodedBytes(EncodingGzip)
+	assert.True(t, found)
+	assert.Equal(t, f.(Gziper).RawBytes(), raw)
+	assert.Nil(t, f.Close())
+
+	// replaced file drops its warmed data
+	replaced := gzipString(t, "replaced")
+	assert.Nil(t, m.AddFile(&NodeInfo{Path: "/app/config/security.conf", DataSize: 8, Gzip: true}, []byte(replaced)))
+	assert.Equal(t, 1, len(m.cache.entries))
+	data, err = m.ReadFile("/app/config/security.conf")
+	assert.Nil(t, err)
+	assert.Equal(t, "replaced", string(data))
+
+	err = m.Warm("/app/config/not-exists.conf", "/app/config/aah.conf")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestVFSTrackAccessAndPrefetch(t *testing.T) {
+	buf := new(bytes.Buffer)
+	gw := gzip.NewWriter(buf)
+	_, err := gw.Write([]byte("console.log('aah')"))
+	assert.Nil(t, err)
+	assert.Nil(t, gw.Close())
+
+	newJSMount := func(opts ...MountOption) *Mount {
+		m, err := NewMount("/assets", "", opts...)
+		assert.Nil(t, err)
+		for _, name := range []string{"/assets/app.js", "/assets/vendor.js", "/assets/legacy.js"} {
+			assert.Nil(t, m.AddFile(&NodeInfo{Path: name, DataSize: 18}, buf.Bytes()))
+		}
+		return m
+	}
+
+	fs := new(VFS)
+	old := newJSMount(TrackAccess(2))
+	assert.Nil(t, fs.attach(old))
+	assert.Equal(t, []string{}, old.HotPaths(0))
+	for _, name := range []string{"/assets/vendor.js", "/assets/app.js", "/assets/vendor.js", "/assets/legacy.js", "/assets/app.js", "/assets/vendor.js"} {
+		data, err := fs.ReadFile(name)
+		assert.Nil(t, err)
+		assert.Equal(t, "console.log('aah')", string(data))
+	}
+	assert.Equal(t, []string{"/assets/vendor.js", "/assets/app.js", "/assets/legacy.js"}, old.HotPaths(0))
+	assert.Equal(t, []string{"/assets/vendor.js"}, old.HotPaths(1))
+
+	// swapped mount continues tracking and prefetches hottest paths
+	m := newJSMount()
+	assert.Nil(t, m.HotPaths(0))
+	fs.replace(m)
+	assert.Equal(t, []string{"/assets/vendor.js", "/assets/app.js", "/assets/legacy.js"}, m.HotPaths(0))
+
+	// close waits for the prefetch
+	assert.Nil(t, m.Close())
+	_, found := m.cache.get("/assets/vendor.js")
+	assert.True(t, found)
+	_, found = m.cache.get("/assets/app.js")
+	assert.True(t, found)
+	_, found = m.cache.get("/assets/legacy.js")
+	assert.False(t, found)
+
+	fs.SetMountOptions(TrackAccess(0))
+	assert.Nil(t, fs.AddMount("/static", filepath.Join(testdataBaseDir(), "vfstest", "static")))
+	_, err = fs.ReadFile("/static/robots.txt")
+	assert.Nil(t, err)
+	sm, err := fs.FindMount("/static")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/static/robots.txt"}, sm.HotPaths(0))
+}
+
+func TestVFSInstrument(t *testing.T) {
+	var events []string
+	hooks := Instrumentation{
+		OnOpen: func(name string, physical bool) {
+			events = append(events, fmt.Sprintf("open %s %v", name, physical))
+		},
+		OnReadFile: func(name string, n int, physical bool) {
+			events = append(events, fmt.Sprintf("readfile %s %d %v", name, n, physical))
+		},
+		OnMiss: func(op, name string) {
+			events = append(events, op+" "+name)
+		},
+	}
+	newMount := func() *Mount {
+		m, err := NewMount("/app", filepath.Join(testdataBaseDir(), "vfstest", "static"), Instrument(hooks))
+		assert.Nil(t, err)
+		assert.Nil(t, m.AddFile(&NodeInfo{Path: "/app/index.html", DataSize: 5}, []byte("hello")))
+		return m
+	}
+
+	fs := new(VFS)
+	old := newMount()
+	assert.Nil(t, fs.attach(old))
+	assert.Equal(t, MountMetrics{}, old.Metrics())
+
+	_, err := fs.ReadFile("/app/index.html")
+	assert.Nil(t, err)
+	_, err = fs.ReadFile("/app/robots.txt")
+	assert.Nil(t, err)
+	_, err = fs.Stat("/app/favicon.ico")
+	assert.True(t, os.IsNotExist(err))
+	_, err = fs.Open("/app/missing.css")
+	assert.True(t, os.IsNotExist(err))
+	f, err := fs.Open("/app/css/aah.css")
+	assert.Nil(t, err)
+	assert.Nil(t, f.Close())
+	assert.True(t, fs.IsExists("/app/css"))
+
+	assert.Equal(t, []string{
+		"open /app/index.html false",
+		"readfile /app/index.html 5 false",
+		"open /app/robots.txt true",
+		"readfile /app/robots.txt 68 true",
+		"stat /app/favicon.ico",
+		"open /app/missing.css",
+		"open /app/css/aah.css true",
+	}, events)
+	assert.Equal(t, MountMetrics{EmbeddedOpens: 1, PhysicalOpens: 2, Misses: 2, BytesRead: 73}, old.Metrics())
+	assert.Equal(t, "metrics(embedded=1 physical=2 misses=2 bytes=73)", old.Metrics().String())
+
+	// swapped mount continues the counters
+	m := newMount()
+	fs.replace(m)
+	_, err = fs.ReadFile("/app/index.html")
+	assert.Nil(t, err)
+	assert.Equal(t, MountMetrics{EmbeddedOpens: 2, PhysicalOpens: 2, Misses: 2, BytesRead: 78}, m.Metrics())
+
+	m, err = NewMount("/app", "")
+	assert.Nil(t, err)
+	_, _ = m.Open("/app/index.html")
+	assert.Equal(t, MountMetrics{}, m.Metrics())
+}
+
+func TestVFSMemoryLimit(t *testing.T) {
+	fs := createVFS(t)
+	assert.Equal(t, MemoryStats{}, fs.MemoryStats())
+	m, err := fs.FindMount("/app")
+	assert.Nil(t, err)
+
+	aahConf, err := ioutil.ReadFile(filepath.Join(testdataBaseDir(), "vfstest", "config", "aah.conf"))
+	assert.Nil(t, err)
+	securityConf, err := ioutil.ReadFile(filepath.Join(testdataBaseDir(), "vfstest", "config", "security.conf"))
+	assert.Nil(t, err)
+
+	fs.SetMemoryLimit(0)
+	assert.Nil(t, m.Warm("/app/config/aah.conf", "/app/config/security.conf"))
+	size := int64(len(aahConf) + len(securityConf))
+	assert.Equal(t, MemoryStats{Used: size, Entries: 2}, fs.MemoryStats())
+
+	// least recently used gets evicted
+	_, err = m.ReadFile("/app/config/aah.conf")
+	assert.Nil(t, err)
+	fs.SetMemoryLimit(size - 1)
+	assert.Equal(t, MemoryStats{Limit: size - 1, Used: int64(len(aahConf)), Entries: 1, Evictions: 1}, fs.MemoryStats())
+	_, found := m.cache.get("/app/config/security.conf")
+	assert.False(t, found)
+	data, err := m.ReadFile("/app/config/security.conf")
+	assert.Nil(t, err)
+	assert.Equal(t, string(securityConf), string(data))
+
+	// lazy tree of mounts added later shares the budget
+	fs.SetMountOptions(Lazy(0))
+	assert.Nil(t, fs.AddMount("/static", filepath.Join(testdataBaseDir(), "vfstest", "static")))
+	assert.True(t, fs.IsExists("/static/robots.txt"))
+	stats := fs.MemoryStats()
+	assert.Equal(t, 2, stats.Entries)
+	assert.True(t, stats.Used > int64(len(aahConf)))
+	assert.Equal(t, "memory(limit=", stats.String()[:13])
+
+	fs.detach("/static")
+	assert.Equal(t, MemoryStats{Limit: size - 1, Used: int64(len(aahConf)), Entries: 1, Evictions: 1}, fs.MemoryStats())
+}
+
+func TestVFSCompressCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vfs-gzcache")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "index.html"), []byte("index page"), 0644))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "large.bin"), make([]byte, 64), 0644))
+
+	m, err := NewMount("/app", dir, CompressCache(32))
+	assert.Nil(t, err)
+
+	f, err := m.Open("/app/index.html")
+	assert.Nil(t, err)
+	assert.True(t, f.(Gziper).IsGzip())
+	_, err = f.Seek(6, io.SeekStart)
+	assert.Nil(t, err)
+	data, err := ioutil.ReadAll(f)
+	assert.Nil(t, err)
+	assert.Equal(t, "page", string(data))
+	virtual, physical := m.OpenFiles()
+	assert.Equal(t, 1, virtual)
+	assert.Equal(t, 0, physical)
+	assert.Nil(t, f.Close())
+
+	// refreshed on change
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "index.html"), []byte("new index page"), 0644))
+	data, err = m.ReadFile("/app/index.html")
+	assert.Nil(t, err)
+	assert.Equal(t, "new index page", string(data))
+	assert.Equal(t, 1, len(m.gzCache.entries))
+
+	// beyond max size served from physical file
+	f, err = m.Open("/app/large.bin")
+	assert.Nil(t, err)
+	_, ok := f.(*physicalFile)
+	assert.True(t, ok)
+	assert.Nil(t, f.Close())
+
+	_, err = m.Open("/app/not-exists.html")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestVFSMountDataArena(t *testing.T) {
+	m, err := NewMount("/app", "")
+	assert.Nil(t, err)
+
+	data := []byte("hello")
+	assert.Nil(t, m.AddFile(&NodeInfo{Path: "/app/a.txt", DataSize: 5}, data))
+	assert.Nil(t, m.AddFile(&NodeInfo{Path: "/app/b.txt", DataSize: 5}, []byte("world")))
+	assert.Nil(t, m.AddFile(&NodeInfo{Path: "/app/large.bin", DataSize: defaultArenaChunkSize}, make([]byte, defaultArenaChunkSize)))
+	assert.Nil(t, m.AddFile(&NodeInfo{Path: "/app/empty.txt"}, []byte{}))
+	data[0] = 'j'
+
+	a, _ := m.tree.lookup("a.txt")
+	b, _ := m.tree.lookup("b.txt")
+	large, _ := m.tree.lookup("large.bin")
+	assert.Equal(t, "hello", string(a.data))
+	assert.Equal(t, 5, cap(a.data))
+	assert.Equal(t, 5, cap(b.data))
+	assert.Equal(t, defaultArenaChunkSize-10, len(m.arena.free)) // same chunk
+	assert.Equal(t, defaultArenaChunkSize, len(large.data))
+
+	got, err := m.ReadFile("/app/b.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, "world", string(got))
+	got, err = m.ReadFile("/app/empty.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(got))
+}
+
+func TestVFSMountLink(t *testing.T) {
+	m, err := NewMount("/app", "")
+	assert.Nil(t, err)
+	assert.Nil(t, m.AddDir(&NodeInfo{Path: "/app/fonts", Dir: true}))
+	assert.Nil(t, m.AddFile(&NodeInfo{Path: "/app/fonts/a.woff", DataSize: 4}, []byte("font")))
+
+	assert.Nil(t, m.Link("/app/fonts/a.woff", "/app/b.woff"))
+	fi, err := m.Stat("/app/b.woff")
+	assert.Nil(t, err)
+	assert.Equal(t, "b.woff", fi.Name())
+	assert.Equal(t, int64(4), fi.Size())
+	got, err := m.ReadFile("/app/b.woff")
+	assert.Nil(t, err)
+	assert.Equal(t, "font", string(got))
+
+	a, _ := m.tree.lookup("fonts/a.woff")
+	b, _ := m.tree.lookup("b.woff")
+	assert.True(t, &a.data[0] == &b.data[0])
+	assert.Equal(t, "/app/fonts/a.woff", a.Path)
+
+	mt := time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.Nil(t, m.AddEncoded("/app/fonts/a.woff", EncodingBrotli, []byte("br")))
+	assert.Nil(t, m.AddLink(&NodeInfo{Path: "/app/fonts/c.woff", DataSize: 4, Time: mt}, "/app/fonts/a.woff"))
+	c, _ := m.tree.lookup("fonts/c.woff")
+	assert.True(t, &a.data[0] == &c.data[0])
+	assert.True(t, mt.Equal(c.ModTime()))
+	encoded, found := c.EncodedBytes(EncodingBrotli)
+	assert.True(t, found)
+	assert.Equal(t, "br", string(encoded))
+	assert.NotNil(t, m.AddLink(&NodeInfo{Path: "/app/fonts/d.woff"}, "/app/fonts"))
+	assert.NotNil(t, m.AddLink(&NodeInfo{Path: "/app/fonts/d.woff"}, "/app/missing.woff"))
+
+	for _, c := range []struct{ old, new string }{
+		{"/app/missing.woff", "/app/c.woff"},
+		{"/app/fonts", "/app/c"},
+		{"/app/fonts/a.woff", "/app/b.woff"},
+		{"/app/fonts/a.woff", "/other/c.woff"},
+	} {
+		err = m.Link(c.old, c.new)
+		_, ok := err.(*os.LinkError)
+		assert.True(t, ok)
+	}
+	assert.True(t, os.IsExist(m.Link("/app/fonts/a.woff", "/app/b.woff")))
+}
+
+func TestVFSMountValidate(t *testing.T) {
+	fs := createVFS(t)
+	m, err := fs.FindMount("/app")
+	assert.Nil(t, err)
+	assert.Nil(t, m.Validate())
+
+	m, err = NewMount("/app", "")
+	assert.Nil(t, err)
+	assert.Nil(t, m.AddDir(&NodeInfo{Path: "/app/css", Dir: true}))
+	assert.Nil(t, m.AddFile(&NodeInfo{Path: "/app/css/a.css", DataSize: 1}, []byte("a")))
+	assert.Nil(t, m.AddFile(&NodeInfo{Path: "/app/b.css", DataSize: 1}, []byte("b")))
+	assert.Nil(t, m.Validate())
+
+	css, _ := m.tree.lookup("css")
+	css.data = []byte("x")
+	a := css.childs["a.css"]
+	css.childInfos = append(css.childInfos, a)
+	delete(m.tree.childs, "b.css")
+	m.tree.childs["c.css"] = newNode("/app/other/c.css", &NodeInfo{DataSize: 1})
+
+	issues := m.Validate()
+	var kinds []string
+	for _, i := range issues {
+		kinds = append(kinds, i.Path+" "+i.Kind)
+	}
+	assert.Equal(t, []string{
+		"/app/b.css orphan",
+		"/app/c.css orphan",
+		"/app/css dir-with-data",
+		"/app/css/a.css duplicate-name",
+		"/app/other/c.css path-mismatch",
+	}, kinds)
+	assert.Equal(t, "issue(path=/app/css kind=dir-with-data detail=directory has 1 bytes of data)", issues[2].String())
+}
+
+func TestVFSMountVerify(t *testing.T) {
+	fs := createVFS(t)
+	m, err := fs.FindMount("/app")
+	assert.Nil(t, err)
+	r := m.Verify()
+	assert.True(t, r.OK())
+	assert.True(t, r.Files > 0)
+	assert.True(t, r.Bytes > 0)
+
+	m, err = NewMount("/app", "")
+	assert.Nil(t, err)
+	h := sha256.Sum256([]byte("a"))
+	assert.Nil(t, m.AddFile(&NodeInfo{Path: "/app/a.css", DataSize: 1, SHA256: hex.EncodeToString(h[:])}, []byte("a")))
+	assert.Nil(t, m.AddFile(&NodeInfo{Path: "/app/b.css", DataSize: 1, SHA256: hex.EncodeToString(h[:])}, []byte("b")))
+	assert.Nil(t, m.AddFile(&NodeInfo{Path: "/app/c.css", DataSize: 2}, []byte("c")))
+	assert.Nil(t, m.AddFile(&NodeInfo{Path: "/app/d.css", DataSize: 1}, append([]byte{}, gzipMemberHeader...)))
+	assert.Nil(t, m.AddFileString("/app/e.css", nil, "e"))
+
+	r = m.Verify()
+	assert.False(t, r.OK())
+	assert.Equal(t, 5, r.Files)
+	var kinds []string
+	for _, i := range r.Issues {
+		kinds = append(kinds, i.Path+" "+i.Kind)
+	}
+	assert.Equal(t, []string{
+		"/app/b.css checksum-mismatch",
+		"/app/c.css size-mismatch",
+		"/app/d.css corrupt-gzip",
+	}, kinds)
+	assert.Equal(t, "data size is 1, expected 2", r.Issues[1].Detail)
+}
+
+func TestVFSMountPhysicalNode(t *testing.T) {
+	src := filepath.Join(testdataBaseDir(), "vfstest", "static")
+	logo, err := ioutil.ReadFile(filepath.Join(src, "img", "aah-framework-logo.png"))
+	assert.FailNowOnError(t, err, "")
+	mt := time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	m, err := NewMount("/app", src)
+	assert.Nil(t, err)
+	assert.Nil(t, m.AddDir(&NodeInfo{Path: "/app/img", Dir: true, Time: mt}))
+	assert.Nil(t, m.AddFile(&NodeInfo{Path: "/app/img/aah-framework-logo.png", DataSize: int64(len(logo)), Time: mt, Physical: true}, nil))
+
+	fi, err := m.Stat("/app/img/aah-framework-logo.png")
+	assert.Nil(t, err)
+	assert.True(t, mt.Equal(fi.ModTime()))
+	data, err := m.ReadFile("/app/img/aah-framework-logo.png")
+	assert.Nil(t, err)
+	assert.Equal(t, logo, data)
+	assert.True(t, m.Verify().OK())
+	assert.Equal(t, 0, m.Verify().Files)
+
+	var walked []string
+	assert.Nil(t, m.Walk("/app/img", func(fpath string, fi os.FileInfo, err error) error {
+		walked = append(walked, fpath)
+		return err
+	}))
+	assert.True(t, ess.IsSliceContainsString(walked, "/app/img/aah-framework-logo.png"))
+
+	// embedded only, data is not available
+	m.SetEmbeddedOnly(true)
+	_, err = m.Open("/app/img/aah-framework-logo.png")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestVFSMountAddFileAll(t *testing.T) {
+	m, err := NewMount("/app", "", Strict())
+	assert.Nil(t, err)
+
+	mt := time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.Nil(t, m.AddFileAll(&NodeInfo{Path: "/app/static/css/a.css", DataSize: 1, Time: mt}, []byte("a")))
+	assert.Nil(t, m.AddDirAll(&NodeInfo{Path: "/app/static/img/icons", Dir: true, Time: mt}))
+	assert.Nil(t, m.AddFileAll(&NodeInfo{Path: "/app/static/css/b.css", DataSize: 1, Time: mt}, []byte("b")))
+
+	for _, name := range []string{"/app/static", "/app/static/css", "/app/static/img", "/app/static/img/icons"} {
+		fi, err := m.Stat(name)
+		assert.Nil(t, err)
+		assert.True(t, fi.IsDir())
+		assert.True(t, fi.ModTime().Equal(mt))
+	}
+	got, err := m.ReadFile("/app/static/css/b.css")
+	assert.Nil(t, err)
+	assert.Equal(t, "b", string(got))
+	assert.Nil(t, m.Validate())
+
+	err = m.AddFileAll(&NodeInfo{Path: "/app/static/css/a.css/x.css", DataSize: 1}, []byte("x"))
+	assert.NotNil(t, err)
+	assert.True(t, strings.Contains(err.Error(), "not a directory"))
+	assert.NotNil(t, m.AddFileAll(&NodeInfo{Path: "/other/x.css", DataSize: 1}, []byte("x")))
+}
+
+func TestVFSMaxReadSize(t *testing.T) {
+	fs := createVFS(t)
+	fs.SetMountOptions(MaxReadSize(1024))
+	assert.Nil(t, fs.AddMount("/limited", filepath.Join(testdataBaseDir(), "vfstest", "static")))
+
+	data, err := fs.ReadFile("/limited/robots.txt")
+	assert.Nil(t, err)
+	assert.True(t, len(data) > 0)
+
+	_, err = fs.ReadFile("/limited/img/aah-framework-logo.png")
+	assert.NotNil(t, err)
+	assert.Equal(t, ErrFileTooLarge, err.(*os.PathError).Err)
+
+	// streaming is not limited
+	f, err := fs.Open("/limited/img/aah-framework-logo.png")
+	assert.Nil(t, err)
+	n, err := io.Copy(ioutil.Discard, f)
+	assert.Nil(t, err)
+	assert.True(t, n > 1024)
+	assert.Nil(t, f.Close())
+}
+
+func TestVFSCleanPath(t *testing.T) {
+	for name, expected := range map[string]string{
+		"/":                 "/",
+		"app":               "/app",
+		"/app/config/":      "/app/config",
+		"/app/./config/env": "/app/config/env",
+		"./app":             "/app",
+	} {
+		got, err := CleanPath(name)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, got)
+	}
+
+	for _, name := range []string{"", "/app//config", "/app/a\x00b", "//app"} {
+		_, err := CleanPath(name)
+		assert.NotNil(t, err)
+		assert.Equal(t, ErrInvalidPath, err.(*os.PathError).Err)
+	}
+	for _, name := range []string{"/app/../etc/passwd", "..", "../app"} {
+		_, err := CleanPath(name)
+		assert.Equal(t, os.ErrPermission, err.(*os.PathError).Err)
+	}
+
+	MaxPathLength = 10
+	_, err := CleanPath("/app/config/aah.conf")
+	assert.Equal(t, ErrInvalidPath, err.(*os.PathError).Err)
+	MaxPathLength = 0
+
+	// all backends agree
+	fs := createVFS(t)
+	mfs := NewMemFS()
+	m, err := fs.FindMount("/app")
+	assert.Nil(t, err)
+	for _, b := range []FileSystem{fs, m, mfs} {
+		_, err = b.Open("/app/../app/config/aah.conf")
+		assert.True(t, os.IsPermission(err))
+		_, err = b.ReadFile("/app/../../etc/passwd")
+		assert.True(t, os.IsPermission(err))
+		_, err = b.Stat("/app//config")
+		assert.Equal(t, ErrInvalidPath, err.(*os.PathError).Err)
+		_, err = b.Glob("/app/../*")
+		assert.True(t, os.IsPermission(err))
+	}
+	fi, err := fs.Stat("/app/config/")
+	assert.Nil(t, err)
+	assert.True(t, fi.IsDir())
+}
+
+func TestVFSOverlay(t *testing.T) {
+	mt := time.Date(2018, 6, 17, 0, 0, 0, 0, time.UTC)
+	newLayer := func(files map[string]string) *Mount {
+		m, err := NewMount("/static", "")
+		assert.FailNowOnError(t, err, "")
+		for name, content := range files {
+			if content == "" {
+				assert.Nil(t, m.AddDirAll(&NodeInfo{Dir: true, Path: name, Time: mt}))
+			} else {
+				assert.Nil(t, m.AddFileAll(&NodeInfo{Path: name, DataSize: int64(len(content)), Time: mt}, []byte(content)))
+			}
+		}
+		return m
+	}
+
+	theme := newLayer(map[string]string{
+		"/static/css/aah.css": "theme",
+		"/static/img":         "",
+		"/static/js":          "theme js file",
+	})
+	base := newLayer(map[string]string{
+		"/static/css/aah.css":   "default",
+		"/static/css/print.css": "print",
+		"/static/img/logo.png":  "logo",
+		"/static/js/aah.js":     "js",
+		"/static/robots.txt":    "robots",
+	})
+	o := NewOverlay(theme, base)
+
+	data, err := o.ReadFile("/static/css/aah.css")
+	assert.Nil(t, err)
+	assert.Equal(t, "theme", string(data))
+	data, err = o.ReadFile("/static/css/print.css")
+	assert.Nil(t, err)
+	assert.Equal(t, "print", string(data))
+
+	names := func(infos []os.FileInfo) []string {
+		var list []string
+		for _, fi := range infos {
+			list = append(list, fi.Name())
+		}
+		return list
+	}
+	infos, err := o.ReadDir("/static")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"css", "img", "js", "robots.txt"}, names(infos))
+	infos, err = o.ReadDir("/static/img")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"logo.png"}, names(infos))
+
+	// file of upper layer shadows the directory
+	fi, err := o.Stat("/static/js")
+	assert.Nil(t, err)
+	assert.False(t, fi.IsDir())
+	assert.False(t, o.IsExists("/static/js/aah.js"))
+	_, err = o.ReadDir("/static/js")
+	assert.NotNil(t, err)
+
+	f, err := o.Open("/static/css")
+	assert.Nil(t, err)
+	infos, err = f.Readdir(-1)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"aah.css", "print.css"}, names(infos))
+	assert.Nil(t, f.Close())
+
+	matches, err := o.Glob("/static/css/*.css")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/static/css/aah.css", "/static/css/print.css"}, matches)
+	matches, err = o.Glob("/static/js/*.js")
+	assert.Nil(t, err)
+	assert.True(t, len(matches) == 0)
+
+	_, err = o.Open("/static/missing.txt")
+	assert.True(t, os.IsNotExist(err))
+	_, err = o.OpenFile("/static/robots.txt", os.O_WRONLY, 0)
+	assert.Equal(t, ErrReadOnly, err.(*os.PathError).Err)
+}
+
+func TestVFSMountConcurrentAdd(t *testing.T) {
+	m, err := NewMount("/app", "")
+	assert.Nil(t, err)
+	assert.Nil(t, m.AddDir(&NodeInfo{Dir: true, Path: "/app/plugins"}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				name := fmt.Sprintf("/app/plugins/p%d/%02d.html", i, j)
+				assert.Nil(t, m.AddFileAll(&NodeInfo{Path: name, DataSize: 1}, []byte("x")))
+			}
+		}(i)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				infos, err := m.ReadDir("/app/plugins")
+				assert.Nil(t, err)
+				for _, fi := range infos {
+					_, _ = m.ReadDir(path.Join("/app/plugins", fi.Name()))
+				}
+				_, _ = m.Glob("/app/plugins/p0/*.html")
+				_, _ = m.ReadFile("/app/plugins/p0/00.html")
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i := 0; i < 4; i++ {
+		infos, err := m.ReadDir(fmt.Sprintf("/app/plugins/p%d", i))
+		assert.Nil(t, err)
+		assert.Equal(t, 50, len(infos))
+	}
+	assert.Equal(t, 0, len(m.Validate()))
+}
+
+func TestVFSMountWalk(t *testing.T) {
+	m, err := NewMount("/app", filepath.Join(testdataBaseDir(), "vfstest"))
+	assert.Nil(t, err)
+	assert.Nil(t, m.AddDir(&NodeInfo{Dir: true, Path: "/app/config"}))
+	assert.Nil(t, m.AddFile(&NodeInfo{Path: "/app/config/aah.conf", DataSize: 7}, []byte("virtual")))
+	assert.Nil(t, m.AddFile(&NodeInfo{Path: "/app/config/extra.conf", DataSize: 5}, []byte("extra")))
+
+	var visited []string
+	err = Walk(m, "/app/config", func(fpath string, info os.FileInfo, err error) error {
+		assert.Nil(t, err)
+		if fpath == "/app/config/aah.conf" {
+			assert.Equal(t, int64(7), info.Size())
+		}
+		visited = append(visited, fpath)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/app/config", "/app/config/aah.conf", "/app/config/env",
+		"/app/config/env/dev.conf", "/app/config/env/prod.conf", "/app/config/extra.conf",
+		"/app/config/routes.conf", "/app/config/security.conf"}, visited)
+
+	// generic FileSystem
+	mfs := NewMemFS()
+	assert.Nil(t, mfs.Mkdir("/views", 0755))
+	assert.Nil(t, mfs.WriteFile("/views/index.html", []byte("index"), 0644))
+	visited = nil
+	err = Walk(mfs, "/", func(fpath string, info os.FileInfo, err error) error {
+		visited = append(visited, fpath)
+		return err
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/", "/views", "/views/index.html"}, visited)
+
+	var nilVFS *VFS
+	err = Walk(nilVFS, filepath.Join(testdataBaseDir(), "vfstest", "config", "env"), func(fpath string, info os.FileInfo, err error) error {
+		return err
+	})
+	assert.Nil(t, err)
+}
+
+func TestVFSGlobStar(t *testing.T) {
+	fs := createVFS(t)
+
+	matches, err := GlobStar(fs, "/app/views/**/*.html")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/app/views/common/error_footer.html", "/app/views/common/error_header.html",
+		"/app/views/common/footer_scripts.html", "/app/views/common/head_tags.html",
+		"/app/views/errors/404.html", "/app/views/errors/500.html",
+		"/app/views/layouts/master.html", "/app/views/pages/app/index.html"}, matches)
+
+	matches, err = GlobStar(fs, "/*/static/**/*.{css,js,{png,txt}}")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/app/static/css/aah.css", "/app/static/img/aah-framework-logo.png",
+		"/app/static/js/aah.js", "/app/static/robots.txt"}, matches)
+
+	matches, err = GlobStar(fs, "/app/**/env")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/app/config/env"}, matches)
+
+	matches, err = GlobStar(fs, "/app/config/{aah,routes}.conf")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/app/config/aah.conf", "/app/config/routes.conf"}, matches)
+
+	matches, err = GlobStar(fs, "/app/not-exists/**")
+	assert.Nil(t, err)
+	assert.True(t, len(matches) == 0)
+
+	// mount virtual tree and physical fallback
+	m, err := NewMount("/app", filepath.Join(testdataBaseDir(), "vfstest"))
+	assert.Nil(t, err)
+	assert.Nil(t, m.AddDir(&NodeInfo{Dir: true, Path: "/app/views"}))
+	assert.Nil(t, m.AddFile(&NodeInfo{Path: "/app/views/virtual.html", DataSize: 1}, []byte("v")))
+	matches, err = GlobStar(m, "/app/views/*/*.html")
+	assert.Nil(t, err)
+	assert.Equal(t, 7, len(matches))
+	matches, err = GlobStar(m, "/app/views/**/{virtual,master}.html")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/app/views/layouts/master.html", "/app/views/virtual.html"}, matches)
+
+	// physical filesystem
+	matches, err = GlobStar(nil, filepath.Join(testdataBaseDir(), "vfstest", "config", "**", "*.conf"))
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(matches))
+
+	for _, pattern := range []string{"/app/{a,b", "/app/a}", "/app/[*.html"} {
+		_, err = GlobStar(fs, pattern)
+		assert.Equal(t, path.ErrBadPattern, err)
+	}
+}
+
+func TestVFSUnmountAndListMounts(t *testing.T) {
+	mountDir := filepath.Join(testdataBaseDir(), "vfstest")
+	fs := new(VFS)
+	assert.Nil(t, fs.AddMount("/app", mountDir))
+	assert.Nil(t, fs.AddMount("/app/static", filepath.Join(mountDir, "static")))
+	assert.Nil(t, fs.AddMount("/assets", filepath.Join(mountDir, "static")))
+
+	var names []string
+	for _, m := range fs.ListMounts() {
+		names = append(names, m.Name())
+	}
+	assert.Equal(t, []string{"/app", "/app/static", "/assets"}, names)
+
+	m, err := fs.FindMount("/app/static/css/aah.css")
+	assert.Nil(t, err)
+	assert.Equal(t, "/app/static", m.Name())
+	m, err = fs.FindMount("/app/config/aah.conf")
+	assert.Nil(t, err)
+	assert.Equal(t, "/app", m.Name())
+
+	assert.Nil(t, fs.Unmount("/app/"))
+	assert.True(t, fs.IsExists("/app/static/css/aah.css"))
+	assert.False(t, fs.IsExists("/app/config/aah.conf"))
+	err = fs.Unmount("/app")
+	assert.Equal(t, ErrMountNotExists, err.(*os.PathError).Err)
+	assert.Equal(t, 2, len(fs.ListMounts()))
+
+	assert.Nil(t, fs.AddMount("/app", mountDir))
+	assert.Equal(t, 3, len(fs.ListMounts()))
+}
+
+func TestVFSGzipDataSeek(t *testing.T) {
+	content := strings.Repeat("aah framework, ", 1000)
+	buf := new(bytes.Buffer)
+	gw := gzip.NewWriter(buf)
+	_, err := gw.Write([]byte(content))
+	assert.Nil(t, err)
+	assert.Nil(t, gw.Close())
+
+	m, err := NewMount("/assets", "")
+	assert.Nil(t, err)
+	assert.Nil(t, m.AddFile(&NodeInfo{Path: "/assets/app.txt", DataSize: int64(len(content))}, buf.Bytes()))
+	corrupt := append([]byte{}, gzipMemberHeader...)
+	assert.Nil(t, m.AddFile(&NodeInfo{Path: "/assets/corrupt.txt", DataSize: 10}, append(corrupt, "corrupt"...)))
+
+	f, err := m.Open("/assets/app.txt")
+	assert.Nil(t, err)
+	g := f.(*file).rs.(*gzipData)
+	assert.True(t, g.r == nil)
+
+	// streaming forward
+	b := make([]byte, 3)
+	_, err = f.Seek(15, io.SeekStart)
+	assert.Nil(t, err)
+	_, err = io.ReadFull(f, b)
+	assert.Nil(t, err)
+	assert.Equal(t, "aah", string(b))
+	assert.True(t, g.full == nil)
+
+	// backward materializes the payload
+	_, err = f.Seek(4, io.SeekStart)
+	assert.Nil(t, err)
+	_, err = io.ReadFull(f, b)
+	assert.Nil(t, err)
+	assert.Equal(t, "fra", string(b))
+	assert.True(t, g.full != nil && g.r == nil)
+
+	pos, err := f.Seek(-4, io.SeekEnd)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(len(content)-4), pos)
+	data, err := ioutil.ReadAll(f)
+	assert.Nil(t, err)
+	assert.Equal(t, "k, ", string(data[1:]))
+	assert.Nil(t, f.Close())
+
+	_, err = m.ReadFile("/assets/corrupt.txt")
+	assert.NotNil(t, err)
+}
+
+func TestVFSRawGzipFile(t *testing.T) {
+	buf := new(bytes.Buffer)
+	gw := gzip.NewWriter(buf)
+	_, err := gw.Write([]byte("aah framework"))
+	assert.Nil(t, err)
+	assert.Nil(t, gw.Close())
+	archive := buf.Bytes()
+
+	// gzip file as-is, header is not sniffed
+	m, err := NewMount("/assets", "")
+	assert.Nil(t, err)
+	assert.Nil(t, m.AddFile(&NodeInfo{Path: "/assets/app.tar.gz", DataSize: int64(len(archive)), Raw: true}, archive))
+	fi, err := m.Stat("/assets/app.tar.gz")
+	assert.Nil(t, err)
+	data, err := m.ReadFile("/assets/app.tar.gz")
+	assert.Nil(t, err)
+	assert.Equal(t, archive, data)
+	assert.Equal(t, fi.Size(), int64(len(data)))
+
+	f, err := m.Open("/assets/app.tar.gz")
+	assert.Nil(t, err)
+	assert.False(t, f.(Gziper).IsGzip())
+	assert.Nil(t, f.Close())
+
+	// neither flag set, gzip header is sniffed as earlier versions did
+	assert.Nil(t, m.AddFile(&NodeInfo{Path: "/assets/app.txt", DataSize: 13}, archive))
+	f, err = m.Open("/assets/app.txt")
+	assert.Nil(t, err)
+	assert.True(t, f.(Gziper).IsGzip())
+	assert.Nil(t, f.Close())
+	data, err = m.ReadFile("/assets/app.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, "aah framework", string(data))
+}
+
+func TestVFSFileReadAt(t *testing.T) {
+	fs := createVFS(t)
+	for _, name := range []string{"/app/config/routes.conf", "/app/config/security.conf"} {
+		expected, err := fs.ReadFile(name)
+		assert.Nil(t, err)
+
+		f, err := fs.Open(name)
+		assert.Nil(t, err)
+		ra, ok := f.(io.ReaderAt)
+		assert.True(t, ok)
+
+		b := make([]byte, 10)
+		_, err = io.ReadFull(f, b)
+		assert.Nil(t, err)
+		n, err := ra.ReadAt(b, 100)
+		assert.Nil(t, err)
+		assert.Equal(t, 10, n)
+		assert.Equal(t, string(expected[100:110]), string(b))
+
+		// read offset is not affected
+		rest, err := ioutil.ReadAll(f)
+		assert.Nil(t, err)
+		assert.Equal(t, string(expected[10:]), string(rest))
+
+		n, err = ra.ReadAt(b, int64(len(expected)-4))
+		assert.Equal(t, io.EOF, err)
+		assert.Equal(t, 4, n)
+		_, err = ra.ReadAt(b, -1)
+		assert.NotNil(t, err)
+		assert.Nil(t, f.Close())
+	}
+
+	d, err := fs.Open("/app/config")
+	assert.Nil(t, err)
+	_, err = d.(io.ReaderAt).ReadAt(make([]byte, 1), 0)
+	assert.NotNil(t, err)
+
+	// physical file
+	m, err := NewMount("/app", filepath.Join(testdataBaseDir(), "vfstest"))
+	assert.Nil(t, err)
+	f, err := m.Open("/app/static/robots.txt")
+	assert.Nil(t, err)
+	_, ok := f.(io.ReaderAt)
+	assert.True(t, ok)
+	assert.Nil(t, f.Close())
+
+	mfs := NewMemFS()
+	assert.Nil(t, mfs.WriteFile("/robots.txt", []byte("User-agent: *"), 0644))
+	f, err = mfs.Open("/robots.txt")
+	assert.Nil(t, err)
+	b := make([]byte, 5)
+	_, err = f.(io.ReaderAt).ReadAt(b, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, "User-", string(b))
+	assert.Nil(t, f.Close())
+}
+
+func TestVFSIndependentOpens(t *testing.T) {
+	fs := createVFS(t)
+	for _, name := range []string{"/app/config/routes.conf", "/app/config/security.conf"} {
+		expected, err := fs.ReadFile(name)
+		assert.Nil(t, err)
+
+		f1, err := fs.Open(name)
+		assert.Nil(t, err)
+		f2, err := fs.Open(name)
+		assert.Nil(t, err)
+
+		b1, b2 := make([]byte, 8), make([]byte, 8)
+		_, err = io.ReadFull(f1, b1)
+		assert.Nil(t, err)
+		_, err = f2.Seek(20, io.SeekStart)
+		assert.Nil(t, err)
+		_, err = io.ReadFull(f2, b2)
+		assert.Nil(t, err)
+		_, err = io.ReadFull(f1, b1)
+		assert.Nil(t, err)
+		assert.Equal(t, string(expected[8:16]), string(b1))
+		assert.Equal(t, string(expected[20:28]), string(b2))
+		assert.Nil(t, f1.Close())
+
+		// close of one does not affect other
+		rest, err := ioutil.ReadAll(f2)
+		assert.Nil(t, err)
+		assert.Equal(t, string(expected[28:]), string(rest))
+		assert.Nil(t, f2.Close())
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				f, err := fs.Open(name)
+				assert.Nil(t, err)
+				defer func() { _ = f.Close() }()
+				data, err := ioutil.ReadAll(f)
+				assert.Nil(t, err)
+				assert.Equal(t, len(expected), len(data))
+			}()
+		}
+		wg.Wait()
+	}
+
+	d1, err := fs.Open("/app/config")
+	assert.Nil(t, err)
+	d2, err := fs.Open("/app/config")
+	assert.Nil(t, err)
+	names1, err := d1.Readdirnames(2)
+	assert.Nil(t, err)
+	names2, err := d2.Readdirnames(-1)
+	assert.Nil(t, err)
+	assert.Equal(t, names1, names2[:2])
+}
+
+func TestVFSChecksum(t *testing.T) {
+	fs := createVFS(t)
+	m, err := fs.FindMount("/app")
+	assert.Nil(t, err)
+
+	data := []byte("body { color: #333; }")
+	sum := sha256.Sum256(data)
+	expected := hex.EncodeToString(sum[:])
+	assert.Nil(t, m.AddFile(&NodeInfo{Path: "/app/static/css/sum.css", DataSize: int64(len(data)),
+		Time: time.Now(), SHA256: expected}, data))
+	assert.Nil(t, m.Link("/app/static/css/sum.css", "/app/static/css/sum-link.css"))
+
+	for _, name := range []string{"/app/static/css/sum.css", "/app/static/css/sum-link.css"} {
+		fi, err := fs.Stat(name)
+		assert.Nil(t, err)
+		c, ok := fi.(Checksummer)
+		assert.True(t, ok)
+		got, found := c.Checksum()
+		assert.True(t, found)
+		assert.Equal(t, expected, got)
+
+		f, err := fs.Open(name)
+		assert.Nil(t, err)
+		got, found = f.(Checksummer).Checksum()
+		assert.True(t, found)
+		assert.Equal(t, expected, got)
+		_ = f.Close()
+	}
+
+	// not known
+	fi, err := fs.Stat("/app/config/aah.conf")
+	assert.Nil(t, err)
+	_, found := fi.(Checksummer).Checksum()
+	assert.False(t, found)
+}
+
+func TestVFSMountSymlinkAddFile(t *testing.T) {
+	m, err := NewMount("/app", "")
+	assert.FailNowOnError(t, err, "")
+	assert.Nil(t, m.AddFileString("/app/hello.txt", nil, "hello"))
+	assert.Nil(t, m.AddFile(&NodeInfo{Path: "/app/link.txt", Symlink: "hello.txt"}, nil))
+	assert.Equal(t, int32(1), m.symlinks)
+
+	data, err := m.ReadFile("/app/link.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, "hello", string(data))
+	fi, err := m.Lstat("/app/link.txt")
+	assert.Nil(t, err)
+	assert.True(t, fi.Mode()&os.ModeSymlink != 0)
+
+	// replaced link is not counted
+	assert.Nil(t, m.AddFileString("/app/link.txt", nil, "file"))
+	assert.Equal(t, int32(0), m.symlinks)
+	data, err = m.ReadFile("/app/link.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, "file", string(data))
+
+	// file renamed over link
+	assert.Nil(t, m.Symlink("hello.txt", "/app/other.txt"))
+	assert.Equal(t, int32(1), m.symlinks)
+	assert.Nil(t, m.Rename("/app/link.txt", "/app/other.txt"))
+	assert.Equal(t, int32(0), m.symlinks)
+	fi, err = m.Lstat("/app/other.txt")
+	assert.Nil(t, err)
+	assert.True(t, fi.Mode()&os.ModeSymlink == 0)
+	data, err = m.ReadFile("/app/other.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, "file", string(data))
+}
+
+func TestVFSMountSymlink(t *testing.T) {
+	fs := createVFS(t)
+	m, err := fs.FindMount("/app")
+	assert.Nil(t, err)
+	expected, err := fs.ReadFile("/app/static/css/aah.css")
+	assert.Nil(t, err)
+
+	assert.Nil(t, m.Symlink("css/aah.css", "/app/static/style.css"))
+	assert.Nil(t, m.Symlink("/app/static/css", "/app/static/styles"))
+	assert.Nil(t, m.Symlink("../style.css", "/app/static/js/style.css"))
+
+	for _, name := range []string{"/app/static/style.css", "/app/static/styles/aah.css", "/app/static/js/style.css"} {
+		data, err := fs.ReadFile(name)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, data)
+	}
+
+	fi, err := fs.Lstat("/app/static/style.css")
+	assert.Nil(t, err)
+	assert.True(t, fi.Mode()&os.ModeSymlink != 0)
+	fi, err = fs.Stat("/app/static/style.css")
+	assert.Nil(t, err)
+	assert.True(t, fi.Mode()&os.ModeSymlink == 0)
+	assert.Equal(t, int64(len(expected)), fi.Size())
+
+	fi, err = fs.Stat("/app/static/styles")
+	assert.Nil(t, err)
+	assert.True(t, fi.IsDir())
+	infos, err := fs.ReadDir("/app/static/styles")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(infos))
+	assert.Equal(t, "aah.css", infos[0].Name())
+
+	// directory listing has the link itself
+	infos, err = fs.ReadDir("/app/static")
+	assert.Nil(t, err)
+	for _, fi := range infos {
+		if fi.Name() == "styles" {
+			assert.True(t, fi.Mode()&os.ModeSymlink != 0)
+		}
+	}
+
+	target, err := fs.Readlink("/app/static/styles")
+	assert.Nil(t, err)
+	assert.Equal(t, "/app/static/css", target)
+	target, err = Readlink(fs, "/app/static/js/style.css")
+	assert.Nil(t, err)
+	assert.Equal(t, "../style.css", target)
+	_, err = fs.Readlink("/app/static/css/aah.css")
+	assert.NotNil(t, err)
+
+	// loop, dangling and outside of mount
+	assert.Nil(t, m.Symlink("loop2", "/app/static/loop1"))
+	assert.Nil(t, m.Symlink("loop1", "/app/static/loop2"))
+	_, err = fs.Open("/app/static/loop1")
+	assert.NotNil(t, err)
+	assert.Equal(t, ErrTooManyLinks, err.(*os.PathError).Err)
+	_, err = fs.Lstat("/app/static/loop1")
+	assert.Nil(t, err)
+
+	assert.Nil(t, m.Symlink("not-exists.css", "/app/static/dangling.css"))
+	_, err = fs.Stat("/app/static/dangling.css")
+	assert.True(t, os.IsNotExist(err))
+	assert.Nil(t, m.Symlink("/etc/passwd", "/app/static/passwd"))
+	_, err = fs.ReadFile("/app/static/passwd")
+	assert.True(t, os.IsNotExist(err))
+
+	// errors
+	assert.NotNil(t, m.Symlink("css/aah.css", "/app/static/style.css"))
+	assert.NotNil(t, m.Symlink("css/aah.css", "/app/static/not-exists/style.css"))
+	assert.NotNil(t, m.Symlink("css/aah.css", "/other/style.css"))
+	assert.NotNil(t, m.Symlink("", "/app/static/empty.css"))
+}
+
+func TestVFSPhysicalContainment(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlink creation requires privilege on windows")
+	}
+
+	base, err := ioutil.TempDir("", "vfs-contain")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(base) }()
+
+	dir := filepath.Join(base, "app")
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "assets"), 0755))
+	assert.Nil(t, os.MkdirAll(filepath.Join(base, "appsecret"), 0755))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(base, "secret.txt"), []byte("secret"), 0644))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(base, "appsecret", "key.txt"), []byte("key"), 0644))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "assets", "app.css"), []byte("body {}"), 0644))
+	assert.Nil(t, os.Symlink(filepath.Join(base, "secret.txt"), filepath.Join(dir, "secret.txt")))
+	assert.Nil(t, os.Symlink(base, filepath.Join(dir, "parent")))
+	assert.Nil(t, os.Symlink(filepath.Join(dir, "assets", "app.css"), filepath.Join(dir, "app.css")))
+	assert.Nil(t, os.Symlink("assets", filepath.Join(dir, "css")))
+	assert.Nil(t, os.Symlink("..", filepath.Join(dir, "assets", "up")))
+
+	m, err := NewMount("/app", dir)
+	assert.Nil(t, err)
+
+	for _, name := range []string{"/app/secret.txt", "/app/parent", "/app/parent/secret.txt"} {
+		_, err = m.Open(name)
+		assert.True(t, os.IsPermission(err))
+		_, err = m.Stat(name)
+		assert.True(t, os.IsPermission(err))
+		_, err = m.ReadFile(name)
+		assert.True(t, os.IsPermission(err))
+	}
+	_, err = m.ReadDir("/app/parent")
+	assert.True(t, os.IsPermission(err))
+
+	// sibling directory sharing the source prefix is not the source
+	_, err = m.ReadFile(dir + "secret/key.txt")
+	assert.True(t, os.IsNotExist(err))
+
+	// link within the mount and the link itself
+	data, err := m.ReadFile("/app/app.css")
+	assert.Nil(t, err)
+	assert.Equal(t, "body {}", string(data))
+	_, err = m.Lstat("/app/assets/app.css")
+	assert.Nil(t, err)
+	data, err = m.ReadFile("/app/css/up/css/app.css")
+	assert.Nil(t, err)
+	assert.Equal(t, "body {}", string(data))
+	_, err = m.ReadFile("/app/css/up/parent/secret.txt")
+	assert.True(t, os.IsPermission(err))
+	_, err = m.ReadFile("/app/css/../secret.txt")
+	assert.True(t, os.IsPermission(err))
+
+	// opt-out
+	m, err = NewMount("/app", dir, AllowExternalLinks())
+	assert.Nil(t, err)
+	data, err = m.ReadFile("/app/parent/secret.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, "secret", string(data))
+}
+
+func TestVFSMountEmbeddedOnly(t *testing.T) {
+	m, err := NewMount("/app", filepath.Join(testdataBaseDir(), "vfstest"), EmbeddedOnly())
+	assert.Nil(t, err)
+	assert.True(t, m.IsEmbeddedOnly())
+	assert.Nil(t, m.AddFile(&NodeInfo{Path: "/app/embedded.txt", Time: time.Now()}, []byte("embedded")))
+
+	data, err := m.ReadFile("/app/embedded.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, "embedded", string(data))
+
+	_, err = m.Open("/app/static/robots.txt")
+	assert.True(t, os.IsNotExist(err))
+	_, err = m.Stat("/app/static")
+	assert.True(t, os.IsNotExist(err))
+	_, err = m.ReadDir("/app/static")
+	assert.True(t, os.IsNotExist(err))
+	matches, err := m.Glob("/app/static/*.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(matches))
+
+	m.SetEmbeddedOnly(false)
+	assert.False(t, m.IsEmbeddedOnly())
+	_, err = m.Stat("/app/static/robots.txt")
+	assert.Nil(t, err)
+
+	m.SetEmbeddedOnly(true)
+	assert.False(t, m.IsExists("/app/static/robots.txt"))
+}
+
+func TestVFSMountRemoveRename(t *testing.T) {
+	m, err := NewMount("/plugins", "")
+	assert.Nil(t, err)
+	now := time.Now()
+	assert.Nil(t, m.AddFileAll(&NodeInfo{Path: "/plugins/blog/views/index.html", Time: now}, []byte("index")))
+	assert.Nil(t, m.AddFile(&NodeInfo{Path: "/plugins/blog/views/list.html", Time: now}, []byte("list")))
+	assert.Nil(t, m.AddFile(&NodeInfo{Path: "/plugins/blog/blog.css", Time: now}, []byte("css")))
+	assert.Nil(t, m.Symlink("/plugins/blog/blog.css", "/plugins/blog/style.css"))
+
+	// kind mismatch and invalid paths
+	err = m.RemoveFile("/plugins/blog/views")
+	assert.Equal(t, "remove /plugins/blog/views: is a directory", err.Error())
+	err = m.RemoveDir("/plugins/blog/blog.css")
+	assert.Equal(t, "removedir /plugins/blog/blog.css: not a directory", err.Error())
+	err = m.RemoveDir("/plugins")
+	assert.True(t, err.(*os.PathError).Err == os.ErrInvalid)
+	err = m.RemoveFile("/plugins/not-exists.css")
+	assert.True(t, os.IsNotExist(err))
+	_, ok := err.(*os.PathError)
+	assert.True(t, ok)
+
+	// opened file keeps its data
+	f, err := m.Open("/plugins/blog/views/list.html")
+	assert.Nil(t, err)
+	assert.Nil(t, m.RemoveFile("/plugins/blog/views/list.html"))
+	assert.False(t, m.IsExists("/plugins/blog/views/list.html"))
+	data, err := ioutil.ReadAll(f)
+	assert.Nil(t, err)
+	assert.Equal(t, "list", string(data))
+	assert.Nil(t, f.Close())
+
+	// rename, opened file keeps its info
+	f, err = m.Open("/plugins/blog/views/index.html")
+	assert.Nil(t, err)
+	assert.Nil(t, m.Rename("/plugins/blog/views", "/plugins/blog/templates"))
+	assert.False(t, m.IsExists("/plugins/blog/views/index.html"))
+	assert.Equal(t, "/plugins/blog/views/index.html", f.(*file).NodeInfo.Path)
+	assert.Nil(t, f.Close())
+	data, err = m.ReadFile("/plugins/blog/templates/index.html")
+	assert.Nil(t, err)
+	assert.Equal(t, "index", string(data))
+	matches, err := m.Glob("/plugins/blog/templates/*.html")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/plugins/blog/templates/index.html"}, matches)
+
+	err = m.Rename("/plugins/blog/blog.css", "/plugins/blog/templates")
+	assert.True(t, os.IsExist(err))
+	err = m.Rename("/plugins/blog", "/plugins/blog/templates/blog")
+	_, ok = err.(*os.LinkError)
+	assert.True(t, ok)
+	err = m.Rename("/plugins/blog/blog.css", "/plugins/shop/shop.css")
+	assert.True(t, os.IsNotExist(err))
+
+	// unload the plugin
+	assert.Nil(t, m.RemoveDir("/plugins/blog"))
+	assert.False(t, m.IsExists("/plugins/blog"))
+	assert.Equal(t, int32(0), m.symlinks)
+
+	ro, err := NewMount("/app", "", ReadOnly())
+	assert.Nil(t, err)
+	assert.Nil(t, ro.AddFile(&NodeInfo{Path: "/app/index.html", DataSize: 1}, []byte("i")))
+	ro.Seal()
+	err = ro.RemoveFile("/app/index.html")
+	assert.True(t, err.(*os.PathError).Err == ErrReadOnly)
+}
+
+func TestVFSMountAddFileFromReader(t *testing.T) {
+	m, err := NewMount("/app", "")
+	assert.Nil(t, err)
+	mtime := time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	assert.Nil(t, m.AddFileString("/app/app.conf", &NodeInfo{Time: mtime}, "name = \"app\""))
+	fi, err := m.Stat("/app/app.conf")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(12), fi.Size())
+	assert.True(t, mtime.Equal(fi.ModTime()))
+	sum, found := fi.(Checksummer).Checksum()
+	assert.True(t, found)
+	h := sha256.Sum256([]byte("name = \"app\""))
+	assert.Equal(t, hex.EncodeToString(h[:]), sum)
+
+	content := strings.Repeat("generated config line\n", 100)
+	gm, err := NewMount("/app", "", CompressAdded(gzip.BestSpeed))
+	assert.Nil(t, err)
+	assert.Nil(t, gm.AddFileFromReader("/app/gen.conf", nil, strings.NewReader(content)))
+	f, err := gm.Open("/app/gen.conf")
+	assert.Nil(t, err)
+	assert.True(t, f.(*file).IsGzip())
+	assert.Equal(t, int64(len(content)), f.(*file).Size())
+	assert.Nil(t, f.Close())
+	data, err := gm.ReadFile("/app/gen.conf")
+	assert.Nil(t, err)
+	assert.Equal(t, content, string(data))
+
+	// size of fi is the hint, mode is honored
+	for _, size := range []int64{0, 3, int64(len(content)), int64(len(content)) + 10} {
+		fi := &NodeInfo{DataSize: size, Time: mtime, Perm: 0640}
+		assert.Nil(t, m.AddFileFromReader("/app/sized.conf", fi, strings.NewReader(content)))
+		data, err = m.ReadFile("/app/sized.conf")
+		assert.Nil(t, err)
+		assert.Equal(t, content, string(data))
+		fi2, err := m.Stat("/app/sized.conf")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(len(content)), fi2.Size())
+		assert.Equal(t, os.FileMode(0640), fi2.Mode())
+	}
+	fi, err = m.Stat("/app/app.conf")
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0444), fi.Mode())
+	err = m.AddFileFromReader("/app/dir", &NodeInfo{Dir: true}, strings.NewReader(""))
+	assert.NotNil(t, err)
+
+	// reader error
+	err = m.AddFileFromReader("/app/err.conf", nil, iotest.TimeoutReader(strings.NewReader("abc")))
+	assert.NotNil(t, err)
+	assert.False(t, m.IsExists("/app/err.conf"))
+	err = m.AddFileFromReader("/app/err.conf", &NodeInfo{DataSize: 10}, iotest.TimeoutReader(strings.NewReader("abc")))
+	assert.NotNil(t, err)
+	assert.False(t, m.IsExists("/app/err.conf"))
+
+	// invalid names and names outside of mount
+	err = m.AddFileFromReader("/app/../etc/passwd", nil, strings.NewReader("x"))
+	assert.Equal(t, os.ErrPermission, err.(*os.PathError).Err)
+	err = m.AddFileFromReader("/app/a\x00.conf", nil, strings.NewReader("x"))
+	assert.Equal(t, ErrInvalidPath, err.(*os.PathError).Err)
+	for _, name := range []string{"/app", "/other/a.conf"} {
+		err = m.AddFileFromReader(name, nil, strings.NewReader("x"))
+		assert.Equal(t, os.ErrInvalid, err.(*os.PathError).Err)
+	}
+	assert.False(t, m.IsExists("/other/a.conf"))
+}
+
+func TestVFSSub(t *testing.T) {
+	fs := createVFS(t)
+
+	views, err := Sub(fs, "/app/views")
+	assert.Nil(t, err)
+	assert.True(t, views.IsExists("/layouts/master.html"))
+	assert.False(t, views.IsExists("/app/views/layouts/master.html"))
+
+	data, err := views.ReadFile("/layouts/master.html")
+	assert.Nil(t, err)
+	edata, err := fs.ReadFile("/app/views/layouts/master.html")
+	assert.Nil(t, err)
+	assert.Equal(t, edata, data)
+
+	list, err := views.ReadDir("/")
+	assert.Nil(t, err)
+	elist, err := fs.ReadDir("/app/views")
+	assert.Nil(t, err)
+	assert.Equal(t, len(elist), len(list))
+
+	matches, err := views.Glob("/pages/app/*.html")
+	assert.Nil(t, err)
+	assert.True(t, len(matches) > 0)
+	for _, m := range matches {
+		assert.True(t, strings.HasPrefix(m, "/pages/app/"))
+	}
+
+	// sibling paths are not reachable, errors carry the view name
+	_, err = views.Open("/../config/aah.conf")
+	assert.NotNil(t, err)
+	_, err = views.Stat("/not-exists.html")
+	assert.True(t, os.IsNotExist(err))
+	assert.Equal(t, "/not-exists.html", err.(*os.PathError).Path)
+
+	// nested view
+	pages, err := Sub(views, "pages")
+	assert.Nil(t, err)
+	assert.Equal(t, "sub(/app/views/pages vfs)", strings.Replace(fmt.Sprint(pages), fmt.Sprint(fs), "vfs", 1))
+	assert.True(t, pages.IsExists("/app/index.html"))
+
+	root, err := Sub(fs, "/")
+	assert.Nil(t, err)
+	assert.True(t, root == FileSystem(fs))
+
+	_, err = Sub(fs, "/app/views/layouts/master.html")
+	assert.NotNil(t, err)
+	_, err = Sub(fs, "/app/not-exists")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestVFSBind(t *testing.T) {
+	fs := createVFS(t)
+
+	assert.Nil(t, fs.Bind("/assets", "/app/static"))
+
+	data, err := fs.ReadFile("/assets/css/aah.css")
+	assert.Nil(t, err)
+	edata, err := fs.ReadFile("/app/static/css/aah.css")
+	assert.Nil(t, err)
+	assert.Equal(t, edata, data)
+
+	fi, err := fs.Stat("/assets")
+	assert.Nil(t, err)
+	assert.Equal(t, "assets", fi.Name())
+	assert.True(t, fi.IsDir())
+
+	list, err := fs.ReadDir("/assets/css")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(list))
+
+	matches, err := fs.Glob("/assets/img/*")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/assets/img/aah-framework-logo.png", "/assets/img/favicon.ico"}, matches)
+
+	files, err := fs.Files("/assets")
+	assert.Nil(t, err)
+	assert.True(t, ess.IsSliceContainsString(files, "/assets/robots.txt"))
+	assert.True(t, ess.IsSliceContainsString(files, "/assets/js/aah.js"))
+
+	// virtual node
+	m, err := fs.FindMount("/app")
+	assert.Nil(t, err)
+	assert.Nil(t, m.AddFile(&NodeInfo{Path: "/app/static/version.txt", Time: time.Now()}, []byte("1.0")))
+	fi, err = fs.Lstat("/assets/version.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, "/assets/version.txt", fi.(*NodeInfo).Path)
+
+	// invalid binds
+	err = fs.Bind("/assets", "/app/views")
+	assert.True(t, os.IsExist(err))
+	err = fs.Bind("/app/static/more", "/app/static")
+	assert.NotNil(t, err)
+	err = fs.Bind("/public", "/assets/css")
+	assert.NotNil(t, err)
+	err = fs.Bind("/public", "/app/not-exists")
+	assert.True(t, os.IsNotExist(err))
+
+	assert.Nil(t, fs.Unbind("/assets"))
+	assert.False(t, fs.IsExists("/assets/css/aah.css"))
+	assert.True(t, os.IsNotExist(fs.Unbind("/assets")))
+}
+
+func BenchmarkMountOpen(b *testing.B) {
+	m, name := createDeepMount(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f, err := m.Open(name)
+		if err != nil {
+			b.Fatal(err)
+		}
+		_ = f.Close()
+	}
+}
+
+func BenchmarkMountStat(b *testing.B) {
+	m, name := createDeepMount(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := m.Stat(name); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// createDeepMount method creates the mount of 8 levels deep with 5000
+// siblings on each level, it returns the path of last file on deepest level.
+func createDeepMount(b *testing.B) (*Mount, string) {
+	m, err := NewMount("/app", "")
+	if err != nil {
+		b.Fatal(err)
+	}
+	dir := "/app"
+	var name string
+	for level := 0; level < 8; level++ {
+		for i := 0; i < 5000; i++ {
+			name = fmt.Sprintf("%s/f%04d.txt", dir, i)
+			if err = m.AddFile(&NodeInfo{Path: name, DataSize: 4}, []byte("file")); err != nil {
+				b.Fatal(err)
+			}
+		}
+		dir = fmt.Sprintf("%s/d%d", dir, level)
+		if err = m.AddDir(&NodeInfo{Dir: true, Path: dir}); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return m, name
+}
+
+func TestVFSMountOpenExactPath(t *testing.T) {
+	m, err := NewMount("/app", "")
+	assert.FailNowOnError(t, err, "")
+	assert.Nil(t, m.AddDir(&NodeInfo{Path: "/app/static", Dir: true}))
+	assert.Nil(t, m.AddFile(&NodeInfo{Path: "/app/static/app.css", DataSize: 7}, []byte("body {}")))
+
+	for _, name := range []string{"/app/static", "/app/static/app.css"} {
+		f, err := m.Open(name)
+		assert.Nil(t, err)
+		assert.Equal(t, name, f.(*file).NodeInfo.Path)
+		assert.Nil(t, f.Close())
+	}
+
+	// nearest parent of the same base name is not the match
+	for _, name := range []string{"/app/x/app", "/app/static/x/static", "/app/static/app.css/app.css", "/app/STATIC"} {
+		_, err = m.Open(name)
+		assert.True(t, os.IsNotExist(err))
+		_, err = m.Stat(name)
+		assert.True(t, os.IsNotExist(err))
+	}
+}
+
 func createVFS(t *testing.T) *VFS {
 	mountDir := filepath.Join(testdataBaseDir(), "vfstest")
 
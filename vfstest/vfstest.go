@@ -0,0 +1,278 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package vfstest provides `MockFS`, the `vfs.FileSystem` for unit tests
+// whose paths are configured to return specific content, errors or
+// latencies, so that application error handling around template and config
+// loading is tested without touching disk.
+//
+//	fs := vfstest.New()
+//	_ = fs.AddFile("/app/config/aah.conf", []byte(`name = "demo"`))
+//	fs.FailOn("/app/views/*.html", vfstest.OpOpen, os.ErrPermission)
+//	fs.Delay("/app/config/*", vfstest.OpAny, 2*time.Second)
+package vfstest
+
+import (
+	"errors"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"aahframework.org/vfs.v0"
+)
+
+var _ vfs.FileSystem = (*MockFS)(nil)
+var _ vfs.File = (*file)(nil)
+
+// Operations of MockFS rules, error is returned as `*os.PathError` with
+// the operation.
+const (
+	OpAny      = "*"
+	OpOpen     = "open" // Open and OpenFile
+	OpStat     = "stat" // Stat and IsExists
+	OpLstat    = "lstat"
+	OpReadlink = "readlink"
+	OpReadFile = "readfile"
+	OpReadDir  = "readdir"
+	OpGlob     = "glob" // matched against the glob pattern
+	OpRead     = "read" // Read of opened file
+)
+
+// MockFS is the `vfs.FileSystem` which applies the configured errors and
+// latencies on top of its backing FileSystem and counts the calls. Rules are
+// matched against the clean path by `path.Match` pattern, rule added later
+// takes precedence. It is safe for concurrent use.
+type MockFS struct {
+	fs    vfs.FileSystem
+	mu    sync.Mutex
+	rules []rule
+	calls map[string]int // by op and path
+}
+
+// New method creates the MockFS backed by empty `vfs.MemFS`, content is
+// added via `MockFS.AddFile`.
+func New() *MockFS {
+	return Wrap(vfs.NewMemFS())
+}
+
+// Wrap method creates the MockFS backed by given FileSystem, for e.g.
+// application VFS to inject errors on top of the real assets.
+func Wrap(fs vfs.FileSystem) *MockFS {
+	return &MockFS{fs: fs, calls: make(map[string]int)}
+}
+
+// AddFile method adds the file with data, missing parent directories are
+// created. Backing FileSystem must be `vfs.WritableFileSystem`.
+func (m *MockFS) AddFile(name string, data []byte) error {
+	wfs, ok := m.fs.(vfs.WritableFileSystem)
+	if !ok {
+		return &os.PathError{Op: "addfile", Path: name, Err: vfs.ErrReadOnly}
+	}
+	name = path.Clean("/" + name)
+	if err := mkdirAll(wfs, path.Dir(name)); err != nil {
+		return err
+	}
+	return wfs.WriteFile(name, data, 0644)
+}
+
+// FailOn method makes the operation op on paths matching pattern to return
+// err, for e.g. `os.ErrPermission`. Use `OpAny` for all the operations.
+func (m *MockFS) FailOn(pattern, op string, err error) {
+	m.addRule(rule{pattern: pattern, op: op, err: err})
+}
+
+// Delay method makes the operation op on paths matching pattern to sleep
+// for d before it is performed, for e.g. slow network filesystem. Delays of
+// all the matching rules are added up.
+func (m *MockFS) Delay(pattern, op string, d time.Duration) {
+	m.addRule(rule{pattern: pattern, op: op, delay: d})
+}
+
+// Reset method removes the rules and call counts.
+func (m *MockFS) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rules = nil
+	m.calls = make(map[string]int)
+}
+
+// Calls method returns no. of times operation op is called for name, for
+// e.g. to assert the caching of config loading.
+func (m *MockFS) Calls(op, name string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls[op+" "+path.Clean(name)]
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// MockFS FileSystem interface methods
+//______________________________________________________________________________
+
+// Open method opens the file of backing FileSystem.
+func (m *MockFS) Open(name string) (vfs.File, error) {
+	if err := m.apply(OpOpen, name); err != nil {
+		return nil, err
+	}
+	f, err := m.fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &file{File: f, m: m, name: path.Clean(name)}, nil
+}
+
+// OpenFile method opens the file of backing FileSystem with flag.
+func (m *MockFS) OpenFile(name string, flag int, perm os.FileMode) (vfs.File, error) {
+	if err := m.apply(OpOpen, name); err != nil {
+		return nil, err
+	}
+	f, err := m.fs.OpenFile(name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	return &file{File: f, m: m, name: path.Clean(name)}, nil
+}
+
+// Lstat method returns the file info of backing FileSystem.
+func (m *MockFS) Lstat(name string) (os.FileInfo, error) {
+	if err := m.apply(OpLstat, name); err != nil {
+		return nil, err
+	}
+	return m.fs.Lstat(name)
+}
+
+// Stat method returns the file info of backing FileSystem.
+func (m *MockFS) Stat(name string) (os.FileInfo, error) {
+	if err := m.apply(OpStat, name); err != nil {
+		return nil, err
+	}
+	return m.fs.Stat(name)
+}
+
+// Readlink method returns the symbolic link target of backing FileSystem.
+func (m *MockFS) Readlink(name string) (string, error) {
+	if err := m.apply(OpReadlink, name); err != nil {
+		return "", err
+	}
+	return m.fs.Readlink(name)
+}
+
+// ReadFile method returns the file content of backing FileSystem.
+func (m *MockFS) ReadFile(filename string) ([]byte, error) {
+	if err := m.apply(OpReadFile, filename); err != nil {
+		return nil, err
+	}
+	return m.fs.ReadFile(filename)
+}
+
+// ReadDir method returns the directory entries of backing FileSystem.
+func (m *MockFS) ReadDir(dirname string) ([]os.FileInfo, error) {
+	if err := m.apply(OpReadDir, dirname); err != nil {
+		return nil, err
+	}
+	return m.fs.ReadDir(dirname)
+}
+
+// Glob method returns the matches of backing FileSystem, rules are matched
+// against the pattern.
+func (m *MockFS) Glob(pattern string) ([]string, error) {
+	if err := m.apply(OpGlob, pattern); err != nil {
+		return nil, err
+	}
+	return m.fs.Glob(pattern)
+}
+
+// IsExists method returns false if stat rule returns an error, otherwise
+// existence on backing FileSystem.
+func (m *MockFS) IsExists(name string) bool {
+	if err := m.apply(OpStat, name); err != nil {
+		return false
+	}
+	return m.fs.IsExists(name)
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// MockFS unexported types and methods
+//______________________________________________________________________________
+
+type rule struct {
+	pattern string
+	op      string
+	err     error
+	delay   time.Duration
+}
+
+func (r rule) match(op, name string) bool {
+	if r.op != OpAny && r.op != op {
+		return false
+	}
+	if r.pattern == name {
+		return true
+	}
+	matched, _ := path.Match(r.pattern, name)
+	return matched
+}
+
+func (m *MockFS) addRule(r rule) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rules = append(m.rules, r)
+}
+
+// apply method counts the call, sleeps for the delay and returns the error
+// of matching rules.
+func (m *MockFS) apply(op, name string) error {
+	name = path.Clean(name)
+	var delay time.Duration
+	var err error
+	m.mu.Lock()
+	m.calls[op+" "+name]++
+	for i := len(m.rules) - 1; i >= 0; i-- {
+		if r := m.rules[i]; r.match(op, name) {
+			delay += r.delay
+			if err == nil {
+				err = r.err
+			}
+		}
+	}
+	m.mu.Unlock()
+
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+	if err != nil {
+		return &os.PathError{Op: op, Path: name, Err: err}
+	}
+	return nil
+}
+
+// file applies the read rules of MockFS.
+type file struct {
+	vfs.File
+	m    *MockFS
+	name string
+}
+
+func (f *file) Read(b []byte) (int, error) {
+	if err := f.m.apply(OpRead, f.name); err != nil {
+		return 0, err
+	}
+	return f.File.Read(b)
+}
+
+func mkdirAll(fs vfs.WritableFileSystem, dir string) error {
+	if dir == "/" {
+		return nil
+	}
+	if fi, err := fs.Stat(dir); err == nil {
+		if !fi.IsDir() {
+			return &os.PathError{Op: "mkdir", Path: dir, Err: errors.New("not a directory")}
+		}
+		return nil
+	}
+	if err := mkdirAll(fs, path.Dir(dir)); err != nil {
+		return err
+	}
+	return fs.Mkdir(dir, 0755)
+}
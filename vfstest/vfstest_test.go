@@ -0,0 +1,109 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfstest
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"aahframework.org/test.v0/assert"
+	"aahframework.org/vfs.v0"
+)
+
+func TestMockFS(t *testing.T) {
+	fs := New()
+	assert.Nil(t, fs.AddFile("/app/config/aah.conf", []byte(`name = "demo"`)))
+	assert.Nil(t, fs.AddFile("/app/views/index.html", []byte("<html></html>")))
+	assert.Nil(t, fs.AddFile("app/views/about.html", []byte("about")))
+
+	data, err := fs.ReadFile("/app/config/aah.conf")
+	assert.Nil(t, err)
+	assert.Equal(t, `name = "demo"`, string(data))
+	assert.True(t, fs.IsExists("/app/views"))
+
+	infos, err := fs.ReadDir("/app/views")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(infos))
+
+	fs.FailOn("/app/views/*.html", OpOpen, os.ErrPermission)
+	_, err = fs.Open("/app/views/index.html")
+	assert.NotNil(t, err)
+	assert.True(t, os.IsPermission(err))
+	assert.Equal(t, "open /app/views/index.html: permission denied", err.Error())
+
+	// other operations are not affected
+	data, err = fs.ReadFile("/app/views/index.html")
+	assert.Nil(t, err)
+	assert.Equal(t, "<html></html>", string(data))
+
+	// rule added later takes precedence
+	errCorrupt := errors.New("corrupt config")
+	fs.FailOn("/app/config/*", OpAny, os.ErrNotExist)
+	fs.FailOn("/app/config/aah.conf", OpReadFile, errCorrupt)
+	_, err = fs.ReadFile("/app/config/aah.conf")
+	assert.Equal(t, errCorrupt, err.(*os.PathError).Err)
+	_, err = fs.Stat("/app/config/aah.conf")
+	assert.True(t, os.IsNotExist(err))
+	assert.False(t, fs.IsExists("/app/config/aah.conf"))
+
+	assert.Equal(t, 2, fs.Calls(OpReadFile, "/app/config/aah.conf"))
+	assert.Equal(t, 2, fs.Calls(OpStat, "/app/config/aah.conf"))
+	assert.Equal(t, 0, fs.Calls(OpReadFile, "/app/config/missing.conf"))
+
+	fs.Reset()
+	assert.Equal(t, 0, fs.Calls(OpReadFile, "/app/config/aah.conf"))
+	_, err = fs.ReadFile("/app/config/aah.conf")
+	assert.Nil(t, err)
+}
+
+func TestMockFSRead(t *testing.T) {
+	fs := New()
+	assert.Nil(t, fs.AddFile("/app/robots.txt", []byte("User-agent: *")))
+
+	errIO := errors.New("input/output error")
+	fs.FailOn("/app/robots.txt", OpRead, errIO)
+	f, err := fs.Open("/app/robots.txt")
+	assert.Nil(t, err)
+	_, err = ioutil.ReadAll(f)
+	assert.Equal(t, errIO, err.(*os.PathError).Err)
+	assert.Nil(t, f.Close())
+	assert.Equal(t, 1, fs.Calls(OpOpen, "/app/robots.txt"))
+}
+
+func TestMockFSDelay(t *testing.T) {
+	fs := New()
+	assert.Nil(t, fs.AddFile("/app/config/aah.conf", []byte("name")))
+	fs.Delay("/app/config/*", OpAny, 20*time.Millisecond)
+
+	start := time.Now()
+	_, err := fs.ReadFile("/app/config/aah.conf")
+	assert.Nil(t, err)
+	assert.True(t, time.Since(start) >= 20*time.Millisecond)
+
+	start = time.Now()
+	_, err = fs.Stat("/app/config")
+	assert.Nil(t, err)
+	assert.True(t, time.Since(start) < 20*time.Millisecond)
+}
+
+func TestMockFSWrap(t *testing.T) {
+	m, err := vfs.NewMount("/app", "")
+	assert.Nil(t, err)
+	assert.Nil(t, m.AddFile(&vfs.NodeInfo{Path: "/app/index.html", DataSize: 5, Time: time.Now()}, []byte("hello")))
+
+	fs := Wrap(m)
+	err = fs.AddFile("/app/about.html", []byte("about"))
+	assert.True(t, err.(*os.PathError).Err == vfs.ErrReadOnly)
+
+	fs.FailOn("/app/*.css", OpGlob, os.ErrPermission)
+	_, err = fs.Glob("/app/*.css")
+	assert.True(t, os.IsPermission(err))
+	matches, err := fs.Glob("/app/*.html")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/app/index.html"}, matches)
+}
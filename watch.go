@@ -0,0 +1,218 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Mount event types
+const (
+	MountAdded   = "added"
+	MountRemoved = "removed"
+	MountSwapped = "swapped"
+	MountFailed  = "failed"
+)
+
+// MountEvent represents the change made on VFS mounts at runtime.
+type MountEvent struct {
+	Type      string
+	MountPath string
+	Source    string
+	Err       error
+}
+
+// String method Stringer interface.
+func (e MountEvent) String() string {
+	if e.Err != nil {
+		return fmt.Sprintf("mountevent(%s %s => %s err=%v)", e.Type, e.MountPath, e.Source, e.Err)
+	}
+	return fmt.Sprintf("mountevent(%s %s => %s)", e.Type, e.MountPath, e.Source)
+}
+
+// SyncMounts method brings the physical directory mounts managed by it in
+// sync with given configuration of mount path => physical path. Mounts not
+// present in the configuration gets removed, new ones gets added and the
+// ones with changed physical path gets swapped. Mounts added via other
+// methods are not touched.
+func (v *VFS) SyncMounts(config map[string]string) ([]MountEvent, error) {
+	desired := make(map[string]string, len(config))
+	for mp, pp := range config {
+		desired[path.Clean("/"+filepath.ToSlash(mp))] = pp
+	}
+
+	var events []MountEvent
+	var firstErr error
+	record := func(e MountEvent) {
+		if e.Err != nil && firstErr == nil {
+			firstErr = e.Err
+		}
+		events = append(events, e)
+	}
+
+	current := v.managedMounts()
+	for _, mp := range sortedKeys(current) {
+		if _, found := desired[mp]; !found {
+			v.setManaged(mp, "")
+			e := MountEvent{Type: MountRemoved, MountPath: mp, Source: current[mp]}
+			if m := v.detach(mp); m != nil {
+				e.Err = m.Close()
+			}
+			record(e)
+		}
+	}
+
+	for _, mp := range sortedKeys(desired) {
+		pp := desired[mp]
+		old, found := current[mp]
+		if found && old == pp {
+			continue
+		}
+
+		m, err := v.newPhysicalMount(mp, pp)
+		if err != nil {
+			record(MountEvent{Type: MountFailed, MountPath: mp, Source: pp, Err: err})
+			continue
+		}
+
+		e := MountEvent{Type: MountAdded, MountPath: mp, Source: pp}
+		if found {
+			e.Type = MountSwapped
+			if om := v.replace(m); om != nil {
+				e.Err = om.Close()
+			}
+		} else if err = v.attach(m); err != nil {
+			record(MountEvent{Type: MountFailed, MountPath: mp, Source: pp, Err: err})
+			continue
+		}
+		v.setManaged(mp, pp)
+		record(e)
+	}
+
+	return events, firstErr
+}
+
+// WatchMounts method periodically calls load to read the mount configuration
+// (for e.g. from config file) and applies it via `VFS.SyncMounts`. Each change
+// is reported to onEvent. First sync happens before it returns.
+//
+// Call returned stop func to stop watching.
+func (v *VFS) WatchMounts(interval time.Duration, load func() (map[string]string, error), onEvent func(MountEvent)) (stop func()) {
+	return watch(interval, func() {
+		config, err := load()
+		if err != nil {
+			notify(onEvent, []MountEvent{{Type: MountFailed, Err: err}})
+			return
+		}
+		events, _ := v.SyncMounts(config)
+		notify(onEvent, events)
+	})
+}
+
+// WatchArchives method periodically rescans the archive directory dir via
+// `VFS.MountArchives` and reports each change to onEvent. First scan happens
+// before it returns.
+//
+// Call returned stop func to stop watching.
+func (v *VFS) WatchArchives(vroot, dir string, interval time.Duration, onEvent func(MountEvent)) (stop func()) {
+	dir = v.resolvePath(dir)
+	return watch(interval, func() {
+		events, err := v.syncArchives(vroot, dir)
+		if events == nil && err != nil {
+			events = []MountEvent{{Type: MountFailed, Source: dir, Err: err}}
+		}
+		notify(onEvent, events)
+	})
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Watch unexported methods
+//______________________________________________________________________________
+
+func (v *VFS) newPhysicalMount(mountPath, physicalPath string) (*Mount, error) {
+	pp := v.resolvePath(physicalPath)
+	if !v.embeddedMode {
+		if err := checkPhysicalDir(pp); err != nil {
+			return nil, err
+		}
+	}
+	return newMount(mountPath, pp, v.mountOpts...)
+}
+
+func (v *VFS) managedMounts() map[string]string {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	result := make(map[string]string, len(v.managed))
+	for mp, pp := range v.managed {
+		result[mp] = pp
+	}
+	return result
+}
+
+func (v *VFS) setManaged(mountPath, physicalPath string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if physicalPath == "" {
+		delete(v.managed, mountPath)
+		return
+	}
+	if v.managed == nil {
+		v.managed = make(map[string]string)
+	}
+	v.managed[mountPath] = physicalPath
+}
+
+// watch method runs fn immediately and then on every interval until the
+// returned stop func is called. Stop waits for the running fn to complete.
+func watch(interval time.Duration, fn func()) func() {
+	fn()
+
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				fn()
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-exited
+		})
+	}
+}
+
+func notify(onEvent func(MountEvent), events []MountEvent) {
+	if onEvent == nil {
+		return
+	}
+	for _, e := range events {
+		onEvent(e)
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
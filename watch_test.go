@@ -0,0 +1,179 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"aahframework.org/test.v0/assert"
+)
+
+func TestVFSSyncMounts(t *testing.T) {
+	base := filepath.Join(testdataBaseDir(), "vfstest")
+	fs := new(VFS)
+	assert.Nil(t, fs.AddMount("/static", filepath.Join(base, "static")))
+
+	events, err := fs.SyncMounts(map[string]string{
+		"/config": filepath.Join(base, "config"),
+		"views":   filepath.Join(base, "views"),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []MountEvent{
+		{Type: MountAdded, MountPath: "/config", Source: filepath.Join(base, "config")},
+		{Type: MountAdded, MountPath: "/views", Source: filepath.Join(base, "views")},
+	}, events)
+	assert.True(t, fs.IsExists("/config/aah.conf"))
+
+	events, err = fs.SyncMounts(map[string]string{
+		"/config": filepath.Join(base, "i18n"),
+		"/i18n":   filepath.Join(base, "not-exists"),
+	})
+	assert.NotNil(t, err)
+	assert.Equal(t, 3, len(events))
+	assert.Equal(t, MountEvent{Type: MountRemoved, MountPath: "/views", Source: filepath.Join(base, "views")}, events[0])
+	assert.Equal(t, MountEvent{Type: MountSwapped, MountPath: "/config", Source: filepath.Join(base, "i18n")}, events[1])
+	assert.Equal(t, MountFailed, events[2].Type)
+	assert.True(t, fs.IsExists("/config/messages.en"))
+	assert.False(t, fs.IsExists("/views/common"))
+
+	// mounts not managed by SyncMounts are untouched
+	events, err = fs.SyncMounts(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(events))
+	assert.True(t, fs.IsExists("/static/robots.txt"))
+}
+
+func TestVFSWatchMountsAndArchives(t *testing.T) {
+	var closed []string
+	RegisterArchive(".watchbundle", func(vroot, filename string) (*Mount, error) {
+		return openTestBundle(vroot, filename, &closed)
+	})
+
+	dir, err := ioutil.TempDir("", "vfs-watch")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "blog.watchbundle"), []byte("index.html=blog"), 0644))
+
+	var mu sync.Mutex
+	var events []MountEvent
+	received := make(chan struct{}, 10)
+	onEvent := func(e MountEvent) {
+		mu.Lock()
+		events = append(events, e)
+		mu.Unlock()
+		received <- struct{}{}
+	}
+
+	fs := new(VFS)
+	stop := fs.WatchArchives("/plugins", dir, 5*time.Millisecond, onEvent)
+	<-received
+	assert.True(t, fs.IsExists("/plugins/blog/index.html"))
+
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "shop.watchbundle"), []byte("index.html=shop"), 0644))
+	select {
+	case <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("watcher did not report the new archive")
+	}
+	stop()
+	stop()
+
+	mu.Lock()
+	assert.Equal(t, []MountEvent{
+		{Type: MountAdded, MountPath: "/plugins/blog", Source: filepath.Join(dir, "blog.watchbundle")},
+		{Type: MountAdded, MountPath: "/plugins/shop", Source: filepath.Join(dir, "shop.watchbundle")},
+	}, events)
+	mu.Unlock()
+
+	// configuration loader
+	loadErr := errors.New("config parse error")
+	stop = fs.WatchMounts(time.Hour, func() (map[string]string, error) {
+		return nil, loadErr
+	}, onEvent)
+	stop()
+	<-received
+	mu.Lock()
+	assert.Equal(t, MountEvent{Type: MountFailed, Err: loadErr}, events[2])
+	mu.Unlock()
+}
+
+func TestVFSMountScanPhysicalUnderVroot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vfs-reload")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "index.html"), []byte("physical"), 0644))
+
+	// physical root lies under virtual root path
+	vroot := filepath.ToSlash(filepath.Dir(dir))
+	m, err := NewMount(vroot, dir)
+	assert.Nil(t, err)
+	states := m.scanPhysical()
+	_, found := states[vroot+"/index.html"]
+	assert.True(t, found)
+	_, found = states[vroot]
+	assert.True(t, found)
+}
+
+func TestVFSMountPreferPhysicalWatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vfs-reload")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "index.html"), []byte("physical"), 0644))
+
+	m, err := NewMount("/views", dir, PreferPhysical(), Lazy(0))
+	assert.Nil(t, err)
+	assert.Nil(t, m.AddFile(&NodeInfo{Path: "/views/index.html", Time: time.Now()}, []byte("embedded")))
+	assert.Nil(t, m.AddFile(&NodeInfo{Path: "/views/layout.html", Time: time.Now()}, []byte("layout")))
+
+	data, err := m.ReadFile("/views/index.html")
+	assert.Nil(t, err)
+	assert.Equal(t, "physical", string(data))
+
+	// virtual tree is the fallback
+	data, err = m.ReadFile("/views/layout.html")
+	assert.Nil(t, err)
+	assert.Equal(t, "layout", string(data))
+
+	list, err := m.ReadDir("/views")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(list))
+
+	// directory is reported too since its entries changed
+	received := make(chan string, 10)
+	stop := m.Watch(5*time.Millisecond, func(name string) { received <- name })
+	defer stop()
+	waitFor := func(want string) {
+		for {
+			select {
+			case name := <-received:
+				if name == want {
+					return
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("watcher did not report %s", want)
+			}
+		}
+	}
+
+	// lazy tree serves the cached entries until watch scan drops them
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "new.html"), []byte("new"), 0644))
+	waitFor("/views/new.html")
+	data, err = m.ReadFile("/views/new.html")
+	assert.Nil(t, err)
+	assert.Equal(t, "new", string(data))
+
+	assert.Nil(t, os.Remove(filepath.Join(dir, "index.html")))
+	waitFor("/views/index.html")
+	data, err = m.ReadFile("/views/index.html")
+	assert.Nil(t, err)
+	assert.Equal(t, "embedded", string(data))
+}
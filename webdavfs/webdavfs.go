@@ -0,0 +1,149 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package webdavfs adapts any `vfs.FileSystem` to read-only
+// `webdav.FileSystem` of golang.org/x/net/webdav, so operators can inspect
+// what is actually embedded in the running binary with WebDAV client (file
+// manager, `cadaver`, etc.) via debug endpoint.
+//
+//	http.Handle("/debug/vfs/", webdavfs.Handler("/debug/vfs", aah.AppVFS()))
+//
+// Names are the paths of FileSystem, use `vfs.Sub` to serve the mount
+// directory as root. Authentication is not provided, protect the endpoint.
+package webdavfs
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"aahframework.org/vfs.v0"
+	"golang.org/x/net/webdav"
+)
+
+var _ webdav.FileSystem = (*FileSystem)(nil)
+var _ webdav.ContentTyper = (*fileInfo)(nil)
+var _ webdav.ETager = (*fileInfo)(nil)
+
+// FileSystem is the read-only `webdav.FileSystem` backed by
+// `vfs.FileSystem`.
+type FileSystem struct {
+	fs vfs.FileSystem
+}
+
+// New method returns the `webdav.FileSystem` of given FileSystem.
+func New(fs vfs.FileSystem) *FileSystem {
+	return &FileSystem{fs: fs}
+}
+
+// Handler method returns the WebDAV handler serving given FileSystem under
+// URL path prefix. Request methods which modify resources are rejected with
+// `405 Method Not Allowed`.
+func Handler(prefix string, fs vfs.FileSystem) http.Handler {
+	h := &webdav.Handler{
+		Prefix:     prefix,
+		FileSystem: New(fs),
+		LockSystem: webdav.NewMemLS(),
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodOptions, http.MethodGet, http.MethodHead, "PROPFIND":
+			h.ServeHTTP(w, r)
+		default:
+			w.Header().Set("Allow", "OPTIONS, GET, HEAD, PROPFIND")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// OpenFile method opens the file of FileSystem, flags other than
+// `os.O_RDONLY` returns `vfs.ErrReadOnly`.
+func (w *FileSystem) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_APPEND|os.O_CREATE|os.O_TRUNC) != 0 {
+		return nil, readOnly("open", name)
+	}
+	f, err := w.fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &file{File: f, fs: w.fs, name: name}, nil
+}
+
+// Stat method returns the file info of FileSystem. Content type is sniffed
+// via `vfs.ContentType` and ETag is the checksum of file, if available.
+func (w *FileSystem) Stat(ctx context.Context, name string) (os.FileInfo, error) {
+	fi, err := w.fs.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	return &fileInfo{FileInfo: fi, fs: w.fs, name: name}, nil
+}
+
+// Mkdir method returns `vfs.ErrReadOnly`.
+func (w *FileSystem) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
+	return readOnly("mkdir", name)
+}
+
+// RemoveAll method returns `vfs.ErrReadOnly`.
+func (w *FileSystem) RemoveAll(ctx context.Context, name string) error {
+	return readOnly("remove", name)
+}
+
+// Rename method returns `vfs.ErrReadOnly`.
+func (w *FileSystem) Rename(ctx context.Context, oldName, newName string) error {
+	return &os.LinkError{Op: "rename", Old: oldName, New: newName, Err: vfs.ErrReadOnly}
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Unexported types and methods
+//______________________________________________________________________________
+
+// file adapts the `vfs.File` to `webdav.File`.
+type file struct {
+	vfs.File
+	fs   vfs.FileSystem
+	name string
+}
+
+// Stat method returns the file info with WebDAV properties, PROPFIND reads
+// them from the opened file.
+func (f *file) Stat() (os.FileInfo, error) {
+	fi, err := f.File.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return &fileInfo{FileInfo: fi, fs: f.fs, name: f.name}, nil
+}
+
+func (f *file) Write(b []byte) (int, error) {
+	return 0, readOnly("write", f.name)
+}
+
+// fileInfo implements the optional WebDAV properties of file info.
+type fileInfo struct {
+	os.FileInfo
+	fs   vfs.FileSystem
+	name string
+}
+
+func (fi *fileInfo) ContentType(ctx context.Context) (string, error) {
+	if fi.IsDir() {
+		return "", webdav.ErrNotImplemented
+	}
+	return vfs.ContentType(fi.fs, fi.name)
+}
+
+func (fi *fileInfo) ETag(ctx context.Context) (string, error) {
+	if c, ok := fi.FileInfo.(vfs.Checksummer); ok {
+		if sum, found := c.Checksum(); found {
+			return fmt.Sprintf("%q", sum), nil
+		}
+	}
+	return "", webdav.ErrNotImplemented
+}
+
+func readOnly(op, name string) error {
+	return &os.PathError{Op: op, Path: name, Err: vfs.ErrReadOnly}
+}
@@ -0,0 +1,75 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package webdavfs
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"aahframework.org/test.v0/assert"
+	"aahframework.org/vfs.v0"
+)
+
+func TestWebDAVHandler(t *testing.T) {
+	m, err := vfs.NewMount("/app", "")
+	assert.Nil(t, err)
+	now := time.Now()
+	assert.Nil(t, m.AddDir(&vfs.NodeInfo{Dir: true, Path: "/app/css", Time: now}))
+	assert.Nil(t, m.AddFile(&vfs.NodeInfo{Path: "/app/css/app.css", DataSize: 15, Time: now,
+		SHA256: "0f7b2a1c"}, []byte("body { m: 0; }\n")))
+	sub, err := vfs.Sub(m, "/app")
+	assert.Nil(t, err)
+
+	ts := httptest.NewServer(Handler("/debug/vfs", sub))
+	defer ts.Close()
+
+	do := func(method, p, body string) (*http.Response, string) {
+		req, err := http.NewRequest(method, ts.URL+p, strings.NewReader(body))
+		assert.Nil(t, err)
+		req.Header.Set("Depth", "1")
+		resp, err := http.DefaultClient.Do(req)
+		assert.Nil(t, err)
+		data, err := ioutil.ReadAll(resp.Body)
+		assert.Nil(t, err)
+		_ = resp.Body.Close()
+		return resp, string(data)
+	}
+
+	resp, body := do(http.MethodGet, "/debug/vfs/css/app.css", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "body { m: 0; }\n", body)
+
+	resp, body = do("PROPFIND", "/debug/vfs/css/", "")
+	assert.Equal(t, http.StatusMultiStatus, resp.StatusCode)
+	assert.True(t, strings.Contains(body, "/debug/vfs/css/app.css"))
+	assert.True(t, strings.Contains(body, "text/css"))
+	assert.True(t, strings.Contains(body, `"0f7b2a1c"`))
+
+	for _, method := range []string{http.MethodPut, http.MethodDelete, "MKCOL", "MOVE", "PROPPATCH", "LOCK"} {
+		resp, _ = do(method, "/debug/vfs/css/app.css", "x")
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	}
+	assert.True(t, m.IsExists("/app/css/app.css"))
+}
+
+func TestWebDAVFileSystemReadOnly(t *testing.T) {
+	fs := New(vfs.NewMemFS())
+	ctx := context.Background()
+
+	_, err := fs.OpenFile(ctx, "/a.txt", os.O_CREATE|os.O_WRONLY, 0644)
+	assert.Equal(t, vfs.ErrReadOnly, err.(*os.PathError).Err)
+	assert.NotNil(t, fs.Mkdir(ctx, "/dir", 0755))
+	assert.NotNil(t, fs.RemoveAll(ctx, "/"))
+	assert.NotNil(t, fs.Rename(ctx, "/a", "/b"))
+
+	_, err = fs.Stat(ctx, "/not-exists")
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,106 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"archive/zip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+)
+
+// NewZipMount method creates the mount of zip archive content at given
+// virtual root. Tree is populated from zip central directory, file data is
+// decompressed on first read and kept in memory. So the mount of large
+// archive is cheap until the files are read. Symbolic link entries are
+// skipped. Entry data is mounted as-is, gzip file entry, for e.g. `.tar.gz`,
+// is not decompressed on read.
+//
+// Reader r must stay valid until the mount is closed, see `Mount.AddCloser`.
+func NewZipMount(vroot string, r io.ReaderAt, size int64, opts ...MountOption) (*Mount, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := newMount(vroot, "", opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, zf := range zr.File {
+		if err = m.addZipEntry(zf); err != nil {
+			return nil, err
+		}
+	}
+	m.sealReadOnly()
+	return m, nil
+}
+
+// OpenZip method opens the zip archive file and mounts it at given virtual
+// root, file is closed on `Mount.Close`. It can be registered for
+// `VFS.MountArchives`, for e.g.:
+//
+//	vfs.RegisterArchive(".zip", vfs.OpenZip)
+func OpenZip(vroot, filename string) (*Mount, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	m, err := NewZipMount(vroot, f, fi.Size())
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	m.AddCloser(f)
+	return m, nil
+}
+
+//‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾‾
+// Zip mount unexported methods
+//______________________________________________________________________________
+
+func (m *Mount) addZipEntry(zf *zip.File) error {
+	name, err := cleanPath("zip", zf.Name)
+	if err != nil {
+		return err
+	}
+	mode := zf.Mode()
+	if name == "/" || mode&os.ModeSymlink != 0 {
+		return nil
+	}
+
+	vpath := path.Join(m.Vroot, name)
+	modTime := zf.ModTime().UTC()
+	if err = m.mkdirAll(path.Dir(vpath), modTime); err != nil {
+		return err
+	}
+
+	if mode.IsDir() {
+		if n, found := m.tree.lookup(name); found && n.IsDir() {
+			n.Time = modTime // explicit entry after implicit parent
+			return nil
+		}
+		return m.AddDir(&NodeInfo{Dir: true, Path: vpath, Time: modTime})
+	}
+
+	fi := &NodeInfo{Path: vpath, DataSize: int64(zf.UncompressedSize64), Time: modTime, Raw: true}
+	return m.addSourceNode(fi, func() ([]byte, error) {
+		rc, err := zf.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = rc.Close() }()
+		return ioutil.ReadAll(rc)
+	})
+}
@@ -0,0 +1,107 @@
+// Copyright (c) Jeevanandam M. (https://github.com/jeevatkm)
+// aahframework.org/vfs source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package vfs
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"aahframework.org/test.v0/assert"
+)
+
+func TestVFSZipMount(t *testing.T) {
+	mtime := time.Date(2018, 3, 12, 10, 20, 30, 0, time.UTC)
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+	addEntry := func(name, content string) {
+		fh := &zip.FileHeader{Name: name, Method: zip.Deflate}
+		fh.SetModTime(mtime)
+		w, err := zw.CreateHeader(fh)
+		assert.Nil(t, err)
+		_, err = w.Write([]byte(content))
+		assert.Nil(t, err)
+	}
+	addEntry("static/", "")
+	addEntry("static/css/aah.css", "body { color: #333; }")
+	addEntry("views/index.html", "<h1>aah</h1>")
+	gz := gzipString(t, "aah framework")
+	addEntry("dl/app.tar.gz", gz)
+	assert.Nil(t, zw.Close())
+
+	m, err := NewZipMount("/app", bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(m.Validate()))
+
+	fi, err := m.Stat("/app/static/css/aah.css")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(21), fi.Size())
+	assert.True(t, fi.ModTime().Equal(mtime))
+
+	fi, err = m.Stat("/app/views")
+	assert.Nil(t, err)
+	assert.True(t, fi.IsDir())
+
+	data, err := m.ReadFile("/app/views/index.html")
+	assert.Nil(t, err)
+	assert.Equal(t, "<h1>aah</h1>", string(data))
+
+	// gzip entry as-is
+	fi, err = m.Stat("/app/dl/app.tar.gz")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(len(gz)), fi.Size())
+	data, err = m.ReadFile("/app/dl/app.tar.gz")
+	assert.Nil(t, err)
+	assert.Equal(t, gz, string(data))
+	f, err := m.Open("/app/dl/app.tar.gz")
+	assert.Nil(t, err)
+	assert.False(t, f.(Gziper).IsGzip())
+	assert.Nil(t, f.Close())
+
+	names, err := m.Glob("/app/static/css/*.css")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/app/static/css/aah.css"}, names)
+
+	// read only mount is sealed once populated
+	m, err = NewZipMount("/app", bytes.NewReader(buf.Bytes()), int64(buf.Len()), ReadOnly())
+	assert.Nil(t, err)
+	assert.True(t, m.IsExists("/app/static/css/aah.css"))
+	err = m.AddFile(&NodeInfo{Path: "/app/static/app.css", DataSize: 1}, []byte("a"))
+	assert.Equal(t, ErrReadOnly, err.(*os.PathError).Err)
+	err = m.Rename("/app/views/index.html", "/app/views/home.html")
+	assert.Equal(t, ErrReadOnly, err.(*os.LinkError).Err)
+
+	_, err = NewZipMount("/app", bytes.NewReader([]byte("not a zip")), 9)
+	assert.NotNil(t, err)
+
+	// archive file
+	dir, err := ioutil.TempDir("", "vfs-zip")
+	assert.Nil(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	fname := filepath.Join(dir, "bundle.zip")
+	assert.Nil(t, ioutil.WriteFile(fname, buf.Bytes(), 0644))
+
+	m, err = OpenZip("/bundle", fname)
+	assert.Nil(t, err)
+	data, err = m.ReadFile("/bundle/static/css/aah.css")
+	assert.Nil(t, err)
+	assert.Equal(t, "body { color: #333; }", string(data))
+	assert.Nil(t, m.Close())
+}
+
+// gzipString method returns the gzip compressed s.
+func gzipString(t *testing.T, s string) string {
+	buf := new(bytes.Buffer)
+	gw := gzip.NewWriter(buf)
+	_, err := gw.Write([]byte(s))
+	assert.Nil(t, err)
+	assert.Nil(t, gw.Close())
+	return buf.String()
+}